@@ -18,6 +18,8 @@ blocksOpts = {
     "articulate": {"hotkey": "-a", "type": "switch"},
     "bigRange": {"hotkey": "-r", "type": "flag"},
     "count": {"hotkey": "-U", "type": "switch"},
+    "fees": {"hotkey": "-g", "type": "switch"},
+    "period": {"hotkey": "-d", "type": "flag"},
     "cacheTxs": {"hotkey": "", "type": "switch"},
     "cacheTraces": {"hotkey": "", "type": "switch"},
     "raw": {"hotkey": "-w", "type": "switch"},
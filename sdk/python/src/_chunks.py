@@ -10,6 +10,7 @@ chunksOpts = {
     "check": {"hotkey": "-c", "type": "switch"},
     "pin": {"hotkey": "-i", "type": "switch"},
     "publish": {"hotkey": "-p", "type": "switch"},
+    "upgrade": {"hotkey": "-u", "type": "switch"},
     "remote": {"hotkey": "-r", "type": "switch"},
     "belongs": {"hotkey": "-b", "type": "flag"},
     "firstBlock": {"hotkey": "-F", "type": "flag"},
@@ -12,6 +12,8 @@ exportOpts = {
     "logs": {"hotkey": "-l", "type": "switch"},
     "traces": {"hotkey": "-t", "type": "switch"},
     "neighbors": {"hotkey": "-n", "type": "switch"},
+    "graph": {"hotkey": "-G", "type": "flag"},
+    "hops": {"hotkey": "", "type": "flag"},
     "accounting": {"hotkey": "-C", "type": "switch"},
     "statements": {"hotkey": "-A", "type": "switch"},
     "balances": {"hotkey": "-b", "type": "switch"},
@@ -25,6 +27,10 @@ exportOpts = {
     "topic": {"hotkey": "-B", "type": "flag"},
     "asset": {"hotkey": "-P", "type": "flag"},
     "flow": {"hotkey": "-f", "type": "flag"},
+    "gains": {"hotkey": "-g", "type": "switch"},
+    "costBasis": {"hotkey": "-k", "type": "flag"},
+    "period": {"hotkey": "-d", "type": "flag"},
+    "entity": {"hotkey": "-i", "type": "flag"},
     "factory": {"hotkey": "-y", "type": "switch"},
     "unripe": {"hotkey": "-u", "type": "switch"},
     "reversed": {"hotkey": "-E", "type": "switch"},
@@ -13,6 +13,8 @@ stateOpts = {
     "call": {"hotkey": "-l", "type": "flag"},
     "articulate": {"hotkey": "-a", "type": "switch"},
     "proxyFor": {"hotkey": "-r", "type": "flag"},
+    "storage": {"hotkey": "-s", "type": "flag"},
+    "layout": {"hotkey": "-y", "type": "flag"},
     "ether": {"hotkey": "-H", "type": "switch"},
     "cache": {"hotkey": "-o", "type": "switch"},
     "fmt": {"hotkey": "-x", "type": "flag"},
@@ -2,5 +2,5 @@ export * from './lib/api_callers';
 export * from './paths';
 export * from './types';
 export function getVersion(): string {
-  return 'TrueBlocks SDK v1.0.0-release';
+  return 'TrueBlocks SDK v1.1.0-release';
 }
@@ -4,7 +4,7 @@
  * This file was generated with makeClass --sdk. Do not edit it.
  */
 import * as ApiCallers from '../lib/api_callers';
-import { address, Appearance, blknum, Block, BlockCount, Log, LogFilter, topic, Trace, TraceAction, TraceResult, uint64, Withdrawal } from '../types';
+import { address, Appearance, blknum, Block, BlockCount, BlockFee, Log, LogFilter, topic, Trace, TraceAction, TraceResult, uint64, Withdrawal } from '../types';
 
 export function getBlocks(
   parameters?: {
@@ -20,6 +20,8 @@ export function getBlocks(
     articulate?: boolean,
     bigRange?: uint64,
     count?: boolean,
+    fees?: boolean,
+    period?: 'hourly' | 'daily' | 'weekly' | 'monthly' | 'quarterly' | 'annually',
     chain: string,
     noHeader?: boolean,
     fmt?: string,
@@ -30,7 +32,7 @@ export function getBlocks(
   },
   options?: RequestInit,
 ) {
-  return ApiCallers.fetch<Appearance[] | Block[] | BlockCount[] | Log[] | LogFilter[] | Trace[] | TraceAction[] | TraceResult[] | Withdrawal[]>(
+  return ApiCallers.fetch<Appearance[] | Block[] | BlockCount[] | BlockFee[] | Log[] | LogFilter[] | Trace[] | TraceAction[] | TraceResult[] | Withdrawal[]>(
     { endpoint: '/blocks', method: 'get', parameters, options },
   );
 }
@@ -13,6 +13,7 @@ export function getChunks(
     check?: boolean,
     pin?: boolean,
     publish?: boolean,
+    upgrade?: boolean,
     remote?: boolean,
     belongs?: address[],
     firstBlock?: blknum,
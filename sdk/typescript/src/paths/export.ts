@@ -4,7 +4,7 @@
  * This file was generated with makeClass --sdk. Do not edit it.
  */
 import * as ApiCallers from '../lib/api_callers';
-import { address, Appearance, AppearanceCount, blknum, fourbyte, Function, Log, Monitor, Parameter, Receipt, Statement, Token, topic, Trace, TraceAction, TraceResult, Transaction, Transfer, uint64 } from '../types';
+import { address, Appearance, AppearanceCount, blknum, fourbyte, Function, Gain, Log, Monitor, Parameter, Receipt, Statement, Token, topic, Trace, TraceAction, TraceResult, Transaction, Transfer, uint64 } from '../types';
 
 export function getExport(
   parameters?: {
@@ -16,6 +16,8 @@ export function getExport(
     logs?: boolean,
     traces?: boolean,
     neighbors?: boolean,
+    graph?: 'graphml' | 'dot' | 'edges',
+    hops?: uint64,
     accounting?: boolean,
     statements?: boolean,
     balances?: boolean,
@@ -29,6 +31,10 @@ export function getExport(
     topic?: topic[],
     asset?: address[],
     flow?: 'in' | 'out' | 'zero',
+    gains?: boolean,
+    costBasis?: 'fifo' | 'lifo' | 'hifo',
+    period?: 'year' | 'quarter' | 'month',
+    entity?: string,
     factory?: boolean,
     unripe?: boolean,
     reversed?: boolean,
@@ -45,7 +51,7 @@ export function getExport(
   },
   options?: RequestInit,
 ) {
-  return ApiCallers.fetch<Appearance[] | AppearanceCount[] | Function[] | Log[] | Monitor[] | Parameter[] | Receipt[] | Statement[] | Token[] | Trace[] | TraceAction[] | TraceResult[] | Transaction[] | Transfer[]>(
+  return ApiCallers.fetch<Appearance[] | AppearanceCount[] | Function[] | Gain[] | Log[] | Monitor[] | Parameter[] | Receipt[] | Statement[] | Token[] | Trace[] | TraceAction[] | TraceResult[] | Transaction[] | Transfer[]>(
     { endpoint: '/export', method: 'get', parameters, options },
   );
 }
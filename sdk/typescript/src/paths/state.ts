@@ -4,7 +4,7 @@
  * This file was generated with makeClass --sdk. Do not edit it.
  */
 import * as ApiCallers from '../lib/api_callers';
-import { address, blknum, Result, State } from '../types';
+import { address, blknum, Result, State, StorageSlot } from '../types';
 
 export function getState(
   parameters?: {
@@ -16,6 +16,8 @@ export function getState(
     call?: string,
     articulate?: boolean,
     proxyFor?: address,
+    storage?: string[],
+    layout?: string,
     chain: string,
     noHeader?: boolean,
     fmt?: string,
@@ -26,7 +28,7 @@ export function getState(
   },
   options?: RequestInit,
 ) {
-  return ApiCallers.fetch<Result[] | State[]>(
+  return ApiCallers.fetch<Result[] | State[] | StorageSlot[]>(
     { endpoint: '/state', method: 'get', parameters, options },
   );
 }
@@ -14,6 +14,7 @@ export function getTokens(
     byAcct?: boolean,
     changes?: boolean,
     noZero?: boolean,
+    holders?: boolean,
     chain: string,
     noHeader?: boolean,
     fmt?: string,
@@ -29,4 +29,6 @@ export type Block = {
   transactions: Transaction[]
   transactionsRoot: hash
   uncles?: hash[]
+  blobGasUsed?: gas
+  excessBlobGas?: gas
 }
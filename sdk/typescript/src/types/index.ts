@@ -4,6 +4,7 @@ export * from './appearanceCount';
 export * from './basetypes';
 export * from './block';
 export * from './blockCount';
+export * from './blockFee';
 export * from './bounds';
 export * from './cacheItem';
 export * from './chain';
@@ -15,6 +16,7 @@ export * from './chunkRecord';
 export * from './chunkStats';
 export * from './config';
 export * from './function';
+export * from './gain';
 export * from './log';
 export * from './logFilter';
 export * from './manifest';
@@ -29,6 +31,7 @@ export * from './result';
 export * from './slurp';
 export * from './state';
 export * from './statement';
+export * from './storageSlot';
 export * from './timestamp';
 export * from './timestampCount';
 export * from './token';
@@ -3,7 +3,7 @@
 /*
  * This file was generated with makeClass --sdk. Do not edit it.
  */
-import { address, blknum, datetime, double, hash, int256, timestamp, uint64 } from '.';
+import { address, blknum, datetime, double, hash, int256, timestamp, uint256, uint64 } from '.';
 
 export type Statement = {
   blockNumber: blknum
@@ -35,6 +35,7 @@ export type Statement = {
   minerUncleRewardIn?: int256
   correctingIn?: int256
   prefundIn?: int256
+  withdrawalIn?: int256
   totalOut: int256
   amountOut?: int256
   internalOut?: int256
@@ -48,4 +49,6 @@ export type Statement = {
   endBalDiff?: int256
   endBalCalc?: int256
   correctingReason?: string
+  tokenType?: string
+  tokenId?: uint256
 }
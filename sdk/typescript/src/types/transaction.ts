@@ -31,4 +31,6 @@ export type Transaction = {
   statements: Statement[]
   gasUsed: gas
   type: string
+  maxFeePerBlobGas?: gas
+  blobVersionedHashes?: hash[]
 }
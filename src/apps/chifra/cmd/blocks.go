@@ -78,6 +78,9 @@ One of [ from | to | reward ]`)
 	blocksCmd.Flags().BoolVarP(&blocksPkg.GetOptions().Articulate, "articulate", "a", false, "for the --logs option only, articulate the retrieved data if ABIs can be found")
 	blocksCmd.Flags().Uint64VarP(&blocksPkg.GetOptions().BigRange, "big_range", "r", 500, "for the --logs option only, allow for block ranges larger than 500")
 	blocksCmd.Flags().BoolVarP(&blocksPkg.GetOptions().Count, "count", "U", false, "display the number of the lists of appearances for --addrs or --uniq")
+	blocksCmd.Flags().BoolVarP(&blocksPkg.GetOptions().Fees, "fees", "g", false, "summarize the gas used and the base, priority, and blob fees paid in each block")
+	blocksCmd.Flags().StringVarP(&blocksPkg.GetOptions().Period, "period", "d", "", `for the --fees option only, summarize the fees over each period rather than per block
+One of [ hourly | daily | weekly | monthly | quarterly | annually ]`)
 	blocksCmd.Flags().BoolVarP(&blocksPkg.GetOptions().CacheTxs, "cache_txs", "", false, "force a write of the block's transactions to the cache (slow) (hidden)")
 	blocksCmd.Flags().BoolVarP(&blocksPkg.GetOptions().CacheTraces, "cache_traces", "", false, "force a write of the block's traces to the cache (slower) (hidden)")
 	blocksCmd.Flags().Uint64VarP(&blocksPkg.GetOptions().List, "list", "L", 0, "summary list of blocks running backwards from latest block minus num (hidden)")
@@ -39,8 +39,8 @@ var configCmd = &cobra.Command{
 const usageConfig = `config <mode> [flags]
 
 Arguments:
-  mode - either show or edit the configuration
-	One of [ show | edit ]`
+  mode - either show or edit the configuration, or migrate the cache to the configured store
+	One of [ show | edit | migrate ]`
 
 const shortConfig = "report on and edit the configuration of the TrueBlocks system"
 
@@ -59,7 +59,9 @@ Notes:
   - The --first_record and --max_record options are zero-based (as are the block options).
   - The _block and _record filters are ignored when used with the --count option.
   - If the --reversed option is present, the appearance list is reversed prior to all processing (including filtering).
-  - The --decache option will remove all cache items (blocks, transactions, traces, etc.) for the given address(es).`
+  - The --decache option will remove all cache items (blocks, transactions, traces, etc.) for the given address(es).
+  - The --entity option reconciles its addresses as one account, netting out transfers between them (the addresses may be listed in a file, share a names tag, or be separated by commas, the only form the API accepts).
+  - The --graph option writes a graph in the chosen format (--fmt is ignored), following counterparties up to --hops away and reading at most --max_records appearances for each address and exploring at most the 100 most active addresses found at each hop.`
 
 func init() {
 	var capabilities = caps.Default // Additional global caps for chifra export
@@ -76,6 +78,9 @@ func init() {
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Logs, "logs", "l", false, "export logs instead of transactional data")
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Traces, "traces", "t", false, "export traces instead of transactional data")
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Neighbors, "neighbors", "n", false, "export the neighbors of the given address")
+	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().Graph, "graph", "G", "", `export a weighted, directed graph of value flows and calls among the given addresses in this format
+One of [ graphml | dot | edges ]`)
+	exportCmd.Flags().Uint64VarP(&exportPkg.GetOptions().Hops, "hops", "", 1, "for the --graph option only, the number of hops to follow from the given addresses")
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Accounting, "accounting", "C", false, "attach accounting records to the exported data (applies to transactions export only)")
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Statements, "statements", "A", false, "for the accounting options only, export only statements")
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Balances, "balances", "b", false, "traverse the transaction history and show each change in ETH balances")
@@ -90,6 +95,12 @@ func init() {
 	exportCmd.Flags().StringSliceVarP(&exportPkg.GetOptions().Asset, "asset", "P", nil, "for the accounting options only, export statements only for this asset")
 	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().Flow, "flow", "f", "", `for the accounting options only, export statements with incoming, outgoing, or zero value
 One of [ in | out | zero ]`)
+	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Gains, "gains", "g", false, "for the accounting options only, export realized and unrealized gains per asset per period")
+	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().CostBasis, "cost_basis", "k", "", `for the --gains option only, the order in which lots are relieved (fifo if not specified)
+One of [ fifo | lifo | hifo ]`)
+	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().Period, "period", "d", "", `for the --gains option only, the period over which gains are summarized (year if not specified)
+One of [ year | quarter | month ]`)
+	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().Entity, "entity", "i", "", "for the accounting options only, reconcile the addresses in this file (or with this names tag) as a single entity")
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Factory, "factory", "y", false, "for --traces only, report addresses created by (or self-destructed by) the given address(es)")
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Unripe, "unripe", "u", false, "export transactions labeled upripe (i.e. less than 28 blocks old)")
 	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().Load, "load", "O", "", "a comma separated list of dynamic traversers to load (hidden)")
@@ -55,7 +55,8 @@ Notes:
   - Special blocks are detailed under chifra when --list.
   - Balance is the default mode. To select a single mode use none first, followed by that mode.
   - Valid parameters for --call include Solidity-like syntax: balanceOf(0x316b...183d), a four-byte followed by parameters: 0x70a08231(0x316b...183d), or encoded input data.
-  - You may specify multiple modes on a single line.`
+  - You may specify multiple modes on a single line.
+  - Valid parameters for --storage include slot numbers (decimal or hex) and, with --layout, variables followed by any keys, indices, or members: balances[0x316b...183d], config.fee.`
 
 func init() {
 	var capabilities = caps.Default // Additional global caps for chifra state
@@ -74,6 +75,8 @@ One or more of [ none | some | all | balance | nonce | code | proxy | deployed |
 	stateCmd.Flags().StringVarP(&statePkg.GetOptions().Call, "call", "l", "", "call a smart contract with a solidity syntax, a four-byte and parameters, or encoded call data")
 	stateCmd.Flags().BoolVarP(&statePkg.GetOptions().Articulate, "articulate", "a", false, "for the --call option only, articulate the retrieved data if ABIs can be found")
 	stateCmd.Flags().StringVarP(&statePkg.GetOptions().ProxyFor, "proxy_for", "r", "", "for the --call option only, redirects calls to this implementation")
+	stateCmd.Flags().StringSliceVarP(&statePkg.GetOptions().Storage, "storage", "s", nil, "read one or more storage slots (or, with --layout, variables) of a smart contract")
+	stateCmd.Flags().StringVarP(&statePkg.GetOptions().Layout, "layout", "y", "", "for the --storage option only, a Solidity storage layout (JSON) used to decode the slots")
 	globals.InitGlobals(stateCmd, &statePkg.GetOptions().Globals, capabilities)
 
 	stateCmd.SetUsageTemplate(UsageWithNotes(notesState))
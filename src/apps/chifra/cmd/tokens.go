@@ -54,7 +54,8 @@ Notes:
   - If the token contract(s) from which you request balances are not ERC20 compliant, the results are undefined.
   - If the queried node does not store historical state, the results are undefined.
   - Special blocks are detailed under chifra when --list.
-  - If the --parts option is not empty, all addresses are considered tokens and each token's attributes are presented.`
+  - If the --parts option is not empty, all addresses are considered tokens and each token's attributes are presented.
+  - The --holders option replays the token's Transfer events from its appearances in the index, so the index must be current through the given block(s).`
 
 func init() {
 	var capabilities = caps.Default // Additional global caps for chifra tokens
@@ -69,6 +70,7 @@ One or more of [ name | symbol | decimals | totalSupply | version | all ]`)
 	tokensCmd.Flags().BoolVarP(&tokensPkg.GetOptions().ByAcct, "by_acct", "b", false, "consider each address an ERC20 token except the last, whose balance is reported for each token")
 	tokensCmd.Flags().BoolVarP(&tokensPkg.GetOptions().Changes, "changes", "c", false, "only report a balance when it changes from one block to the next")
 	tokensCmd.Flags().BoolVarP(&tokensPkg.GetOptions().NoZero, "no_zero", "z", false, "suppress the display of zero balance accounts")
+	tokensCmd.Flags().BoolVarP(&tokensPkg.GetOptions().Holders, "holders", "l", false, "rebuild the holders of the ERC20 token and their balances at the given block(s) from its Transfer events")
 	globals.InitGlobals(tokensCmd, &tokensPkg.GetOptions().Globals, capabilities)
 
 	tokensCmd.SetUsageTemplate(UsageWithNotes(notesTokens))
@@ -6,5 +6,5 @@
  * the code inside of 'EXISTING_CODE' tags.
  */
 
-// Package blocksPkg handles the chifra blocks command. It The  tool retrieves block data from your Ethereum node or, if previously cached, from the TrueBlocks cache. You may specify multiple blocks per invocation. By default,  queries the full transactional details of the block (including receipts). You may optionally retrieve only the transaction hashes in the block (which is significantly faster). Additionally, you may also use this tool to retrieve uncle blocks at a give height. Another useful feature of  is the ability to extract address appearances from a block. TrueBlocks uses a similar feature internally to build its index of appearances. This type of data is very insightful when studying end user behavior and chain-wide adoption analysis. 
+// Package blocksPkg handles the chifra blocks command. It The  tool retrieves block data from your Ethereum node or, if previously cached, from the TrueBlocks cache. You may specify multiple blocks per invocation. By default,  queries the full transactional details of the block (including receipts). You may optionally retrieve only the transaction hashes in the block (which is significantly faster). Additionally, you may also use this tool to retrieve uncle blocks at a give height. Another useful feature of  is the ability to extract address appearances from a block. TrueBlocks uses a similar feature internally to build its index of appearances. This type of data is very insightful when studying end user behavior and chain-wide adoption analysis. The --fees option summarizes the gas used and the fees paid in each block, including the base fee, the priority fees paid at the 10th, 50th, and 90th percentiles of gas used, the ether burned, and the blob gas used. Add --period to sum the blocks into hourly, daily, weekly, monthly, quarterly, or annual periods. 
 package blocksPkg
@@ -0,0 +1,128 @@
+package blocksPkg
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// feeAggregator sums the fee summaries of consecutive blocks into one summary per period
+type feeAggregator struct {
+	period  string
+	current *types.SimpleBlockFee
+	// the gas-weighted sums of the blocks' priority fee percentiles
+	p10, p50, p90 float64
+	baseFeeSum    float64
+}
+
+func newFeeAggregator(period string) *feeAggregator {
+	return &feeAggregator{period: period}
+}
+
+// add adds a block's fees to the current period. If the block starts a new period, the
+// completed period is returned. Otherwise, add returns nil.
+func (a *feeAggregator) add(fee *types.SimpleBlockFee) *types.SimpleBlockFee {
+	var ret *types.SimpleBlockFee
+	name := periodOf(fee.Timestamp, a.period)
+	if a.current != nil && a.current.Period != name {
+		ret = a.flush()
+	}
+
+	if a.current == nil {
+		a.current = &types.SimpleBlockFee{
+			BlockNumber: fee.BlockNumber,
+			Timestamp:   fee.Timestamp,
+			Period:      name,
+		}
+		a.p10, a.p50, a.p90, a.baseFeeSum = 0, 0, 0, 0
+	}
+
+	cur := a.current
+	cur.LastBlock = fee.BlockNumber
+	cur.BlocksCnt++
+	cur.TransactionsCnt += fee.TransactionsCnt
+	cur.GasUsed += fee.GasUsed
+	cur.GasLimit += fee.GasLimit
+	cur.BlobGasUsed += fee.BlobGasUsed
+	cur.Burned.Add(&cur.Burned, &fee.Burned)
+
+	gas := float64(fee.GasUsed)
+	a.p10 += float64(fee.PriorityFeeP10) * gas
+	a.p50 += float64(fee.PriorityFeeP50) * gas
+	a.p90 += float64(fee.PriorityFeeP90) * gas
+	a.baseFeeSum += float64(fee.BaseFeePerGas)
+
+	return ret
+}
+
+// flush returns the current period (if any) with its averages computed and starts a new one.
+// The period's base fee is the average base fee paid per unit of gas (the amount burned divided
+// by the gas used) and its priority fees are the gas-weighted averages of its blocks' percentiles.
+func (a *feeAggregator) flush() *types.SimpleBlockFee {
+	ret := a.current
+	if ret == nil {
+		return nil
+	}
+	a.current = nil
+
+	if ret.GasUsed > 0 {
+		gas := float64(ret.GasUsed)
+		burned, _ := new(big.Float).SetInt(&ret.Burned).Float64()
+		ret.BaseFeePerGas = base.Gas(math.Round(burned / gas))
+		ret.PriorityFeeP10 = base.Gas(math.Round(a.p10 / gas))
+		ret.PriorityFeeP50 = base.Gas(math.Round(a.p50 / gas))
+		ret.PriorityFeeP90 = base.Gas(math.Round(a.p90 / gas))
+	} else {
+		ret.BaseFeePerGas = base.Gas(math.Round(a.baseFeeSum / float64(ret.BlocksCnt)))
+	}
+
+	return ret
+}
+
+// isWholePeriod returns true if the summary covers every block of its period and the period is
+// final. That's the case if its blocks are consecutive and the blocks just before and just after
+// them belong to other periods. next, if not nil, is the block that started the following period.
+func (opts *BlocksOptions) isWholePeriod(fee, next *types.SimpleBlockFee) bool {
+	if fee.BlocksCnt != fee.LastBlock-fee.BlockNumber+1 {
+		return false
+	}
+
+	if fee.BlockNumber > 0 && periodOf(opts.Conn.GetBlockTimestamp(fee.BlockNumber-1), opts.Period) == fee.Period {
+		return false
+	}
+
+	var nextTs base.Timestamp
+	if next != nil && next.BlockNumber == fee.LastBlock+1 {
+		nextTs = next.Timestamp
+	} else if fee.LastBlock < opts.Conn.GetLatestBlockNumber() {
+		nextTs = opts.Conn.GetBlockTimestamp(fee.LastBlock + 1)
+	} else {
+		return false
+	}
+
+	return periodOf(nextTs, opts.Period) != fee.Period && base.IsFinal(opts.Conn.LatestBlockTimestamp, nextTs)
+}
+
+// periodOf returns the name of the period containing the timestamp
+func periodOf(ts base.Timestamp, period string) string {
+	t := time.Unix(ts, 0).UTC()
+	switch period {
+	case "hourly":
+		return fmt.Sprintf("%04d-%02d-%02dT%02d", t.Year(), t.Month(), t.Day(), t.Hour())
+	case "daily":
+		return fmt.Sprintf("%04d-%02d-%02d", t.Year(), t.Month(), t.Day())
+	case "weekly":
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	case "monthly":
+		return fmt.Sprintf("%04d-%02d", t.Year(), t.Month())
+	case "quarterly":
+		return fmt.Sprintf("%04d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+	default:
+		return fmt.Sprintf("%04d", t.Year())
+	}
+}
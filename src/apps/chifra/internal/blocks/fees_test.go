@@ -0,0 +1,72 @@
+package blocksPkg
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func TestFeeAggregator(t *testing.T) {
+	const day = 24 * 60 * 60
+	fee := func(bn uint64, ts int64, gasUsed, baseFee, p50 uint64) *types.SimpleBlockFee {
+		ret := &types.SimpleBlockFee{
+			BlockNumber:     bn,
+			Timestamp:       ts,
+			TransactionsCnt: 2,
+			GasUsed:         gasUsed,
+			GasLimit:        30000000,
+			BaseFeePerGas:   baseFee,
+			PriorityFeeP50:  p50,
+		}
+		ret.Burned.Mul(new(big.Int).SetUint64(baseFee), new(big.Int).SetUint64(gasUsed))
+		return ret
+	}
+
+	aggregator := newFeeAggregator("daily")
+	if done := aggregator.add(fee(1, 10*day, 10000000, 10, 1)); done != nil {
+		t.Fatal("unexpected period", done.Period)
+	}
+	if done := aggregator.add(fee(2, 10*day+12, 30000000, 20, 3)); done != nil {
+		t.Fatal("unexpected period", done.Period)
+	}
+
+	done := aggregator.add(fee(3, 11*day, 0, 30, 0))
+	if done == nil {
+		t.Fatal("expected the first day to be complete")
+	}
+	if done.Period != "1970-01-11" || done.BlockNumber != 1 || done.LastBlock != 2 || done.BlocksCnt != 2 {
+		t.Error("wrong period", done.Period, done.BlockNumber, done.LastBlock, done.BlocksCnt)
+	}
+	if done.TransactionsCnt != 4 || done.GasUsed != 40000000 || done.GasLimit != 60000000 {
+		t.Error("wrong totals", done.TransactionsCnt, done.GasUsed, done.GasLimit)
+	}
+	if done.Burned.Uint64() != 700000000 || done.BaseFeePerGas != 18 || done.PriorityFeeP50 != 3 {
+		t.Error("wrong fees", done.Burned.String(), done.BaseFeePerGas, done.PriorityFeeP50)
+	}
+
+	last := aggregator.flush()
+	if last == nil || last.Period != "1970-01-12" || last.BaseFeePerGas != 30 {
+		t.Error("wrong last period", last)
+	}
+	if aggregator.flush() != nil {
+		t.Error("expected nothing after the last period")
+	}
+}
+
+func TestPeriodOf(t *testing.T) {
+	ts := int64(1700000000) // 2023-11-14T22:13:20 UTC
+	tests := map[string]string{
+		"hourly":    "2023-11-14T22",
+		"daily":     "2023-11-14",
+		"weekly":    "2023-W46",
+		"monthly":   "2023-11",
+		"quarterly": "2023-Q4",
+		"annually":  "2023",
+	}
+	for period, want := range tests {
+		if got := periodOf(ts, period); got != want {
+			t.Error("wrong name for", period, got, want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package blocksPkg
+
+import (
+	"context"
+	"errors"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/ethereum/go-ethereum"
+)
+
+func (opts *BlocksOptions) HandleFees() error {
+	chain := opts.Globals.Chain
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fetchData := func(modelChan chan types.Modeler[types.RawBlockFee], errorChan chan error) {
+		aggregator := newFeeAggregator(opts.Period)
+		// finished reports a completed period, caching it if it covers the whole period. next is
+		// the block that started the following period, if any.
+		finished := func(done, next *types.SimpleBlockFee) {
+			if opts.Conn.CachesBlockFeePeriods() && opts.isWholePeriod(done, next) {
+				opts.Conn.WriteBlockFeePeriod(done)
+			}
+			modelChan <- done
+		}
+
+		for _, br := range opts.BlockIds {
+			blockNums, err := br.ResolveBlocks(chain)
+			if err != nil {
+				errorChan <- err
+				if errors.Is(err, ethereum.NotFound) {
+					continue
+				}
+				cancel()
+				return
+			}
+
+			for i := 0; i < len(blockNums); i++ {
+				bn := blockNums[i]
+				fee, err := opts.Conn.GetBlockFee(bn)
+				if err != nil {
+					errorChan <- err
+					if errors.Is(err, ethereum.NotFound) {
+						continue
+					}
+					cancel()
+					return
+				}
+
+				if len(opts.Period) == 0 {
+					modelChan <- &fee
+					continue
+				}
+
+				starts := aggregator.current == nil || aggregator.current.Period != periodOf(fee.Timestamp, opts.Period)
+				if done := aggregator.add(&fee); done != nil {
+					finished(done, &fee)
+				}
+
+				// If this block starts a period we've cached, and we're asked for the whole period,
+				// the cached period is reported instead of its remaining blocks
+				if starts {
+					if cached, ok := opts.Conn.GetBlockFeePeriod(aggregator.current.Period); ok && cached.BlockNumber == bn {
+						if n := int(cached.LastBlock - bn); i+n < len(blockNums) && blockNums[i+n] == cached.LastBlock {
+							aggregator.current = nil
+							modelChan <- &cached
+							i += n
+						}
+					}
+				}
+			}
+		}
+
+		if last := aggregator.flush(); last != nil {
+			finished(last, nil)
+		}
+	}
+
+	return output.StreamMany(ctx, fetchData, opts.Globals.OutputOpts())
+}
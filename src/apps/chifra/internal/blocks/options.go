@@ -35,6 +35,8 @@ type BlocksOptions struct {
 	Articulate  bool                     `json:"articulate,omitempty"`  // For the --logs option only, articulate the retrieved data if ABIs can be found
 	BigRange    uint64                   `json:"bigRange,omitempty"`    // For the --logs option only, allow for block ranges larger than 500
 	Count       bool                     `json:"count,omitempty"`       // Display the number of the lists of appearances for --addrs or --uniq
+	Fees        bool                     `json:"fees,omitempty"`        // Summarize the gas used and the base, priority, and blob fees paid in each block
+	Period      string                   `json:"period,omitempty"`      // For the --fees option only, summarize the fees over each period rather than per block
 	CacheTxs    bool                     `json:"cacheTxs,omitempty"`    // Force a write of the block's transactions to the cache (slow)
 	CacheTraces bool                     `json:"cacheTraces,omitempty"` // Force a write of the block's traces to the cache (slower)
 	List        uint64                   `json:"list,omitempty"`        // Summary list of blocks running backwards from latest block minus num
@@ -64,6 +66,8 @@ func (opts *BlocksOptions) testLog() {
 	logger.TestLog(opts.Articulate, "Articulate: ", opts.Articulate)
 	logger.TestLog(opts.BigRange != 500, "BigRange: ", opts.BigRange)
 	logger.TestLog(opts.Count, "Count: ", opts.Count)
+	logger.TestLog(opts.Fees, "Fees: ", opts.Fees)
+	logger.TestLog(len(opts.Period) > 0, "Period: ", opts.Period)
 	logger.TestLog(opts.CacheTxs, "CacheTxs: ", opts.CacheTxs)
 	logger.TestLog(opts.CacheTraces, "CacheTraces: ", opts.CacheTraces)
 	logger.TestLog(opts.List != 0, "List: ", opts.List)
@@ -120,6 +124,10 @@ func blocksFinishParseApi(w http.ResponseWriter, r *http.Request) *BlocksOptions
 			opts.BigRange = globals.ToUint64(value[0])
 		case "count":
 			opts.Count = true
+		case "fees":
+			opts.Fees = true
+		case "period":
+			opts.Period = value[0]
 		case "cacheTxs":
 			opts.CacheTxs = true
 		case "cacheTraces":
@@ -64,6 +64,9 @@ func (opts *BlocksOptions) BlocksInternal() (err error, handled bool) {
 	} else if opts.Count {
 		err = opts.HandleCounts()
 
+	} else if opts.Fees {
+		err = opts.HandleFees()
+
 	} else if opts.Logs {
 		err = opts.HandleLogs()
 
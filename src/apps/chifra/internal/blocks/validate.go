@@ -77,6 +77,22 @@ func (opts *BlocksOptions) validateBlocks() error {
 		}
 	}
 
+	if len(opts.Period) > 0 {
+		if !opts.Fees {
+			return validate.Usage("The {0} option is only available with the {1} option.", "--period", "--fees")
+		}
+		err := validate.ValidateEnum("--period", opts.Period, "[hourly|daily|weekly|monthly|quarterly|annually]")
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.Fees {
+		if opts.Count || opts.Logs || opts.Traces || opts.Uniq || opts.Uncles || opts.Hashes {
+			return validate.Usage("The {0} option is not available{1}.", "--fees", " with any other option")
+		}
+	}
+
 	if len(opts.Globals.File) > 0 {
 		// Do nothing
 	} else {
@@ -6,5 +6,5 @@
  * the code inside of 'EXISTING_CODE' tags.
  */
 
-// Package chunksPkg handles the chifra chunks command. It The  routine provides tools for interacting with, checking the validity of, cleaning up, and analyizing the Unchained Index. It provides options to list pins, the Manifest, summary data on the index, Bloom filters, addresses, and appearances. While still in its early stages, this tool will eventually allow users to clean their local index, clean their remote index, study the indexes, etc. Stay tuned. 
+// Package chunksPkg handles the chifra chunks command. It The  routine provides tools for interacting with, checking the validity of, cleaning up, and analyizing the Unchained Index. It provides options to list pins, the Manifest, summary data on the index, Bloom filters, addresses, and appearances. While still in its early stages, this tool will eventually allow users to clean their local index, clean their remote index, study the indexes, etc. Stay tuned. Index chunks, Bloom filters, the timestamps file, and monitors store block numbers in four bytes until a block number no longer fits, at which point the file is written with eight-byte block numbers. Each file's header records which version it is, so the two versions coexist. The --upgrade option rewrites the local index chunks and the timestamps file with eight-byte block numbers. Upgraded chunks no longer match the IPFS hashes in the published manifest. 
 package chunksPkg
@@ -48,12 +48,12 @@ func (opts *ChunksOptions) HandleAppearances(blockNums []uint64) error {
 					continue
 				}
 				rec := index.AppearanceRecord{}
-				err := rec.ReadAppearance(indexChunk.File)
+				err := rec.ReadAppearance(indexChunk.File, indexChunk.Header.AppRecordWidth())
 				if err != nil {
 					return false, err
 				}
 				s := types.SimpleAppearance{
-					BlockNumber:      rec.BlockNumber,
+					BlockNumber:      uint32(rec.BlockNumber),
 					TransactionIndex: rec.TransactionId,
 				}
 				modelChan <- &s
@@ -46,7 +46,7 @@ func (opts *ChunksOptions) checkIndexChunkInternal(testId int, fileName string,
 			msg := fmt.Sprintf("%s: Magic number expected (0x%x) got (0x%x)", rng, header.Magic, file.MagicNumber)
 			report.MsgStrings = append(report.MsgStrings, msg)
 
-		} else if header.Hash.Hex() != unchained.HeaderMagicHash || (testId == 2) {
+		} else if !unchained.IsHeaderMagicHash(header.Hash.Hex()) || (testId == 2) {
 			msg := fmt.Sprintf("%s: Header hash expected (%s) got (%s)", rng, header.Hash.Hex(), unchained.HeaderMagicHash)
 			report.MsgStrings = append(report.MsgStrings, msg)
 
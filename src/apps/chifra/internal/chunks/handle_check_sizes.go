@@ -43,6 +43,12 @@ func (opts *ChunksOptions) CheckSizes(fileNames []string, blockNums []uint64, ca
 			okay := true // the test passes only if both pass unless there's only one
 			if file.FileExists(indexFn) {
 				indexSize := file.FileSize(indexFn)
+				if indexSize != idxSizeInMan[rng] {
+					// Chunks upgraded to 64-bit block numbers are compared at the size they had before
+					if size32, err := index.Size32(indexFn); err == nil {
+						indexSize = size32
+					}
+				}
 				if indexSize != idxSizeInMan[rng] {
 					report.MsgStrings = append(report.MsgStrings, fmt.Sprintf("Size of index %s (%d) not as expected in manifest (%d)", rng, indexSize, idxSizeInMan[rng]))
 					okay = false
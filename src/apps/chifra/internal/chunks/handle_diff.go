@@ -92,7 +92,7 @@ func (opts *ChunksOptions) exportTo(dest, source string) (bool, error) {
 		for _, app := range s.Appearances {
 			apps = append(apps, types.SimpleAppearance{
 				Address:          s.AddressRecord.Address,
-				BlockNumber:      app.BlockNumber,
+				BlockNumber:      uint32(app.BlockNumber),
 				TransactionIndex: app.TransactionId,
 			})
 		}
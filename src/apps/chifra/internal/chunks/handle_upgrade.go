@@ -0,0 +1,103 @@
+package chunksPkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/colors"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/tslib"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/usage"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/walk"
+)
+
+// HandleUpgrade rewrites the index chunks (and the timestamps file) with 64-bit block numbers. Chunks
+// already upgraded are skipped, so the two versions of the index may coexist.
+func (opts *ChunksOptions) HandleUpgrade(blockNums []uint64) error {
+	chain := opts.Globals.Chain
+
+	if opts.Globals.TestMode {
+		logger.Warn("Upgrade option not tested.")
+		return nil
+	}
+
+	if !opts.Globals.IsApiMode() && !usage.QueryUser(upgradeWarning, "Not upgrading") {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fetchData := func(modelChan chan types.Modeler[types.RawModeler], errorChan chan error) {
+		nChunksUpgraded := 0
+		upgradeIndex := func(walker *walk.CacheWalker, path string, first bool) (bool, error) {
+			if path != index.ToBloomPath(path) {
+				logger.Fatal("should not happen ==> we're spinning through the bloom filters")
+			}
+
+			if strings.HasSuffix(path, ".gz") {
+				return true, nil
+			}
+
+			rng, err := base.RangeFromFilenameE(path)
+			if err != nil {
+				return false, err
+			}
+
+			changed, err := index.UpgradeChunk(chain, index.ToIndexPath(path))
+			if err != nil {
+				return false, err
+			}
+			if changed {
+				nChunksUpgraded++
+				if opts.Globals.Verbose {
+					logger.Info(colors.Green, "Upgraded chunk at "+rng.String(), colors.Off)
+				}
+			}
+
+			return true, nil
+		}
+
+		walker := walk.NewCacheWalker(
+			chain,
+			opts.Globals.TestMode,
+			100, /* maxTests */
+			upgradeIndex,
+		)
+		if err := walker.WalkBloomFilters(blockNums); err != nil {
+			errorChan <- err
+			cancel()
+			return
+		}
+
+		tsUpgraded, err := tslib.Upgrade(chain)
+		if err != nil {
+			errorChan <- err
+			cancel()
+			return
+		}
+
+		// All that's left to do is report on what happened.
+		fin := "."
+		if tsUpgraded {
+			fin = ", the timestamps file was upgraded."
+		}
+		msg := fmt.Sprintf("%d chunks upgraded to 64-bit block numbers%s", nChunksUpgraded, fin)
+		if opts.Globals.Format == "json" {
+			s := types.SimpleMessage{
+				Msg: msg,
+			}
+			modelChan <- &s
+		} else {
+			logger.Info(msg)
+		}
+	}
+
+	opts.Globals.NoHeader = true
+	return output.StreamMany(ctx, fetchData, opts.Globals.OutputOpts())
+}
+
+var upgradeWarning = `Upgraded chunks no longer match the published manifest. Are sure you want to upgrade the index (Yy)? `
@@ -32,6 +32,7 @@ type ChunksOptions struct {
 	Publish    bool                     `json:"publish,omitempty"`    // Publish the manifest to the Unchained Index smart contract
 	Publisher  string                   `json:"publisher,omitempty"`  // For some query options, the publisher of the index
 	Truncate   uint64                   `json:"truncate,omitempty"`   // Truncate the entire index at this block (requires a block identifier)
+	Upgrade    bool                     `json:"upgrade,omitempty"`    // In index mode only, rewrite the index chunks and timestamps with 64-bit block numbers
 	Remote     bool                     `json:"remote,omitempty"`     // Prior to processing, retreive the manifest from the Unchained Index smart contract
 	Belongs    []string                 `json:"belongs,omitempty"`    // In index mode only, checks the address(es) for inclusion in the given index chunk
 	Diff       bool                     `json:"diff,omitempty"`       // Compare two index portions (see notes)
@@ -64,6 +65,7 @@ func (opts *ChunksOptions) testLog() {
 	logger.TestLog(opts.Publish, "Publish: ", opts.Publish)
 	logger.TestLog(!rpc.IsSame(opts.Publisher, "trueblocks.eth"), "Publisher: ", opts.Publisher)
 	logger.TestLog(opts.Truncate != utils.NOPOS, "Truncate: ", opts.Truncate)
+	logger.TestLog(opts.Upgrade, "Upgrade: ", opts.Upgrade)
 	logger.TestLog(opts.Remote, "Remote: ", opts.Remote)
 	logger.TestLog(len(opts.Belongs) > 0, "Belongs: ", opts.Belongs)
 	logger.TestLog(opts.Diff, "Diff: ", opts.Diff)
@@ -110,6 +112,8 @@ func chunksFinishParseApi(w http.ResponseWriter, r *http.Request) *ChunksOptions
 			opts.Publisher = value[0]
 		case "truncate":
 			opts.Truncate = globals.ToUint64(value[0])
+		case "upgrade":
+			opts.Upgrade = true
 		case "remote":
 			opts.Remote = true
 		case "belongs":
@@ -192,7 +196,7 @@ func chunksFinishParse(args []string) *ChunksOptions {
 	getDef := func(def string) string {
 		if (opts.Mode == "index" && opts.Check) ||
 			(opts.Mode == "manifest" && opts.Check) ||
-			opts.Truncate != utils.NOPOS || len(opts.Belongs) > 0 {
+			opts.Truncate != utils.NOPOS || opts.Upgrade || len(opts.Belongs) > 0 {
 			return "json"
 		}
 		return def
@@ -84,6 +84,9 @@ func (opts *ChunksOptions) ChunksInternal() (err error, handled bool) {
 	} else if opts.Truncate != utils.NOPOS {
 		err = opts.HandleTruncate(blockNums)
 
+	} else if opts.Upgrade {
+		err = opts.HandleUpgrade(blockNums)
+
 	} else if opts.Check {
 		err = opts.HandleCheck(blockNums)
 
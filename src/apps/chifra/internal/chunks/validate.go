@@ -103,6 +103,9 @@ func (opts *ChunksOptions) validateChunks() error {
 		if opts.Truncate != utils.NOPOS {
 			return validate.Usage("The {0} option is only available {1}.", "--truncate", "in index mode")
 		}
+		if opts.Upgrade {
+			return validate.Usage("The {0} option is only available {1}.", "--upgrade", "in index mode")
+		}
 		if len(opts.Belongs) > 0 {
 			return validate.Usage("The {0} option requires {1}.", "--belongs", "the index mode")
 		}
@@ -200,6 +203,9 @@ func (opts *ChunksOptions) isDisallowed(test bool, mode string) error {
 		if opts.Truncate != utils.NOPOS {
 			return validate.Usage("The {0} option is not available in {1} mode.", "--truncate", mode)
 		}
+		if opts.Upgrade {
+			return validate.Usage("The {0} option is not available in {1} mode.", "--upgrade", mode)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,40 @@
+package configPkg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// HandleMigrate moves the binary cache for the chain from the file system (i.e., the
+// cache/<chain>/v1 folder) into the store named by the `cacheStore` setting.
+func (opts *ConfigOptions) HandleMigrate() error {
+	chain := opts.Globals.Chain
+
+	to, err := cache.LocationFromName(config.GetCacheStore())
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	fetchData := func(modelChan chan types.Modeler[types.RawModeler], errorChan chan error) {
+		report := func(path string, nMoved int) {
+			logger.Progress(nMoved%1000 == 0, fmt.Sprintf("Migrated %d cache items", nMoved))
+		}
+		if nMoved, err := cache.MigrateStore(chain, cache.FsCache, to, report); err != nil {
+			errorChan <- err
+		} else {
+			modelChan <- &types.SimpleMessage{
+				Msg: fmt.Sprintf("Migrated %d cache items to the %s cache store.", nMoved, config.GetCacheStore()),
+			}
+		}
+	}
+
+	opts.Globals.NoHeader = true
+	return output.StreamMany(ctx, fetchData, opts.Globals.OutputOpts())
+}
@@ -20,7 +20,7 @@ import (
 
 // ConfigOptions provides all command options for the chifra config command.
 type ConfigOptions struct {
-	Mode    string                `json:"mode,omitempty"`    // Either show or edit the configuration
+	Mode    string                `json:"mode,omitempty"`    // Either show or edit the configuration, or migrate the cache to the configured store
 	Paths   bool                  `json:"paths,omitempty"`   // Show the configuration paths for the system
 	Globals globals.GlobalOptions `json:"globals,omitempty"` // The global options
 	Conn    *rpc.Connection       `json:"conn,omitempty"`    // The connection to the RPC server
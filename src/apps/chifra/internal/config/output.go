@@ -64,6 +64,8 @@ func (opts *ConfigOptions) ConfigInternal() (err error, handled bool) {
 		err = opts.HandleEdit()
 	} else if opts.Mode == "show" {
 		err = opts.HandleShow()
+	} else if opts.Mode == "migrate" {
+		err = opts.HandleMigrate()
 	}
 	// EXISTING_CODE
 	timer.Report(msg)
@@ -7,6 +7,7 @@ package configPkg
 import (
 	"os"
 
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/validate"
 )
@@ -24,10 +25,22 @@ func (opts *ConfigOptions) validateConfig() error {
 		return validate.Usage("chain {0} is not properly configured.", chain)
 	}
 
-	if err := validate.ValidateEnum("modes", opts.Mode, "[show|edit]"); err != nil {
+	if err := validate.ValidateEnum("modes", opts.Mode, "[show|edit|migrate]"); err != nil {
 		return err
 	}
 
+	if opts.Mode == "migrate" {
+		if opts.Globals.IsApiMode() {
+			return validate.Usage("The {0} mode is not available from the API", "migrate")
+		}
+
+		if store := config.GetCacheStore(); store == "fs" {
+			return validate.Usage("To {0}, set {1} in trueBlocks.toml to a store other than {2}.", "migrate", "cacheStore", "fs")
+		} else if _, err := cache.LocationFromName(store); err != nil {
+			return validate.Usage(err.Error())
+		}
+	}
+
 	if !opts.Globals.TestMode && opts.Mode == "edit" && os.Getenv("EDITOR") == "" {
 		return validate.Usage("You must set the EDITOR environment variable to use the 'edit' mode.")
 	}
@@ -0,0 +1,277 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package daemonPkg
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+)
+
+// Scope is the access a client's credentials grant to the daemon's routes
+type Scope int
+
+const (
+	// NoScope is the scope of a request without valid credentials
+	NoScope Scope = iota
+	// ReadScope allows routes that only read data
+	ReadScope
+	// AdminScope allows every route, including those that change the index, the cache, monitors, or names
+	AdminScope
+)
+
+func (s Scope) String() string {
+	switch s {
+	case ReadScope:
+		return "read"
+	case AdminScope:
+		return "admin"
+	}
+	return "none"
+}
+
+// Authenticator checks the credentials presented with each request. Clients send either an API
+// key (in the X-API-Key header or as a bearer token) or a JWT (as a bearer token) signed with
+// HS256 whose `scope` claim includes `read` or `admin`. Websocket clients, which can't set headers
+// from a browser, may instead send either in the `token` query parameter.
+type Authenticator struct {
+	readKey   string
+	adminKey  string
+	jwtSecret string
+}
+
+// NewAuthenticator returns an Authenticator with the credentials in the `[keys.daemon]` config group
+func NewAuthenticator() *Authenticator {
+	readKey, adminKey, jwtSecret := config.GetDaemonKeys()
+	return &Authenticator{
+		readKey:   readKey,
+		adminKey:  adminKey,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// Enabled returns true if any credentials are configured. If none are, every request is allowed.
+func (a *Authenticator) Enabled() bool {
+	return a != nil && len(a.readKey)+len(a.adminKey)+len(a.jwtSecret) > 0
+}
+
+// Methods returns the kinds of credentials the Authenticator accepts
+func (a *Authenticator) Methods() []string {
+	ret := []string{}
+	if len(a.readKey) > 0 || len(a.adminKey) > 0 {
+		ret = append(ret, "api key")
+	}
+	if len(a.jwtSecret) > 0 {
+		ret = append(ret, "jwt")
+	}
+	return ret
+}
+
+var (
+	errNoCredentials      = errors.New("this route requires an API key or a bearer token")
+	errInvalidCredentials = errors.New("the API key or bearer token is invalid")
+)
+
+// credentials returns the API key or bearer token sent with the request, if any
+func credentials(r *http.Request) string {
+	token := r.Header.Get("X-API-Key")
+	if len(token) == 0 {
+		if auth := r.Header.Get("Authorization"); len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+			token = strings.TrimSpace(auth[7:])
+		}
+	}
+	if len(token) == 0 && isWebsocketRequest(r) {
+		token = r.URL.Query().Get("token")
+	}
+	return token
+}
+
+// Authorize returns the scope granted by the request's credentials
+func (a *Authenticator) Authorize(r *http.Request) (Scope, error) {
+	token := credentials(r)
+	if len(token) == 0 {
+		return NoScope, errNoCredentials
+	}
+
+	if keyMatches(token, a.adminKey) {
+		return AdminScope, nil
+	}
+	if keyMatches(token, a.readKey) {
+		return ReadScope, nil
+	}
+	if len(a.jwtSecret) > 0 && strings.Count(token, ".") == 2 {
+		return verifyJwt(token, a.jwtSecret, time.Now())
+	}
+	return NoScope, errInvalidCredentials
+}
+
+// keyMatches compares the token to a configured key in constant time. An empty key never matches.
+func keyMatches(token, key string) bool {
+	return len(key) > 0 && subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1
+}
+
+// verifyJwt checks the token's signature (only HS256 is accepted) and its `exp` and `nbf` claims, if
+// present, and returns the broadest scope listed in its space separated `scope` claim
+func verifyJwt(token, secret string, now time.Time) (Scope, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return NoScope, errInvalidCredentials
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJwtPart(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return NoScope, errInvalidCredentials
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return NoScope, errInvalidCredentials
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return NoScope, errInvalidCredentials
+	}
+
+	var claims struct {
+		Scope     string `json:"scope"`
+		ExpiresAt *int64 `json:"exp"`
+		NotBefore *int64 `json:"nbf"`
+	}
+	if err := decodeJwtPart(parts[1], &claims); err != nil {
+		return NoScope, errInvalidCredentials
+	}
+	if claims.ExpiresAt != nil && now.Unix() >= *claims.ExpiresAt {
+		return NoScope, errors.New("the bearer token has expired")
+	}
+	if claims.NotBefore != nil && now.Unix() < *claims.NotBefore {
+		return NoScope, errors.New("the bearer token is not yet valid")
+	}
+
+	scope := NoScope
+	for _, s := range strings.Fields(claims.Scope) {
+		switch s {
+		case "admin":
+			scope = AdminScope
+		case "read":
+			if scope < ReadScope {
+				scope = ReadScope
+			}
+		}
+	}
+	if scope == NoScope {
+		return NoScope, errors.New("the bearer token does not grant a scope")
+	}
+	return scope, nil
+}
+
+func decodeJwtPart(part string, v interface{}) error {
+	bytes, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+// publicRoutes are the routes that do not require credentials
+var publicRoutes = map[string]bool{
+	"Index": true,
+}
+
+// adminRoutes are the routes that always require the admin scope
+var adminRoutes = map[string]bool{
+	"CreateName":     true,
+	"EditName":       true,
+	"DeleteName":     true,
+	"DeleteMonitors": true,
+	"RouteScrape":    true,
+	"RouteInit":      true,
+}
+
+// adminParams are, by route, the options that change data (rather than only read it). Requests that
+// use them require the admin scope.
+var adminParams = map[string][]string{
+	"RouteNames":    {"autoname", "create", "update", "delete", "undelete", "remove", "clean"},
+	"RouteMonitors": {"delete", "undelete", "remove", "clean", "watch"},
+	"RouteAbis":     {"clean"},
+	"RouteChunks":   {"pin", "publish", "truncate", "upgrade"},
+	"RouteWhen":     {"truncate", "repair", "update"},
+}
+
+// adminValues are, by route and option, the values of otherwise read-only options that change data.
+// Requests that use them require the admin scope.
+var adminValues = map[string]map[string][]string{
+	"RouteConfig": {"mode": {"edit", "migrate"}},
+}
+
+// RequiredScope returns the scope needed to make the request to the named route
+func RequiredScope(name string, r *http.Request) Scope {
+	if publicRoutes[name] {
+		return NoScope
+	}
+	if adminRoutes[name] {
+		return AdminScope
+	}
+
+	params := r.URL.Query()
+	if params.Has("decache") {
+		return AdminScope
+	}
+	for _, param := range adminParams[name] {
+		if params.Has(param) {
+			return AdminScope
+		}
+	}
+	for param, values := range adminValues[name] {
+		for _, value := range params[param] {
+			for _, v := range values {
+				if strings.EqualFold(value, v) {
+					return AdminScope
+				}
+			}
+		}
+	}
+	return ReadScope
+}
+
+// Authorizer rejects requests to the named route whose credentials do not grant the scope it requires
+func Authorizer(inner http.Handler, name string, auth *Authenticator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		required := RequiredScope(name, r)
+		if !auth.Enabled() || required == NoScope {
+			inner.ServeHTTP(w, r)
+			return
+		}
+
+		scope, err := auth.Authorize(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="chifra"`)
+			RespondWithError(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if scope < required {
+			RespondWithError(w, http.StatusForbidden, errors.New("this request requires the "+required.String()+" scope"))
+			return
+		}
+
+		inner.ServeHTTP(w, r)
+	})
+}
+
+// isWebsocketRequest returns true if the request asks to upgrade to a websocket
+func isWebsocketRequest(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package daemonPkg
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testSecret = "not-a-real-secret"
+
+// helperJwt returns an HS256 token with the given claims signed with the secret
+func helperJwt(claims, secret string) string {
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + enc.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + enc.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyJwt(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		token    string
+		expected Scope
+		wantErr  bool
+	}{
+		{token: helperJwt(`{"scope":"read"}`, testSecret), expected: ReadScope},
+		{token: helperJwt(`{"scope":"read admin","exp":1700000001}`, testSecret), expected: AdminScope},
+		{token: helperJwt(`{"scope":"admin","exp":1700000000}`, testSecret), wantErr: true},
+		{token: helperJwt(`{"scope":"admin","nbf":1700000001}`, testSecret), wantErr: true},
+		{token: helperJwt(`{"scope":"write"}`, testSecret), wantErr: true},
+		{token: helperJwt(`{"scope":"admin"}`, "wrong-secret"), wantErr: true},
+		{token: "eyJhbGciOiJub25lIn0.eyJzY29wZSI6ImFkbWluIn0.", wantErr: true},
+	}
+
+	for i, tt := range tests {
+		scope, err := verifyJwt(tt.token, testSecret, now)
+		if (err != nil) != tt.wantErr || scope != tt.expected {
+			t.Error(i, "wrong scope", scope, err)
+		}
+	}
+}
+
+func TestAuthorizer(t *testing.T) {
+	auth := &Authenticator{readKey: "reader", adminKey: "admin", jwtSecret: testSecret}
+	tests := []struct {
+		name     string
+		url      string
+		header   string
+		value    string
+		expected int
+	}{
+		{name: "Index", url: "/", expected: http.StatusOK},
+		{name: "RouteBlocks", url: "/blocks?blocks=1", expected: http.StatusUnauthorized},
+		{name: "RouteBlocks", url: "/blocks?blocks=1", header: "X-API-Key", value: "nobody", expected: http.StatusUnauthorized},
+		{name: "RouteBlocks", url: "/blocks?blocks=1", header: "X-API-Key", value: "reader", expected: http.StatusOK},
+		{name: "RouteBlocks", url: "/blocks?blocks=1&decache", header: "X-API-Key", value: "reader", expected: http.StatusForbidden},
+		{name: "RouteMonitors", url: "/monitors?addrs=0x1&delete", header: "X-API-Key", value: "reader", expected: http.StatusForbidden},
+		{name: "RouteMonitors", url: "/monitors?addrs=0x1&delete", header: "Authorization", value: "Bearer admin", expected: http.StatusOK},
+		{name: "RouteScrape", url: "/scrape", header: "Authorization", value: "Bearer " + helperJwt(`{"scope":"read"}`, testSecret), expected: http.StatusForbidden},
+		{name: "RouteScrape", url: "/scrape", header: "Authorization", value: "Bearer " + helperJwt(`{"scope":"admin"}`, testSecret), expected: http.StatusOK},
+		{name: "CreateName", url: "/names", header: "X-API-Key", value: "admin", expected: http.StatusOK},
+		{name: "RouteWhen", url: "/when?timestamps&check", header: "X-API-Key", value: "reader", expected: http.StatusOK},
+		{name: "RouteWhen", url: "/when?timestamps&truncate=100", header: "X-API-Key", value: "reader", expected: http.StatusForbidden},
+		{name: "RouteWhen", url: "/when?timestamps&repair&blocks=100", header: "X-API-Key", value: "reader", expected: http.StatusForbidden},
+		{name: "RouteWhen", url: "/when?timestamps&update", header: "X-API-Key", value: "reader", expected: http.StatusForbidden},
+		{name: "RouteWhen", url: "/when?timestamps&update", header: "X-API-Key", value: "admin", expected: http.StatusOK},
+		{name: "RouteConfig", url: "/config?mode=show", header: "X-API-Key", value: "reader", expected: http.StatusOK},
+		{name: "RouteConfig", url: "/config?mode=edit", header: "X-API-Key", value: "reader", expected: http.StatusForbidden},
+		{name: "RouteConfig", url: "/config?mode=Migrate", header: "X-API-Key", value: "reader", expected: http.StatusForbidden},
+		{name: "RouteConfig", url: "/config?mode=migrate", header: "X-API-Key", value: "admin", expected: http.StatusOK},
+		{name: "Websockets", url: "/websocket?token=reader", expected: http.StatusUnauthorized},
+		{name: "Websockets", url: "/websocket?token=reader", header: "Upgrade", value: "websocket", expected: http.StatusOK},
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for i, tt := range tests {
+		r := httptest.NewRequest("GET", tt.url, nil)
+		if len(tt.header) > 0 {
+			r.Header.Set(tt.header, tt.value)
+		}
+		w := httptest.NewRecorder()
+		Authorizer(ok, tt.name, auth).ServeHTTP(w, r)
+		if w.Code != tt.expected {
+			t.Error(i, tt.name, tt.url, "expected", tt.expected, "got", w.Code, w.Body.String())
+		}
+	}
+
+	// Without credentials configured, everything is allowed
+	w := httptest.NewRecorder()
+	Authorizer(ok, "RouteScrape", &Authenticator{}).ServeHTTP(w, httptest.NewRequest("GET", "/scrape", nil))
+	if w.Code != http.StatusOK {
+		t.Error("expected the daemon to be open without credentials, got", w.Code)
+	}
+}
@@ -129,8 +129,8 @@ func (pool *ConnectionPool) run() {
 
 // HandleWebsockets handles web sockets
 func HandleWebsockets(pool *ConnectionPool, w http.ResponseWriter, r *http.Request) {
-	// TODO: the line below allows any connection through WebSockets. Once the server
-	// TODO: is ready, we should implement some rules here
+	// We accept connections from any origin. If the daemon requires credentials, the Authorizer has
+	// already checked them (browsers send them in the `token` query parameter) before we get here.
 	upgrader.CheckOrigin = func(r *http.Request) bool { return true }
 
 	c, err := upgrader.Upgrade(w, r, nil)
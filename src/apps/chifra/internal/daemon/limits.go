@@ -0,0 +1,202 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package daemonPkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"golang.org/x/time/rate"
+)
+
+// heavyRoutes are the routes whose requests may run for a long time or touch much of the index or
+// the chain. They are limited separately (and more strictly) than the rest of the routes.
+var heavyRoutes = map[string]bool{
+	"RouteExport": true,
+	"RouteList":   true,
+	"RouteTraces": true,
+	"RouteSlurp":  true,
+	"RouteChunks": true,
+	"RouteScrape": true,
+	"RouteInit":   true,
+}
+
+// idleClientTimeout is how long a client's state is kept after its last request
+const idleClientTimeout = 10 * time.Minute
+
+// RateLimiter limits the requests each client makes to the daemon with a token bucket (and, if
+// configured, a cap on concurrent requests) per route class. Clients are identified by their API
+// key or bearer token, if the Authenticator accepts it, and otherwise by their IP address.
+type RateLimiter struct {
+	light     config.RouteLimits
+	heavy     config.RouteLimits
+	auth      *Authenticator
+	now       func() time.Time
+	mutex     sync.Mutex
+	clients   map[string]*clientLimits
+	lastSweep time.Time
+}
+
+// clientLimits is the state of one client's use of one class of routes
+type clientLimits struct {
+	bucket   *rate.Limiter
+	running  int
+	lastSeen time.Time
+}
+
+// NewRateLimiter returns a RateLimiter with the limits in the `[daemon]` config group
+func NewRateLimiter(auth *Authenticator) *RateLimiter {
+	light, heavy := config.GetDaemonLimits()
+	return newRateLimiter(light, heavy, auth, time.Now)
+}
+
+func newRateLimiter(light, heavy config.RouteLimits, auth *Authenticator, now func() time.Time) *RateLimiter {
+	return &RateLimiter{
+		light:   light,
+		heavy:   heavy,
+		auth:    auth,
+		now:     now,
+		clients: map[string]*clientLimits{},
+	}
+}
+
+// String describes the limits for display when the daemon starts
+func (l *RateLimiter) String() string {
+	describe := func(limits config.RouteLimits) string {
+		ret := "no limit"
+		if limits.Rate > 0 {
+			ret = fmt.Sprintf("%g/s (burst %d)", limits.Rate, limits.Burst)
+		}
+		if limits.Concurrency > 0 {
+			ret += fmt.Sprintf(", %d at once", limits.Concurrency)
+		}
+		return ret
+	}
+	return fmt.Sprintf("light %s, heavy %s per client", describe(l.light), describe(l.heavy))
+}
+
+// Limit rejects, with 429 Too Many Requests and a Retry-After header, requests to the named route
+// from clients that have exceeded the limits of the route's class
+func (l *RateLimiter) Limit(inner http.Handler, name string) http.Handler {
+	class, limits := "light", l.light
+	if heavyRoutes[name] {
+		class, limits = "heavy", l.heavy
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limits.Rate <= 0 && limits.Concurrency <= 0 {
+			inner.ServeHTTP(w, r)
+			return
+		}
+
+		key := class + " " + l.clientId(r)
+		retryAfter, err := l.acquire(key, limits)
+		if err != nil {
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			RespondWithError(w, http.StatusTooManyRequests, err)
+			return
+		}
+		defer l.release(key)
+
+		inner.ServeHTTP(w, r)
+	})
+}
+
+// clientId identifies the client that sent the request
+func (l *RateLimiter) clientId(r *http.Request) string {
+	if l.auth.Enabled() {
+		if _, err := l.auth.Authorize(r); err == nil {
+			// Don't hold on to the credentials themselves
+			sum := sha256.Sum256([]byte(credentials(r)))
+			return "key " + hex.EncodeToString(sum[:8])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip " + host
+}
+
+// acquire takes a token from the client's bucket and a place among its running requests. If it
+// can't, it returns the number of seconds after which the client should try again.
+func (l *RateLimiter) acquire(key string, limits config.RouteLimits) (int, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	client := l.clients[key]
+	if client == nil {
+		client = &clientLimits{bucket: newBucket(limits)}
+		l.clients[key] = client
+	}
+	client.lastSeen = now
+
+	if limits.Concurrency > 0 && client.running >= limits.Concurrency {
+		// We can't know when a running request will finish, so we suggest the shortest wait
+		return 1, errors.New("too many concurrent requests")
+	}
+
+	reservation := client.bucket.ReserveN(now, 1)
+	if !reservation.OK() {
+		return 1, errors.New("too many requests")
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return int(math.Ceil(delay.Seconds())), errors.New("too many requests")
+	}
+
+	client.running++
+	return 0, nil
+}
+
+// release frees the client's place among its running requests
+func (l *RateLimiter) release(key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if client := l.clients[key]; client != nil && client.running > 0 {
+		client.running--
+	}
+}
+
+// sweep forgets clients that have been idle for a while (at most once a minute). Unless the rate is
+// very low, their buckets have refilled by then, so forgetting them changes nothing.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	for key, client := range l.clients {
+		if client.running == 0 && now.Sub(client.lastSeen) > idleClientTimeout {
+			delete(l.clients, key)
+		}
+	}
+}
+
+// newBucket returns the token bucket for the limits. A zero rate does not limit the request rate.
+func newBucket(limits config.RouteLimits) *rate.Limiter {
+	if limits.Rate <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	burst := limits.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(limits.Rate), burst)
+}
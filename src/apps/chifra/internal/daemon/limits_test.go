@@ -0,0 +1,109 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package daemonPkg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+)
+
+// helperRequest sends a request from the client (and with the API key, if any) and returns the response
+func helperRequest(handler http.Handler, remoteAddr, apiKey string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", "/blocks?blocks=1", nil)
+	r.RemoteAddr = remoteAddr
+	if len(apiKey) > 0 {
+		r.Header.Set("X-API-Key", apiKey)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestRateLimiter_Rate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	light := config.RouteLimits{Rate: 0.5, Burst: 2}
+	limiter := newRateLimiter(light, config.RouteLimits{}, &Authenticator{}, func() time.Time { return now })
+	handler := limiter.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "RouteBlocks")
+
+	// The burst is allowed, the next request must wait two seconds for a token...
+	for i := 0; i < 2; i++ {
+		if w := helperRequest(handler, "10.0.0.1:5000", ""); w.Code != http.StatusOK {
+			t.Fatal("request", i, "should have been allowed", w.Code)
+		}
+	}
+	w := helperRequest(handler, "10.0.0.1:5001", "")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "2" {
+		t.Fatal("expected to be told to retry after 2 seconds", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	// ...other clients have their own buckets...
+	if w := helperRequest(handler, "10.0.0.2:5000", ""); w.Code != http.StatusOK {
+		t.Error("another client should have been allowed", w.Code)
+	}
+
+	// ...and rejected requests don't use up tokens
+	now = now.Add(2 * time.Second)
+	if w := helperRequest(handler, "10.0.0.1:5000", ""); w.Code != http.StatusOK {
+		t.Error("request should have been allowed after waiting", w.Code)
+	}
+}
+
+func TestRateLimiter_Concurrency(t *testing.T) {
+	heavy := config.RouteLimits{Concurrency: 1}
+	limiter := newRateLimiter(config.RouteLimits{}, heavy, &Authenticator{}, time.Now)
+
+	var inner http.Handler
+	handler := limiter.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inner.ServeHTTP(w, r)
+	}), "RouteExport")
+
+	// While a client's export runs, its second export is rejected, but another client's is not
+	var nested, other *httptest.ResponseRecorder
+	inner = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inner = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+		nested = helperRequest(handler, "10.0.0.1:6000", "")
+		other = helperRequest(handler, "10.0.0.2:6000", "")
+	})
+	if w := helperRequest(handler, "10.0.0.1:5000", ""); w.Code != http.StatusOK {
+		t.Fatal("first request should have been allowed", w.Code)
+	}
+	if nested.Code != http.StatusTooManyRequests || nested.Header().Get("Retry-After") != "1" {
+		t.Error("concurrent request should have been rejected", nested.Code, nested.Header().Get("Retry-After"))
+	}
+	if other.Code != http.StatusOK {
+		t.Error("another client's request should have been allowed", other.Code)
+	}
+
+	// Once it's finished, the client may export again
+	if w := helperRequest(handler, "10.0.0.1:5000", ""); w.Code != http.StatusOK {
+		t.Error("request should have been allowed after the first finished", w.Code)
+	}
+}
+
+func TestRateLimiter_ClientId(t *testing.T) {
+	limiter := newRateLimiter(config.RouteLimits{}, config.RouteLimits{}, &Authenticator{readKey: "reader"}, time.Now)
+
+	ids := map[string]bool{}
+	for _, test := range []struct{ addr, key string }{
+		{"10.0.0.1:5000", "reader"},
+		{"10.0.0.2:5000", "reader"},
+		{"10.0.0.1:5000", "invalid"},
+		{"10.0.0.1:5001", ""},
+	} {
+		r := httptest.NewRequest("GET", "/blocks", nil)
+		r.RemoteAddr = test.addr
+		r.Header.Set("X-API-Key", test.key)
+		ids[limiter.clientId(r)] = true
+	}
+
+	// Valid keys identify the client wherever it connects from, invalid ones are ignored
+	if len(ids) != 2 {
+		t.Error("expected one client per valid key or address, got", ids)
+	}
+}
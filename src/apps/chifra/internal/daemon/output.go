@@ -79,6 +79,15 @@ func (opts *DaemonOptions) DaemonInternal() (err error, handled bool) {
 	logger.InfoTable("Cache Path:        ", config.PathToCache(chain))
 	logger.InfoTable("Index Path:        ", config.PathToIndex(chain))
 
+	auth := NewAuthenticator()
+	if auth.Enabled() {
+		logger.InfoTable("Authentication:    ", strings.Join(auth.Methods(), ", "))
+	} else {
+		logger.InfoTable("Authentication:    ", "off (configure [keys.daemon] to require credentials)")
+	}
+	limiter := NewRateLimiter(auth)
+	logger.InfoTable("Rate Limits:       ", limiter.String())
+
 	meta, err := opts.Conn.GetMetaData(false)
 	if err != nil {
 		msg := fmt.Sprintf("%sCould not load RPC provider: %s%s", colors.Red, err, colors.Off)
@@ -106,7 +115,7 @@ func (opts *DaemonOptions) DaemonInternal() (err error, handled bool) {
 	// Start listening to the web sockets
 	RunWebsocketPool()
 	// Start listening for requests
-	logger.Fatal(http.ListenAndServe(opts.Port, NewRouter()))
+	logger.Fatal(http.ListenAndServe(opts.Port, NewRouter(auth, limiter)))
 
 	// EXISTING_CODE
 	timer.Report(msg)
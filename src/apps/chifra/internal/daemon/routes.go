@@ -40,7 +40,6 @@ import (
 	// END_ROUTE_PKGS
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/gorilla/mux"
-	"golang.org/x/time/rate"
 )
 
 // BEG_ROUTE_CODE
@@ -272,8 +271,10 @@ type Route struct {
 // Routes An array of Route structures
 type Routes []Route
 
-// NewRouter Creates a new router given the routes array
-func NewRouter() *mux.Router {
+// NewRouter Creates a new router given the routes array. Each route requires the scope (see
+// RequiredScope) the authenticator's credentials grant, if any are configured, and each client's
+// requests are limited by the rate limiter.
+func NewRouter(auth *Authenticator, limiter *RateLimiter) *mux.Router {
 	router := mux.NewRouter().StrictSlash(true)
 	router.Use(CorsHandler)
 	router.
@@ -284,6 +285,8 @@ func NewRouter() *mux.Router {
 	for _, route := range routes {
 		var handler http.Handler
 		handler = route.HandlerFunc
+		handler = Authorizer(handler, route.Name, auth)
+		handler = limiter.Limit(handler, route.Name)
 		handler = Logger(handler, route.Name)
 		router.
 			Methods(route.Method).
@@ -297,8 +300,9 @@ func NewRouter() *mux.Router {
 
 func addCorsHeaders(w http.ResponseWriter) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept")
+	w.Header().Set("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization, X-API-Key")
 	w.Header().Set("Access-Control-Allow-Methods", "PUT, POST, GET, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Expose-Headers", "Retry-After")
 }
 
 var OptionsHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -338,11 +342,6 @@ var nProcessed int
 // Logger sends information to the server's console
 func Logger(inner http.Handler, name string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var limiter = rate.NewLimiter(1, 3)
-		if !limiter.Allow() {
-			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
-			return
-		}
 		start := time.Now()
 		inner.ServeHTTP(w, r)
 		t := ""
@@ -0,0 +1,69 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package exportPkg
+
+import (
+	"context"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/articulate"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/ledger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func (opts *ExportOptions) HandleGains(monitorArray []monitor.Monitor) error {
+	chain := opts.Globals.Chain
+	testMode := opts.Globals.TestMode
+	abiCache := articulate.NewAbiCache(chain, opts.Articulate)
+	filter := filter.NewFilter(
+		opts.Reversed,
+		base.BlockRange{First: opts.FirstBlock, Last: opts.LastBlock},
+		base.RecordRange{First: opts.FirstRecord, Last: opts.GetMax()},
+	)
+
+	method := opts.CostBasis
+	if len(method) == 0 {
+		method = "fifo"
+	}
+	period := opts.Period
+	if len(period) == 0 {
+		period = "year"
+	}
+
+	// Every period through the end of the requested range is reported
+	last := opts.LastBlock
+	if latest := opts.Conn.GetLatestBlockNumber(); last > latest {
+		last = latest
+	}
+	through := opts.Conn.GetBlockTimestamp(last)
+
+	ctx := context.Background()
+	fetchData := func(modelChan chan types.Modeler[types.RawGain], errorChan chan error) {
+		if opts.entity != nil {
+			statements := opts.readEntityStatements(monitorArray, filter, errorChan, abiCache)
+			for _, gain := range ledger.GetGainsFromStatements(opts.Conn, testMode, opts.entity.Members[0], statements, method, period, through) {
+				gain := gain
+				modelChan <- gain
+			}
+			return
+		}
+
+		for _, mon := range monitorArray {
+			if statements, err := opts.readStatements(monitorArray, &mon, filter, errorChan, abiCache); err != nil {
+				errorChan <- err
+			} else {
+				for _, gain := range ledger.GetGainsFromStatements(opts.Conn, testMode, mon.Address, statements, method, period, through) {
+					gain := gain
+					modelChan <- gain
+				}
+			}
+		}
+	}
+
+	return output.StreamMany(ctx, fetchData, opts.Globals.OutputOpts())
+}
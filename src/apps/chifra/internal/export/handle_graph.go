@@ -0,0 +1,111 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package exportPkg
+
+import (
+	"math"
+
+	listPkg "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/list"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/graph"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/names"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// maxHopAddresses is the largest number of addresses found at a hop whose monitors are freshened
+// and explored in the next hop. If more are found, those with the most interactions are explored.
+const maxHopAddresses = 100
+
+// HandleGraph builds a graph of the value flows and calls in the transactions of the given
+// addresses and then, for each further hop, in the transactions of the addresses found in the
+// previous hop. The graph is written in the format given by --graph.
+func (opts *ExportOptions) HandleGraph(monitorArray []monitor.Monitor) error {
+	chain := opts.Globals.Chain
+	filter := filter.NewFilter(
+		opts.Reversed,
+		base.BlockRange{First: opts.FirstBlock, Last: opts.LastBlock},
+		base.RecordRange{First: opts.FirstRecord, Last: opts.GetMax()},
+	)
+
+	seeds := make([]base.Address, 0, len(monitorArray))
+	for _, mon := range monitorArray {
+		seeds = append(seeds, mon.Address)
+	}
+	g := graph.NewGraph(seeds)
+
+	monitors := monitorArray
+	for hop := uint64(0); hop < opts.Hops; hop++ {
+		if hop > 0 {
+			addrs := []string{}
+			for _, addr := range g.AddressesAt(hop, maxHopAddresses) {
+				addrs = append(addrs, addr.Hex())
+			}
+			if len(addrs) == 0 {
+				break
+			}
+			if found := len(g.AddressesAt(hop, math.MaxInt)); found > len(addrs) {
+				logger.Warn("Exploring the", len(addrs), "most active of the", found, "addresses found at hop", hop)
+			}
+
+			monitors = make([]monitor.Monitor, 0, len(addrs))
+			listOpts := listPkg.ListOptions{
+				Addrs:   addrs,
+				Silent:  true,
+				Globals: opts.Globals,
+			}
+			if canceled, err := listOpts.HandleFreshenMonitors(&monitors); err != nil || canceled {
+				return err
+			}
+		}
+
+		for _, mon := range monitors {
+			apps, _, err := mon.ReadAndFilterAppearances(filter)
+			if err != nil {
+				return err
+			}
+
+			bar := logger.NewBar(logger.BarOptions{
+				Prefix:  mon.Address.Hex(),
+				Enabled: !opts.Globals.TestMode && len(opts.Globals.File) == 0,
+				Total:   int64(len(apps)),
+			})
+			for _, app := range apps {
+				if app.BlockNumber == 0 || app.TransactionIndex >= 99996 {
+					// Prefunds and rewards are not interactions
+					continue
+				}
+				if g.HasTransaction(base.Blknum(app.BlockNumber), base.Txnum(app.TransactionIndex)) {
+					// Already found while exploring another address
+					bar.Tick()
+					continue
+				}
+				raw := types.RawAppearance{
+					Address:          app.Address.Hex(),
+					BlockNumber:      app.BlockNumber,
+					TransactionIndex: app.TransactionIndex,
+				}
+				tx, err := opts.Conn.GetTransactionByAppearance(&raw, true /* fetchTraces */)
+				if err != nil {
+					return err
+				}
+				g.AddTransaction(tx, hop)
+				bar.Tick()
+			}
+			bar.Finish(true)
+		}
+	}
+
+	parts := names.Custom | names.Prefund | names.Regular
+	if namesMap, err := names.LoadNamesMap(chain, parts, nil); err != nil {
+		return err
+	} else {
+		g.SetNames(namesMap)
+	}
+
+	return g.Write(opts.Globals.Writer, opts.Graph)
+}
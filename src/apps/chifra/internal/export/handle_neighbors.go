@@ -8,14 +8,15 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"sync"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
-	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/uniq"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
 )
 
@@ -38,12 +39,16 @@ func (opts *ExportOptions) HandleNeighbors(monitorArray []monitor.Monitor) error
 					Enabled: !opts.Globals.TestMode && len(opts.Globals.File) == 0,
 					Total:   mon.Count(),
 				})
-				allNeighbors := make([]index.Reason, 0)
+				var mutex sync.Mutex
+				allNeighbors := make([]types.SimpleAppearance, 0)
 				iterFunc := func(app types.SimpleAppearance, unused *bool) error {
-					if neighbors, err := index.GetNeighbors(&app); err != nil {
+					if neighbors, err := uniq.GetNeighbors(opts.Conn, &app); err != nil {
 						return err
 					} else {
+						mutex.Lock()
 						allNeighbors = append(allNeighbors, neighbors...)
+						mutex.Unlock()
+						bar.Tick()
 						return nil
 					}
 				}
@@ -60,16 +65,7 @@ func (opts *ExportOptions) HandleNeighbors(monitorArray []monitor.Monitor) error
 				}
 
 				// Sort the items back into an ordered array by block number
-				items := make([]types.SimpleAppearance, 0, len(neighborMap))
-				for _, neighbor := range allNeighbors {
-					app := types.SimpleAppearance{
-						Address:          *neighbor.Address,
-						BlockNumber:      neighbor.App.BlockNumber,
-						TransactionIndex: neighbor.App.TransactionIndex,
-						Reason:           neighbor.Reason,
-					}
-					items = append(items, app)
-				}
+				items := allNeighbors
 				sort.Slice(items, func(i, j int) bool {
 					if opts.Reversed {
 						i, j = j, i
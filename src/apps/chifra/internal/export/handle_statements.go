@@ -28,6 +28,14 @@ func (opts *ExportOptions) HandleStatements(monitorArray []monitor.Monitor) erro
 
 	ctx := context.Background()
 	fetchData := func(modelChan chan types.Modeler[types.RawStatement], errorChan chan error) {
+		if opts.entity != nil {
+			for _, statement := range opts.readEntityStatements(monitorArray, filter, errorChan, abiCache) {
+				statement := statement
+				modelChan <- statement
+			}
+			return
+		}
+
 		for _, mon := range monitorArray {
 			if statements, err := opts.readStatements(monitorArray, &mon, filter, errorChan, abiCache); err != nil {
 				errorChan <- err
@@ -116,3 +116,29 @@ func (opts *ExportOptions) readStatements(
 	// Return the array of items
 	return items, nil
 }
+
+// readEntityStatements reads the statements for each member of the entity and consolidates
+// them into statements for the entity as a whole
+func (opts *ExportOptions) readEntityStatements(
+	monitorArray []monitor.Monitor,
+	filter *filter.AppearanceFilter,
+	errorChan chan error,
+	abiCache *articulate.AbiCache,
+) []*types.SimpleStatement {
+	all := make([]*types.SimpleStatement, 0)
+	for _, mon := range monitorArray {
+		if statements, err := opts.readStatements(monitorArray, &mon, filter, errorChan, abiCache); err != nil {
+			errorChan <- err
+		} else {
+			all = append(all, statements...)
+		}
+	}
+
+	items := opts.entity.Consolidate(opts.Conn, all)
+	if opts.Reversed {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	return items
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/globals"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/caps"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/ledger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
@@ -30,6 +31,8 @@ type ExportOptions struct {
 	Logs        bool                  `json:"logs,omitempty"`        // Export logs instead of transactional data
 	Traces      bool                  `json:"traces,omitempty"`      // Export traces instead of transactional data
 	Neighbors   bool                  `json:"neighbors,omitempty"`   // Export the neighbors of the given address
+	Graph       string                `json:"graph,omitempty"`       // Export a weighted, directed graph of value flows and calls among the given addresses in this format
+	Hops        uint64                `json:"hops,omitempty"`        // For the --graph option only, the number of hops to follow from the given addresses
 	Accounting  bool                  `json:"accounting,omitempty"`  // Attach accounting records to the exported data (applies to transactions export only)
 	Statements  bool                  `json:"statements,omitempty"`  // For the accounting options only, export only statements
 	Balances    bool                  `json:"balances,omitempty"`    // Traverse the transaction history and show each change in ETH balances
@@ -43,6 +46,10 @@ type ExportOptions struct {
 	Topic       []string              `json:"topic,omitempty"`       // For log export only, export only logs with this topic(s)
 	Asset       []string              `json:"asset,omitempty"`       // For the accounting options only, export statements only for this asset
 	Flow        string                `json:"flow,omitempty"`        // For the accounting options only, export statements with incoming, outgoing, or zero value
+	Gains       bool                  `json:"gains,omitempty"`       // For the accounting options only, export realized and unrealized gains per asset per period
+	CostBasis   string                `json:"costBasis,omitempty"`   // For the --gains option only, the order in which lots are relieved (fifo if not specified)
+	Period      string                `json:"period,omitempty"`      // For the --gains option only, the period over which gains are summarized (year if not specified)
+	Entity      string                `json:"entity,omitempty"`      // For the accounting options only, reconcile the addresses in this file (or with this names tag) as a single entity
 	Factory     bool                  `json:"factory,omitempty"`     // For --traces only, report addresses created by (or self-destructed by) the given address(es)
 	Unripe      bool                  `json:"unripe,omitempty"`      // Export transactions labeled upripe (i.e. less than 28 blocks old)
 	Load        string                `json:"load,omitempty"`        // A comma separated list of dynamic traversers to load
@@ -54,10 +61,12 @@ type ExportOptions struct {
 	Conn        *rpc.Connection       `json:"conn,omitempty"`        // The connection to the RPC server
 	BadFlag     error                 `json:"badFlag,omitempty"`     // An error flag if needed
 	// EXISTING_CODE
+	entity *ledger.Entity
 	// EXISTING_CODE
 }
 
 var defaultExportOptions = ExportOptions{
+	Hops:       1,
 	MaxRecords: 250,
 	LastBlock:  utils.NOPOS,
 }
@@ -72,6 +81,8 @@ func (opts *ExportOptions) testLog() {
 	logger.TestLog(opts.Logs, "Logs: ", opts.Logs)
 	logger.TestLog(opts.Traces, "Traces: ", opts.Traces)
 	logger.TestLog(opts.Neighbors, "Neighbors: ", opts.Neighbors)
+	logger.TestLog(len(opts.Graph) > 0, "Graph: ", opts.Graph)
+	logger.TestLog(opts.Hops != 1, "Hops: ", opts.Hops)
 	logger.TestLog(opts.Accounting, "Accounting: ", opts.Accounting)
 	logger.TestLog(opts.Statements, "Statements: ", opts.Statements)
 	logger.TestLog(opts.Balances, "Balances: ", opts.Balances)
@@ -85,6 +96,10 @@ func (opts *ExportOptions) testLog() {
 	logger.TestLog(len(opts.Topic) > 0, "Topic: ", opts.Topic)
 	logger.TestLog(len(opts.Asset) > 0, "Asset: ", opts.Asset)
 	logger.TestLog(len(opts.Flow) > 0, "Flow: ", opts.Flow)
+	logger.TestLog(opts.Gains, "Gains: ", opts.Gains)
+	logger.TestLog(len(opts.CostBasis) > 0, "CostBasis: ", opts.CostBasis)
+	logger.TestLog(len(opts.Period) > 0, "Period: ", opts.Period)
+	logger.TestLog(len(opts.Entity) > 0, "Entity: ", opts.Entity)
 	logger.TestLog(opts.Factory, "Factory: ", opts.Factory)
 	logger.TestLog(opts.Unripe, "Unripe: ", opts.Unripe)
 	logger.TestLog(len(opts.Load) > 0, "Load: ", opts.Load)
@@ -106,6 +121,7 @@ func (opts *ExportOptions) String() string {
 func exportFinishParseApi(w http.ResponseWriter, r *http.Request) *ExportOptions {
 	copy := defaultExportOptions
 	opts := &copy
+	opts.Hops = 1
 	opts.FirstRecord = 0
 	opts.MaxRecords = 250
 	opts.FirstBlock = 0
@@ -137,6 +153,10 @@ func exportFinishParseApi(w http.ResponseWriter, r *http.Request) *ExportOptions
 			opts.Traces = true
 		case "neighbors":
 			opts.Neighbors = true
+		case "graph":
+			opts.Graph = value[0]
+		case "hops":
+			opts.Hops = globals.ToUint64(value[0])
 		case "accounting":
 			opts.Accounting = true
 		case "statements":
@@ -172,6 +192,14 @@ func exportFinishParseApi(w http.ResponseWriter, r *http.Request) *ExportOptions
 			}
 		case "flow":
 			opts.Flow = value[0]
+		case "gains":
+			opts.Gains = true
+		case "costBasis":
+			opts.CostBasis = value[0]
+		case "period":
+			opts.Period = value[0]
+		case "entity":
+			opts.Entity = value[0]
 		case "factory":
 			opts.Factory = true
 		case "unripe":
@@ -195,6 +223,10 @@ func exportFinishParseApi(w http.ResponseWriter, r *http.Request) *ExportOptions
 	opts.Conn = opts.Globals.FinishParseApi(w, r, opts.getCaches())
 
 	// EXISTING_CODE
+	if len(opts.Graph) > 0 {
+		// The graph is written in its own format
+		opts.Globals.Format = "txt"
+	}
 	// EXISTING_CODE
 	opts.Addrs, _ = opts.Conn.GetEnsAddresses(opts.Addrs)
 	opts.Emitter, _ = opts.Conn.GetEnsAddresses(opts.Emitter)
@@ -232,6 +264,10 @@ func exportFinishParse(args []string) *ExportOptions {
 			opts.Addrs = append(opts.Addrs, arg)
 		}
 	}
+	if len(opts.Graph) > 0 {
+		// The graph is written in its own format
+		opts.Globals.Format = "txt"
+	}
 	// EXISTING_CODE
 	opts.Addrs, _ = opts.Conn.GetEnsAddresses(opts.Addrs)
 	opts.Emitter, _ = opts.Conn.GetEnsAddresses(opts.Emitter)
@@ -78,10 +78,14 @@ func (opts *ExportOptions) ExportInternal() (err error, handled bool) {
 		err = opts.HandleAppearances(monitorArray)
 	} else if opts.Statements {
 		err = opts.HandleStatements(monitorArray)
+	} else if opts.Gains {
+		err = opts.HandleGains(monitorArray)
 	} else if opts.Balances {
 		err = opts.HandleBalances(monitorArray)
 	} else if opts.Neighbors {
 		err = opts.HandleNeighbors(monitorArray)
+	} else if len(opts.Graph) > 0 {
+		err = opts.HandleGraph(monitorArray)
 	} else if opts.Accounting {
 		err = opts.HandleAccounting(monitorArray)
 	} else {
@@ -11,6 +11,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/ledger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/validate"
@@ -51,6 +52,41 @@ func (opts *ExportOptions) validateExport() error {
 		}
 	}
 
+	if len(opts.Graph) > 0 {
+		if err := validate.ValidateEnum("--graph", opts.Graph, "[graphml|dot|edges]"); err != nil {
+			return err
+		}
+		if opts.Count {
+			return validate.Usage("The {0} option is not available{1}.", "--count", " with --graph")
+		}
+		if opts.Hops == 0 {
+			return validate.Usage("The {0} option must be at least one.", "--hops")
+		}
+	} else if opts.Hops != 1 {
+		return validate.Usage("The {0} option is only available with the {1} option.", "--hops", "--graph")
+	}
+
+	if len(opts.Entity) > 0 {
+		if !opts.Accounting {
+			return validate.Usage("The {0} option is only available with the {1} option.", "--entity", "--accounting")
+		}
+		if !opts.Statements && !opts.Gains {
+			return validate.Usage("The {0} option is only available with the {1} option.", "--entity", "--statements or --gains")
+		}
+		if opts.entity == nil {
+			entity, err := ledger.LoadEntity(chain, opts.Entity, opts.Globals.IsApiMode())
+			if err != nil {
+				return validate.Usage("Could not load entity {0}: {1}", opts.Entity, err.Error())
+			}
+			opts.entity = entity
+			for _, member := range entity.Members {
+				if !opts.hasAddr(member) {
+					opts.Addrs = append(opts.Addrs, member.Hex())
+				}
+			}
+		}
+	}
+
 	if len(opts.Globals.File) == 0 {
 		if err := validate.ValidateAtLeastOneAddr(opts.Addrs); err != nil {
 			return err
@@ -138,7 +174,7 @@ func (opts *ExportOptions) validateExport() error {
 	}
 
 	if opts.Accounting {
-		if len(opts.Addrs) != 1 {
+		if len(opts.Addrs) != 1 && opts.entity == nil {
 			return validate.Usage("The {0} option is allows with only a single address.", "--accounting")
 		}
 
@@ -159,6 +195,30 @@ func (opts *ExportOptions) validateExport() error {
 			}
 		}
 
+		if opts.Gains {
+			if opts.Statements {
+				return validate.Usage("Please choose only one of {0}.", "--statements or --gains")
+			}
+			if len(opts.CostBasis) > 0 {
+				if err := validate.ValidateEnum("--cost_basis", opts.CostBasis, "[fifo|lifo|hifo]"); err != nil {
+					return err
+				}
+			}
+			if len(opts.Period) > 0 {
+				if err := validate.ValidateEnum("--period", opts.Period, "[year|quarter|month]"); err != nil {
+					return err
+				}
+			}
+
+		} else {
+			if len(opts.CostBasis) > 0 {
+				return validate.Usage("The {0} option is only available with the {1} option.", "--cost_basis", "--gains")
+			}
+			if len(opts.Period) > 0 {
+				return validate.Usage("The {0} option is only available with the {1} option.", "--period", "--gains")
+			}
+		}
+
 		if !opts.Conn.IsNodeArchive() {
 			return validate.Usage("The {0} option requires {1}.", "--accounting", "an archive node")
 		}
@@ -168,6 +228,10 @@ func (opts *ExportOptions) validateExport() error {
 			return validate.Usage("The {0} option is only available with the {1} option.", "--statements", "--accounting")
 		}
 
+		if opts.Gains || len(opts.CostBasis) > 0 || len(opts.Period) > 0 {
+			return validate.Usage("The {0} options are only available with the {1} option.", "--gains, --cost_basis, and --period", "--accounting")
+		}
+
 		if opts.Globals.Format == "ofx" {
 			return validate.Usage("The {0} option is only available with the {1} option.", "--fmt ofx", "--accounting")
 		}
@@ -215,8 +279,21 @@ func (opts *ExportOptions) TooManyOptions() bool {
 	if opts.Neighbors {
 		cnt++
 	}
+	if len(opts.Graph) > 0 {
+		cnt++
+	}
 	if opts.Accounting {
 		cnt++
 	}
 	return cnt > 1
 }
+
+// hasAddr returns true if the address is already one of the addresses being exported
+func (opts *ExportOptions) hasAddr(addr base.Address) bool {
+	for _, a := range opts.Addrs {
+		if base.HexToAddress(a) == addr {
+			return true
+		}
+	}
+	return false
+}
@@ -87,7 +87,7 @@ func InitGlobals(cmd *cobra.Command, opts *GlobalOptions, c caps.Capability) {
 	}
 
 	if opts.Caps.Has(caps.Fmt) {
-		cmd.Flags().StringVarP(&opts.Format, "fmt", "x", "", "export format, one of [none|json*|txt|csv]")
+		cmd.Flags().StringVarP(&opts.Format, "fmt", "x", "", "export format, one of [none|json*|txt|csv|parquet]")
 	}
 
 	if opts.Caps.Has(caps.Verbose) {
@@ -127,7 +127,7 @@ func InitGlobals(cmd *cobra.Command, opts *GlobalOptions, c caps.Capability) {
 	_ = cmd.Flags().MarkHidden("file")
 
 	if opts.Caps.Has(caps.Output) {
-		cmd.Flags().StringVarP(&opts.OutputFn, "output", "", "", "redirect results from stdout to the given file, create if not present")
+		cmd.Flags().StringVarP(&opts.OutputFn, "output", "", "", "redirect results from stdout to the given file (or sqlite:// or postgres:// database), create if not present")
 	}
 	_ = cmd.Flags().MarkHidden("output")
 
@@ -184,7 +184,7 @@ func (opts *GlobalOptions) FinishParseApi(w http.ResponseWriter, r *http.Request
 			parts := strings.Split(opts.OutputFn, ".")
 			if len(parts) > 0 {
 				last := parts[len(parts)-1]
-				if last == "txt" || last == "csv" || last == "json" {
+				if last == "txt" || last == "csv" || last == "json" || last == "parquet" {
 					opts.Format = last
 				}
 			}
@@ -207,10 +207,14 @@ func (opts *GlobalOptions) FinishParse(args []string, caches map[string]bool) *r
 		parts := strings.Split(opts.OutputFn, ".")
 		if len(parts) > 0 {
 			last := parts[len(parts)-1]
-			if last == "txt" || last == "csv" || last == "json" {
+			if last == "txt" || last == "csv" || last == "json" || last == "parquet" {
 				opts.Format = last
 			}
 		}
+		if output.IsDatabaseDsn(opts.OutputFn) {
+			// Databases are written from the same (flat) models as csv
+			opts.Format = "csv"
+		}
 	}
 
 	if len(opts.Chain) == 0 {
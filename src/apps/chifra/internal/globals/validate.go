@@ -9,6 +9,7 @@ import (
 	"strconv"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/validate"
 )
 
@@ -40,11 +41,23 @@ func (opts *GlobalOptions) Validate() error {
 	// 	}
 	// }
 
-	err := validate.ValidateEnum("--fmt", opts.Format, "[json|txt|csv]")
+	err := validate.ValidateEnum("--fmt", opts.Format, "[json|txt|csv|parquet]")
 	if err != nil {
 		return err
 	}
 
+	if opts.Format == "parquet" && opts.IsApiMode() {
+		return validate.Usage("The {0} option is not available{1}.", "--fmt parquet", " in api mode")
+	}
+
+	if output.IsDatabaseDsn(opts.OutputFn) {
+		if opts.ShowRaw {
+			return validate.Usage("The {0} option is not available{1}.", "--raw", " when --output is a database")
+		} else if opts.Format == "json" || opts.Format == "parquet" {
+			return validate.Usage("The {0} option is not available{1}.", "--fmt "+opts.Format, " when --output is a database")
+		}
+	}
+
 	// TODO: This hack is here to make test cases pass. It can be removed at some point
 	if opts.Format == "json" && len(opts.OutputFn) > 0 && opts.TestMode {
 		fmt.Println("{ \"outputFilename\": \"--output_filename--\" }")
@@ -303,7 +303,7 @@ func (updater *MonitorUpdate) updateMonitors(result *index.AppearanceResult) {
 		return
 	}
 
-	lastScanned := uint32(result.Range.Last) + 1
+	lastScanned := result.Range.Last + 1
 
 	mon := updater.MonitorMap[result.Address]
 	if mon == nil {
@@ -364,14 +364,14 @@ const (
 	endOfTxId           = 42 + 1 + 9 + 1 + 5
 )
 
-func getAppearances(addrStr string, lines []string, lastVisited uint32, found int) *[]index.AppearanceRecord {
+func getAppearances(addrStr string, lines []string, lastVisited uint64, found int) *[]index.AppearanceRecord {
 	results := make([]index.AppearanceRecord, 0, 1000)
 	for idx := found; idx < len(lines); idx++ {
 		if !strings.HasPrefix(lines[idx], addrStr) {
 			break
 		}
 		r := index.AppearanceRecord{
-			BlockNumber:   uint32(globals.ToUint64(lines[idx][startOfBlockNum:endOfBlockNum])),
+			BlockNumber:   globals.ToUint64(lines[idx][startOfBlockNum:endOfBlockNum]),
 			TransactionId: uint32(globals.ToUint64(lines[idx][startOfTxId:endOfTxId])),
 		}
 		if r.BlockNumber > lastVisited {
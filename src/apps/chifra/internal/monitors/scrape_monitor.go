@@ -8,16 +8,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/kballard/go-shellquote"
 )
 
 var MonitorScraper Scraper
@@ -39,28 +43,12 @@ func (opts *MonitorsOptions) RunMonitorScraper(wg *sync.WaitGroup) {
 			s.Pause()
 
 		} else {
-			monitorChan := make(chan monitor.Monitor)
-			var monitors []monitor.Monitor
-			go monitor.ListMonitors(chain, monitorChan)
-			count := 0
-			for result := range monitorChan {
-				switch result.Address {
-				case base.SentinalAddr:
-					close(monitorChan)
-				default:
-					if result.Count() > 500000 {
-						logger.Warn("Ignoring too-large address", result.Address)
-						continue
-					}
-					monitors = append(monitors, result)
-					count++
-					if result.Count() > 0 {
-						logger.Info("     ", count, ": ", result, "                                        ")
-					}
-				}
-			}
+			monitors := opts.getMonitors()
 
-			canceled, _ := opts.Refresh(monitors)
+			canceled, err := opts.Refresh(monitors)
+			if err != nil {
+				logger.Error(err)
+			}
 			if canceled || os.Getenv("RUN_ONCE") == "true" {
 				return
 			}
@@ -77,10 +65,67 @@ func (opts *MonitorsOptions) RunMonitorScraper(wg *sync.WaitGroup) {
 	}
 }
 
+// getMonitors returns the monitors to watch. If a --watchlist file is provided (other than the value
+// `existing`), the monitors come from that file (creating them if necessary). Otherwise, we watch
+// all existing monitors.
+func (opts *MonitorsOptions) getMonitors() []monitor.Monitor {
+	chain := opts.Globals.Chain
+
+	var monitors []monitor.Monitor
+	addMonitor := func(mon monitor.Monitor) {
+		if mon.Count() > 500000 {
+			logger.Warn("Ignoring too-large address", mon.Address)
+			return
+		}
+		monitors = append(monitors, mon)
+		if mon.Count() > 0 {
+			logger.Info("     ", len(monitors), ": ", mon, "                                        ")
+		}
+	}
+
+	if len(opts.Watchlist) > 0 && opts.Watchlist != "existing" {
+		addrMap := make(map[base.Address]bool)
+		for _, line := range file.AsciiFileToLines(opts.Watchlist) {
+			line = strings.Trim(strings.Split(line, "\t")[0], " ")
+			if len(line) == 0 || strings.HasPrefix(line, "#") {
+				continue
+			}
+			resolved, _ := opts.Conn.GetEnsAddresses([]string{line})
+			if len(resolved) == 0 || !base.IsValidAddress(resolved[0]) {
+				logger.Warn("Ignoring invalid address in watchlist:", line)
+				continue
+			}
+			addr := base.HexToAddress(resolved[0])
+			if !addrMap[addr] && !addr.IsZero() {
+				addMonitor(monitor.NewMonitor(chain, addr.Hex(), true /* create */))
+			}
+			addrMap[addr] = true
+		}
+		return monitors
+	}
+
+	monitorChan := make(chan monitor.Monitor)
+	go monitor.ListMonitors(chain, monitorChan)
+	for result := range monitorChan {
+		switch result.Address {
+		case base.SentinalAddr:
+			close(monitorChan)
+		default:
+			addMonitor(result)
+		}
+	}
+	return monitors
+}
+
+// SemiParse carries a single command from the --commands file. Args holds the command's
+// arguments split as a shell would (so quoted arguments may contain spaces), CmdLine the same
+// arguments quoted back into a single line for display.
 type SemiParse struct {
-	CmdLine string `json:"cmdLine"`
-	Fmt     string `json:"fmt"`
-	Folder  string `json:"folder"`
+	Tool    string   `json:"tool"`
+	CmdLine string   `json:"cmdLine"`
+	Args    []string `json:"args"`
+	Fmt     string   `json:"fmt"`
+	Folder  string   `json:"folder"`
 }
 
 func (sp SemiParse) String() string {
@@ -88,70 +133,135 @@ func (sp SemiParse) String() string {
 	return string(ret)
 }
 
-// const addrsPerBatch = 8
+// databaseExports records the monitors (and commands) exported to an --output database during this run
+var databaseExports = map[string]bool{}
 
+// Refresh freshens the monitors in batches of --batch_size. For each monitor that has new
+// appearances, it runs each command from the --commands file, appending only the new records
+// to that monitor's output file. Returns true if the user canceled.
 func (opts *MonitorsOptions) Refresh(monitors []monitor.Monitor) (bool, error) {
-	// chain := opts.Globals.Chain
-	// theCmds, _ := getCommandsFromFile(opts.Globals)
-
-	// batches := batchMonitors(monitors, addrsPerBatch)
-	// for i := 0; i < len(batches); i++ {
-	// 	addrs, countsBefore := preProcessBatch(batches[i], i, len(monitors))
-
-	// 	canceled, err := opts.FreshenMonitorsForWatch(addrs)
-	// 	if canceled || err != nil {
-	// 		return canceled, err
-	// 	}
-
-	// 	for j := 0; j < len(batches[i]); j++ {
-	// 		mon := batches[i][j]
-	// 		countAfter := mon.Count()
-
-	// 		if countAfter > 1000000 {
-	// 			fmt.Println(colors.Red, "Too many transactions for address", mon.Address, colors.Off)
-	// 			continue
-	// 		}
-
-	// 		if countAfter == 0 {
-	// 			continue
-	// 		}
-
-	// 		for _, sp := range theCmds {
-	// 			outputFn := sp.Folder + "/" + mon.Address.Hex() + "." + sp.Fmt
-	// 			exists := file.FileExists(outputFn)
-	// 			countBefore := countsBefore[j]
-
-	// 			if !exists || countAfter > countBefore {
-	// 				cmd := sp.CmdLine + " --output " + outputFn
-	// 				add := ""
-	// 				if exists {
-	// 					add += fmt.Sprintf(" --first_record %d", uint64(countBefore+1))
-	// 					add += fmt.Sprintf(" --max_records %d", uint64(countAfter-countBefore+1)) // extra space won't hurt
-	// 					add += " --append --no_header"
-	// 				}
-	// 				cmd += add + " " + mon.Address.Hex()
-	// 				cmd = strings.Replace(cmd, "  ", " ", -1)
-	// 				o := opts
-	// 				o.Globals.File = ""
-	// 				_ = o.Globals.PassItOn("acctExport", chain, cmd, []string{})
-	// 			}
-	// 		}
-	// 	}
-	// }
+	theCmds, err := opts.getCommandsFromFile()
+	if err != nil {
+		return false, err
+	}
+
+	batches := batchMonitors(monitors, int(opts.BatchSize))
+	for i := 0; i < len(batches); i++ {
+		addrs, countsBefore := preProcessBatch(batches[i], i*int(opts.BatchSize), len(monitors))
+
+		canceled, err := opts.FreshenMonitorsForWatch(addrs)
+		if canceled || err != nil {
+			return canceled, err
+		}
+
+		for j := 0; j < len(batches[i]); j++ {
+			mon := batches[i][j]
+			countAfter := mon.Count()
+
+			if countAfter > 1000000 {
+				logger.Warn("Too many transactions for address", mon.Address)
+				continue
+			}
+
+			if countAfter == 0 {
+				continue
+			}
+
+			for _, sp := range theCmds {
+				outputFn := filepath.Join(sp.Folder, mon.Address.Hex()+"."+sp.Fmt)
+				exists := file.FileExists(outputFn)
+				databaseKey := mon.Address.Hex() + "\t" + sp.CmdLine
+				if output.IsDatabaseDsn(opts.Globals.OutputFn) {
+					// Every command writes to the --output database. Its rows are upserted, so each
+					// monitor is exported in full the first time through and incrementally thereafter.
+					outputFn, exists = opts.Globals.OutputFn, databaseExports[databaseKey]
+				}
+				countBefore := countsBefore[j]
+
+				if exists && countAfter <= countBefore {
+					continue
+				}
+
+				args := append([]string{}, sp.Args...)
+				args = append(args, "--output", outputFn)
+				if sp.Tool == "export" || sp.Tool == "list" {
+					first := int64(0)
+					if exists {
+						first = countBefore
+					}
+					args = append(args, "--first_record", fmt.Sprintf("%d", uint64(first)))
+					args = append(args, "--max_records", fmt.Sprintf("%d", uint64(countAfter-first+1))) // extra space won't hurt
+				}
+				if exists {
+					args = append(args, "--append", "--no_header")
+				}
+
+				hasToken := false
+				for k := range args {
+					if strings.Contains(args[k], addressToken) {
+						args[k] = strings.Replace(args[k], addressToken, mon.Address.Hex(), -1)
+						hasToken = true
+					}
+				}
+				if !hasToken {
+					args = append(args, mon.Address.Hex())
+				}
+
+				if err := opts.runCommand(sp.Tool, args); err != nil {
+					logger.Warn("Command", sp.Tool, shellquote.Join(args...), "failed for address", mon.Address, err)
+				} else if outputFn == opts.Globals.OutputFn {
+					databaseExports[databaseKey] = true
+				}
+			}
+		}
+	}
 	return false, nil
 }
 
-// func batchMonitors(slice []monitor.Monitor, batchSize int) [][]monitor.Monitor {
-// 	var batches [][]monitor.Monitor
-// 	for i := 0; i < len(slice); i += batchSize {
-// 		end := i + batchSize
-// 		if end > len(slice) {
-// 			end = len(slice)
-// 		}
-// 		batches = append(batches, slice[i:end])
-// 	}
-// 	return batches
-// }
+// runCommand runs a single chifra command on behalf of a watched address. We use a separate
+// process so each command is parsed exactly as it would be on the command line. We release
+// the cache first, as the command may need to write to it.
+func (opts *MonitorsOptions) runCommand(tool string, args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	if err := cache.CloseStores(); err != nil {
+		logger.Warn("Could not release the cache:", err)
+	}
+
+	hasChain := false
+	for _, arg := range args {
+		if arg == "--chain" || strings.HasPrefix(arg, "--chain=") {
+			hasChain = true
+		}
+	}
+	args = append([]string{tool}, args...)
+	if !hasChain {
+		args = append(args, "--chain", opts.Globals.Chain)
+	}
+
+	c := exec.Command(exe, args...)
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+func batchMonitors(slice []monitor.Monitor, batchSize int) [][]monitor.Monitor {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	var batches [][]monitor.Monitor
+	for i := 0; i < len(slice); i += batchSize {
+		end := i + batchSize
+		if end > len(slice) {
+			end = len(slice)
+		}
+		batches = append(batches, slice[i:end])
+	}
+	return batches
+}
 
 func GetExportFormat(cmd, def string) string {
 	if strings.Contains(cmd, "json") {
@@ -167,70 +277,107 @@ func GetExportFormat(cmd, def string) string {
 	return "csv"
 }
 
-// func getCommandsFromFile(globals globals.GlobalOptions) ([]SemiParse, error) {
-// 	ret := []SemiParse{}
-// 	cmdLines := []string{}
-
-// 	commandFile := globals.File
-// 	if commandFile == "" && file.FileExists("./commands.fil") {
-// 		commandFile = "./commands.fil"
-// 	}
-// 	if !file.FileExists(commandFile) {
-// 		logger.Warn("No --file option supplied. Using default.")
-// 		cmdLines = append(cmdLines, "export --appearances")
-// 	} else {
-// 		cmdLines = file.AsciiFileToLines(commandFile)
-// 	}
-
-// 	for _, cmd := range cmdLines {
-// 		if strings.HasPrefix(cmd, "export ") {
-// 			cmd = strings.Replace(cmd, "export ", "", -1)
-// 		}
-// 		cmd = strings.Trim(cmd, " \t")
-// 		if len(cmd) > 0 && !strings.HasPrefix(cmd, "#") {
-// 			sp := SemiParse{}
-// 			sp.Folder = "exports/" + globals.Chain + "/" + GetOutputFolder(cmd, "unknown")
-
-// 			sp.Fmt = GetExportFormat(cmd, globals.Format)
-// 			sp.CmdLine = cmd
-// 			sp.CmdLine = strings.Replace(sp.CmdLine, " csv", " "+sp.Fmt, -1)
-// 			sp.CmdLine = strings.Replace(sp.CmdLine, " json", " "+sp.Fmt, -1)
-// 			sp.CmdLine = strings.Replace(sp.CmdLine, " txt", " "+sp.Fmt, -1)
-// 			if !strings.Contains(sp.CmdLine, "--fmt") {
-// 				sp.CmdLine += " --fmt " + sp.Fmt
-// 			}
-// 			ret = append(ret, sp)
-// 		}
-// 	}
-
-// 	logger.Info("Found", len(ret), "commands to process in ./"+globals.File)
-// 	for i, cmd := range ret {
-// 		msg := fmt.Sprintf("\t%d. %s", i, cmd.CmdLine)
-// 		logger.Info(msg)
-// 	}
-
-// 	return ret, nil
-// }
-
-// const spaces = "                                                                                 "
-
-// func preProcessBatch(batch []monitor.Monitor, i, nMons int) ([]string, []int64) {
-// 	var addrs []string
-// 	for j := 0; j < len(batch); j++ {
-// 		addrs = append(addrs, batch[j].Address.Hex())
-// 	}
-
-// 	fmt.Println(strings.Repeat(" ", 120), "\r")
-// 	s := fmt.Sprintf("%s\r%d-%d", colors.BrightBlue+spaces, i*addrsPerBatch, nMons)
-// 	fmt.Println(s, colors.Green, "chifra export --freshen", strings.Replace(strings.Join(addrs, " "), "0x", " \\\n\t0x", -1), colors.Off)
-
-// 	countsBefore := []int64{}
-// 	for j := 0; j < len(batch); j++ {
-// 		countsBefore = append(countsBefore, (batch)[j].Count())
-// 	}
-
-// 	return addrs, countsBefore
-// }
+// addressToken is replaced with each watched address in the commands from the --commands file
+const addressToken = "[{ADDRESS}]"
+
+// watchableTools are the chifra commands that operate on addresses and may appear in the --commands file
+var watchableTools = map[string]bool{
+	"export": true,
+	"list":   true,
+	"state":  true,
+	"tokens": true,
+}
+
+func (opts *MonitorsOptions) getCommandsFromFile() ([]SemiParse, error) {
+	ret := []SemiParse{}
+	cmdLines := []string{}
+
+	commandFile := opts.Commands
+	if commandFile == "" && file.FileExists("./commands.fil") {
+		commandFile = "./commands.fil"
+	}
+	if !file.FileExists(commandFile) {
+		logger.Warn("No --commands file supplied. Using default.")
+		cmdLines = append(cmdLines, "export --appearances")
+	} else {
+		cmdLines = file.AsciiFileToLines(commandFile)
+	}
+
+	for _, cmd := range cmdLines {
+		cmd = strings.Trim(cmd, " \t")
+		if len(cmd) == 0 || strings.HasPrefix(cmd, "#") || strings.HasPrefix(cmd, ";") {
+			continue
+		}
+
+		fields, err := shellquote.Split(strings.TrimPrefix(cmd, "chifra "))
+		if err != nil {
+			logger.Warn("Ignoring invalid command in", commandFile+":", cmd, err)
+			continue
+		}
+		tool := "export"
+		if len(fields) > 0 && !strings.HasPrefix(fields[0], "-") && fields[0] != addressToken {
+			tool, fields = fields[0], fields[1:]
+		}
+		if !watchableTools[tool] {
+			logger.Warn("Ignoring invalid command in", commandFile+":", cmd)
+			continue
+		}
+		cmd = strings.Join(fields, " ")
+
+		sp := SemiParse{Tool: tool}
+		folder := tool
+		if tool == "export" {
+			folder = GetOutputFolder(cmd, "unknown")
+		} else if tool == "list" {
+			folder = "apps"
+		}
+		sp.Folder = filepath.Join("exports", opts.Globals.Chain, folder)
+		if err := file.EstablishFolder(sp.Folder); err != nil {
+			return ret, err
+		}
+
+		sp.Fmt = GetExportFormat(cmd, opts.Globals.Format)
+		hasFmt := false
+		for _, field := range fields {
+			switch field {
+			case "csv", "json", "txt":
+				field = sp.Fmt
+			case "--fmt":
+				hasFmt = true
+			}
+			sp.Args = append(sp.Args, field)
+		}
+		if !hasFmt {
+			sp.Args = append(sp.Args, "--fmt", sp.Fmt)
+		}
+		sp.CmdLine = shellquote.Join(sp.Args...)
+		ret = append(ret, sp)
+	}
+
+	logger.Info("Found", len(ret), "commands to process in", commandFile)
+	for i, cmd := range ret {
+		msg := fmt.Sprintf("\t%d. %s %s", i, cmd.Tool, cmd.CmdLine)
+		logger.Info(msg)
+	}
+
+	return ret, nil
+}
+
+func preProcessBatch(batch []monitor.Monitor, first, nMons int) ([]string, []int64) {
+	var addrs []string
+	for j := 0; j < len(batch); j++ {
+		addrs = append(addrs, batch[j].Address.Hex())
+	}
+
+	logger.Info(fmt.Sprintf("Freshening monitors %d-%d of %d", first, first+len(batch), nMons))
+
+	countsBefore := []int64{}
+	for j := 0; j < len(batch); j++ {
+		countsBefore = append(countsBefore, batch[j].Count())
+	}
+
+	return addrs, countsBefore
+}
 
 // TODO: We could add statistics counting -- nChanged, nProcessed, txCount, etc
 // TODO: Need to protect against invalid addresses including zero address
@@ -0,0 +1,83 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package monitorsPkg
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+)
+
+func TestBatchMonitors(t *testing.T) {
+	monitors := make([]monitor.Monitor, 19)
+
+	tests := []struct {
+		batchSize int
+		nBatches  int
+		lastLen   int
+	}{
+		{batchSize: 8, nBatches: 3, lastLen: 3},
+		{batchSize: 19, nBatches: 1, lastLen: 19},
+		{batchSize: 100, nBatches: 1, lastLen: 19},
+		{batchSize: 0, nBatches: 19, lastLen: 1},
+	}
+
+	for _, tt := range tests {
+		batches := batchMonitors(monitors, tt.batchSize)
+		if len(batches) != tt.nBatches {
+			t.Error("batch size", tt.batchSize, "expected", tt.nBatches, "batches, got", len(batches))
+			continue
+		}
+		if len(batches[len(batches)-1]) != tt.lastLen {
+			t.Error("batch size", tt.batchSize, "expected last batch of", tt.lastLen, "got", len(batches[len(batches)-1]))
+		}
+	}
+}
+
+func TestGetCommandsFromFile(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer func() {
+		_ = os.Chdir(cwd)
+	}()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := []string{
+		`chifra state --call "balanceOf([{ADDRESS}])" --fmt json`,
+		`export --logs --articulate`,
+		`tokens --parts 'name symbol'`,
+		`export --logs "unbalanced`,
+	}
+	if err := os.WriteFile("commands.fil", []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &MonitorsOptions{Commands: "commands.fil"}
+	cmds, err := opts.getCommandsFromFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []SemiParse{
+		{Tool: "state", Args: []string{"--call", "balanceOf([{ADDRESS}])", "--fmt", "json"}},
+		{Tool: "export", Args: []string{"--logs", "--articulate", "--fmt", "csv"}},
+		{Tool: "tokens", Args: []string{"--parts", "name symbol", "--fmt", "csv"}},
+	}
+	if len(cmds) != len(expected) {
+		t.Fatal("expected", len(expected), "commands, got", len(cmds))
+	}
+	for i, want := range expected {
+		if cmds[i].Tool != want.Tool || !reflect.DeepEqual(cmds[i].Args, want.Args) {
+			t.Error("command", i, "expected", want.Tool, want.Args, "got", cmds[i].Tool, cmds[i].Args)
+		}
+	}
+	if cmds[2].CmdLine != `--parts 'name symbol' --fmt csv` {
+		t.Error("wrong command line:", cmds[2].CmdLine)
+	}
+}
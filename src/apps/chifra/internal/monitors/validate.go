@@ -42,18 +42,30 @@ func (opts *MonitorsOptions) validateMonitors() error {
 				return validate.Usage(err.Error())
 			}
 
-			cmdFile := opts.Globals.File
-			if !file.FileExists(cmdFile) {
-				dir, _ := os.Getwd()
-				cmdFile = filepath.Join(dir, opts.Globals.File)
+			if len(opts.Watchlist) > 0 && opts.Watchlist != "existing" && !file.FileExists(opts.Watchlist) {
+				return validate.Usage("The {0} option ({1}) must {2}", "--watchlist", opts.Watchlist, "exist")
 			}
 
-			if file.FileExists(cmdFile) {
-				contents := file.AsciiFileToString(cmdFile)
-				cmds := strings.Split(contents, "\n")
-				if len(cmds) == 0 {
+			if len(opts.Commands) > 0 {
+				cmdFile := opts.Commands
+				if !file.FileExists(cmdFile) {
+					dir, _ := os.Getwd()
+					cmdFile = filepath.Join(dir, opts.Commands)
+				}
+
+				if !file.FileExists(cmdFile) {
+					return validate.Usage("The {0} option ({1}) must {2}", "--commands", opts.Commands, "exist")
+				}
+
+				contents := strings.Trim(file.AsciiFileToString(cmdFile), " \t\n")
+				if len(contents) == 0 {
 					return validate.Usage("The command file you specified ({0}) was found but contained no commands.", cmdFile)
 				}
+				opts.Commands = cmdFile
+			}
+
+			if opts.BatchSize == 0 {
+				return validate.Usage("The {0} option must be greater than zero.", "--batch_size")
 			}
 
 			if opts.Globals.IsApiMode() {
@@ -37,6 +37,7 @@ type BlazeOptions struct {
 	RpcProvider  string                  `json:"rpcProvider"`
 	TsArray      []tslib.TimestampRecord `json:"-"`
 	ProcessedMap map[base.Blknum]bool    `json:"-"`
+	HashMap      blockHashMap            `json:"-"`
 	BlockWg      sync.WaitGroup          `json:"-"`
 	AppearanceWg sync.WaitGroup          `json:"-"`
 	TsWg         sync.WaitGroup          `json:"-"`
@@ -131,9 +132,15 @@ func (opts *BlazeOptions) BlazeProcessBlocks(meta *rpc.MetaData, blockChannel ch
 		chain := opts.Chain
 		conn := rpc.TempConnection(chain)
 
+		hash, parentHash, blockTs, err := conn.GetBlockHashes(uint64(bn))
+		if err != nil {
+			// TODO: BOGUS - we should send in an errorChannel and send the error down that channel and continue here
+			return err
+		}
+
 		ts := tslib.TimestampRecord{
-			Bn: uint32(bn),
-			Ts: uint32(conn.GetBlockTimestamp(uint64(bn))),
+			Bn: uint64(bn),
+			Ts: uint32(blockTs),
 		}
 
 		// TODO: BOGUS - This could use rawTraces so as to avoid unnecessary decoding
@@ -148,6 +155,14 @@ func (opts *BlazeOptions) BlazeProcessBlocks(meta *rpc.MetaData, blockChannel ch
 			return err
 		}
 
+		writeMutex.Lock()
+		opts.HashMap[sd.blockNumber] = blockHashRecord{
+			Bn:         sd.blockNumber,
+			Hash:       hash,
+			ParentHash: parentHash,
+		}
+		writeMutex.Unlock()
+
 		appearanceChannel <- sd
 		tsChannel <- ts
 	}
@@ -49,7 +49,7 @@ func (opts *ScrapeOptions) HandlePrepare(progressThen *rpc.MetaData, blazeOpts *
 
 	array := []tslib.TimestampRecord{}
 	array = append(array, tslib.TimestampRecord{
-		Bn: uint32(0),
+		Bn: uint64(0),
 		Ts: uint32(opts.Conn.GetBlockTimestamp(uint64(0))),
 	})
 	_ = tslib.Append(chain, array)
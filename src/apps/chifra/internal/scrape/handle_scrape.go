@@ -42,6 +42,7 @@ func (opts *ScrapeOptions) HandleScrape() error {
 		RpcProvider:  provider,
 		TsArray:      make([]tslib.TimestampRecord, 0, opts.BlockCnt),
 		ProcessedMap: make(map[base.Blknum]bool, opts.BlockCnt),
+		HashMap:      make(blockHashMap, opts.BlockCnt),
 		AppsPerChunk: opts.Settings.Apps_per_chunk,
 	}
 
@@ -56,6 +57,18 @@ func (opts *ScrapeOptions) HandleScrape() error {
 			return err
 		}
 
+		// If the chain re-organized since the previous round, we roll back anything we've
+		// written past the fork and start this round from the block prior to the fork.
+		if reorged, err := opts.HandleReorg(progress); err != nil {
+			logger.Error(colors.BrightRed, err, colors.Off)
+			opts.Pause(progress)
+			continue
+		} else if reorged {
+			if progress, err = conn.GetMetaData(opts.Globals.TestMode); err != nil {
+				return err
+			}
+		}
+
 		// We start the current round one block past the end of the previous round
 		opts.StartBlock = utils.Max(progress.Ripe, utils.Max(progress.Staging, progress.Finalized)) + 1
 		// And each round we assume we're going to process this many blocks...
@@ -86,6 +99,7 @@ func (opts *ScrapeOptions) HandleScrape() error {
 			RpcProvider:  provider,
 			TsArray:      make([]tslib.TimestampRecord, 0, opts.BlockCnt),
 			ProcessedMap: make(map[base.Blknum]bool, opts.BlockCnt),
+			HashMap:      make(blockHashMap, opts.BlockCnt),
 			AppsPerChunk: opts.Settings.Apps_per_chunk,
 		}
 
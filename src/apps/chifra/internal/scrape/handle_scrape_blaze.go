@@ -5,9 +5,9 @@ package scrapePkg
 // be found in the LICENSE file.
 
 import (
-	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"sort"
 	"strings"
@@ -40,9 +40,20 @@ func (opts *ScrapeOptions) HandleScrapeBlaze(progress *rpc.MetaData, blazeOpts *
 		}
 	}
 
+	// Make sure the blocks we just scraped link to each other and to the blocks we scraped
+	// in previous rounds. If they don't, the chain re-organized and the next round will repair it.
+	hashes := readHashes(chain)
+	if err := checkParentHashes(hashes, blazeOpts.HashMap); err != nil {
+		_ = index.CleanTemporaryFolders(config.PathToIndex(chain), false)
+		return err
+	}
+
 	_ = WriteTimestamps(blazeOpts.Chain, blazeOpts.TsArray, blazeOpts.StartBlock+blazeOpts.BlockCount)
 
-	return nil
+	for bn, rec := range blazeOpts.HashMap {
+		hashes[bn] = rec
+	}
+	return writeHashes(chain, hashes, progress.Finalized+1)
 }
 
 // TODO: Protect against overwriting files on disc
@@ -54,6 +65,14 @@ func WriteTimestamps(chain string, tsArray []tslib.TimestampRecord, endPoint uin
 		return tsArray[i].Bn < tsArray[j].Bn
 	})
 
+	// Block numbers that do not fit in 32 bits need a timestamps file with 64-bit block numbers
+	if endPoint > math.MaxUint32 {
+		if _, err := tslib.Upgrade(chain); err != nil {
+			return err
+		}
+	}
+	width := tslib.GetRecordWidth(chain)
+
 	// Assume that the existing timestamps file always contains valid timestamps in a valid order so we can only append
 	tsPath := config.PathToIndex(chain) + "ts.bin"
 	fp, err := os.OpenFile(tsPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
@@ -77,14 +96,14 @@ func WriteTimestamps(chain string, tsArray []tslib.TimestampRecord, endPoint uin
 		ts := tslib.TimestampRecord{}
 		if cnt >= len(tsArray) {
 			ts = tslib.TimestampRecord{
-				Bn: uint32(bn),
+				Bn: bn,
 				Ts: uint32(conn.GetBlockTimestamp(bn)),
 			}
 		} else {
 			ts = tsArray[cnt]
-			if tsArray[cnt].Bn != uint32(bn) {
+			if tsArray[cnt].Bn != bn {
 				ts = tslib.TimestampRecord{
-					Bn: uint32(bn),
+					Bn: bn,
 					Ts: uint32(conn.GetBlockTimestamp(bn)),
 				}
 				cnt-- // set it back
@@ -92,7 +111,7 @@ func WriteTimestamps(chain string, tsArray []tslib.TimestampRecord, endPoint uin
 		}
 
 		logger.Progress((bn%13) == 0, fmt.Sprintf("Checking or updating timestamps %-04d of %-04d (%d remaining)%s", bn, endPoint, endPoint-bn, spaces))
-		if err = binary.Write(fp, binary.LittleEndian, &ts); err != nil {
+		if err = tslib.WriteRecords(fp, width, []tslib.TimestampRecord{ts}); err != nil {
 			return err
 		}
 
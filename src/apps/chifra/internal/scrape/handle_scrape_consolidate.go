@@ -112,10 +112,10 @@ func (opts *ScrapeOptions) HandleScrapeConsolidate(progressThen *rpc.MetaData, b
 				parts := strings.Split(line, "\t")
 				if len(parts) == 3 {
 					addr := strings.ToLower(parts[0])
-					bn, _ := strconv.ParseUint(parts[1], 10, 32)
+					bn, _ := strconv.ParseUint(parts[1], 10, 64)
 					txid, _ := strconv.ParseUint(parts[2], 10, 32)
 					appMap[addr] = append(appMap[addr], index.AppearanceRecord{
-						BlockNumber:   uint32(bn),
+						BlockNumber:   bn,
 						TransactionId: uint32(txid),
 					})
 				}
@@ -0,0 +1,264 @@
+package scrapePkg
+
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/tslib"
+)
+
+// blockHashRecord carries the hash and parent hash of a block as seen by the scraper when it
+// scraped the block. We remember these for every block that has not yet been consolidated into a
+// finalized chunk so we can tell if the chain re-organizes underneath us.
+type blockHashRecord struct {
+	Bn         base.Blknum
+	Hash       base.Hash
+	ParentHash base.Hash
+}
+
+type blockHashMap map[base.Blknum]blockHashRecord
+
+// hashesPath returns the path to the file that remembers the hashes of the non-finalized blocks
+func hashesPath(chain string) string {
+	return filepath.Join(config.PathToIndex(chain), "hashes.txt")
+}
+
+// readHashes reads the remembered block hashes from disc. A missing or corrupted file simply means
+// we have nothing to compare against, so we return an empty map.
+func readHashes(chain string) blockHashMap {
+	hashes := make(blockHashMap)
+	for _, line := range file.AsciiFileToLines(hashesPath(chain)) {
+		parts := strings.Split(line, "\t")
+		if len(parts) != 3 {
+			continue
+		}
+		bn, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		hashes[bn] = blockHashRecord{
+			Bn:         bn,
+			Hash:       base.HexToHash(parts[1]),
+			ParentHash: base.HexToHash(parts[2]),
+		}
+	}
+	return hashes
+}
+
+// writeHashes writes the remembered block hashes to disc, dropping any block prior to firstBlock
+// (i.e., blocks that have already been consolidated into a finalized chunk).
+func writeHashes(chain string, hashes blockHashMap, firstBlock base.Blknum) error {
+	lines := make([]string, 0, len(hashes))
+	for _, bn := range hashes.sortedBlocks() {
+		if bn < firstBlock {
+			continue
+		}
+		rec := hashes[bn]
+		lines = append(lines, fmt.Sprintf("%09d\t%s\t%s", rec.Bn, rec.Hash.Hex(), rec.ParentHash.Hex()))
+	}
+	return file.LinesToAsciiFile(hashesPath(chain), lines)
+}
+
+// sortedBlocks returns the block numbers in the map in ascending order
+func (hashes blockHashMap) sortedBlocks() []base.Blknum {
+	blocks := make([]base.Blknum, 0, len(hashes))
+	for bn := range hashes {
+		blocks = append(blocks, bn)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i] < blocks[j]
+	})
+	return blocks
+}
+
+// checkParentHashes makes sure each newly scraped block's parent hash matches the hash of the block
+// prior to it (either scraped in this round or remembered from a previous round). If not, the chain
+// re-organized while (or since) we scraped.
+func checkParentHashes(known, scraped blockHashMap) error {
+	for _, bn := range scraped.sortedBlocks() {
+		if bn == 0 {
+			continue
+		}
+		parent, ok := scraped[bn-1]
+		if !ok {
+			if parent, ok = known[bn-1]; !ok {
+				continue
+			}
+		}
+		if scraped[bn].ParentHash != parent.Hash {
+			return fmt.Errorf("the parent hash of block %d does not match the hash of block %d, the chain may have re-organized", bn, bn-1)
+		}
+	}
+	return nil
+}
+
+// findForkPoint walks backwards through the remembered block hashes comparing each against the
+// hash currently reported by the node. It returns the earliest block whose hash has changed and
+// true if the chain re-organized. If the most recent block still matches, there is no reorg.
+func findForkPoint(hashes blockHashMap, latest base.Blknum, getHash func(base.Blknum) (base.Hash, error)) (base.Blknum, bool, error) {
+	blocks := hashes.sortedBlocks()
+	forkBn, found := base.Blknum(0), false
+	for i := len(blocks) - 1; i >= 0; i-- {
+		bn := blocks[i]
+		if bn > latest {
+			// The node is behind the index (perhaps it's re-syncing). We can't check these.
+			continue
+		}
+		hash, err := getHash(bn)
+		if err != nil {
+			return 0, false, err
+		}
+		if hash == hashes[bn].Hash {
+			break
+		}
+		forkBn, found = bn, true
+	}
+	return forkBn, found, nil
+}
+
+// HandleReorg compares the block hashes remembered from previous rounds against the node. If the
+// chain re-organized, it rolls back the ripe, unripe, and staged appearances, the timestamps, and
+// any monitors past the fork so the next round re-scrapes those blocks. Returns true if it rolled
+// anything back.
+func (opts *ScrapeOptions) HandleReorg(progress *rpc.MetaData) (bool, error) {
+	chain := opts.Globals.Chain
+	hashes := readHashes(chain)
+	if len(hashes) == 0 {
+		return false, nil
+	}
+
+	conn := rpc.TempConnection(chain)
+	forkBn, found, err := findForkPoint(hashes, progress.Latest, func(bn base.Blknum) (base.Hash, error) {
+		hash, _, _, err := conn.GetBlockHashes(bn)
+		return hash, err
+	})
+	if err != nil || !found {
+		return false, err
+	}
+
+	if forkBn <= progress.Finalized {
+		msg := "the chain re-organized at block %d which is already part of a finalized chunk (%d), use chifra chunks --truncate to repair the index"
+		return false, fmt.Errorf(msg, forkBn, progress.Finalized)
+	}
+
+	logger.Warn(fmt.Sprintf("The chain re-organized at block %d. Rolling back the index to block %d.%s", forkBn, forkBn-1, spaces))
+	for bn := range hashes {
+		if bn >= forkBn {
+			delete(hashes, bn)
+		}
+	}
+
+	return true, rollback(chain, forkBn, hashes)
+}
+
+// rollback removes everything the scraper wrote at or after forkBn other than the finalized chunks.
+func rollback(chain string, forkBn base.Blknum, hashes blockHashMap) error {
+	if err := index.CleanTemporaryFolders(config.PathToIndex(chain), false); err != nil {
+		return err
+	}
+
+	if err := truncateStaging(chain, forkBn); err != nil {
+		return err
+	}
+
+	if err := tslib.Truncate(chain, forkBn); err != nil {
+		return err
+	}
+
+	if err := truncateMonitors(chain, forkBn); err != nil {
+		return err
+	}
+
+	return writeHashes(chain, hashes, 0)
+}
+
+// truncateStaging removes appearances at or after forkBn from the staging file and renames the file
+// so its range ends just prior to the fork. If the fork precedes the file's range, the file is removed.
+func truncateStaging(chain string, forkBn base.Blknum) error {
+	stageFolder := filepath.Join(config.PathToIndex(chain), "staging")
+	stageFn, _ := file.LatestFileInFolder(stageFolder) // it may not exist...
+	if !file.FileExists(stageFn) {
+		return nil
+	}
+
+	stageRange := base.RangeFromFilename(stageFn)
+	if stageRange.Last < forkBn {
+		return nil
+	}
+
+	if forkBn > stageRange.First {
+		keep := []string{}
+		for _, line := range file.AsciiFileToLines(stageFn) {
+			parts := strings.Split(line, "\t")
+			if len(parts) == 3 {
+				if bn, _ := strconv.ParseUint(parts[1], 10, 64); bn < forkBn {
+					keep = append(keep, line)
+				}
+			}
+		}
+		newRange := base.FileRange{First: stageRange.First, Last: forkBn - 1}
+		newFilename := filepath.Join(stageFolder, newRange.String()+".txt")
+		if err := file.LinesToAsciiFile(newFilename, keep); err != nil {
+			os.Remove(newFilename)
+			return err
+		}
+	}
+
+	return os.Remove(stageFn)
+}
+
+// truncateMonitors removes appearances at or after forkBn from both production and staged monitors
+// and resets their last scanned block.
+func truncateMonitors(chain string, forkBn base.Blknum) error {
+	monitorsPath := config.PathToCache(chain) + "monitors"
+	truncateMonitor := func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		addr, _ := base.AddressFromPath(path, monitor.Ext)
+		if len(addr) == 0 {
+			return nil
+		}
+
+		mon := monitor.Monitor{
+			Header:  monitor.Header{Magic: file.SmallMagicNumber},
+			Address: base.HexToAddress(addr),
+			Chain:   chain,
+			Staged:  filepath.Base(filepath.Dir(path)) == "staging",
+		}
+		err = mon.ReadMonitorHeader()
+		mon.Close()
+		if err != nil || base.Blknum(mon.LastScanned) < forkBn {
+			return err
+		}
+
+		if mon.Count() == 0 {
+			// There are no appearances to remove, but we still need to re-scan the forked blocks
+			return mon.WriteMonHeader(mon.Deleted, uint64(forkBn-1), true /* force */)
+		}
+
+		_, err = mon.TruncateTo(chain, uint64(forkBn-1))
+		return err
+	}
+	return filepath.Walk(monitorsPath, truncateMonitor)
+}
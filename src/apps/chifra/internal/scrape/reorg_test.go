@@ -0,0 +1,85 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package scrapePkg
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+)
+
+func testHash(bn base.Blknum, fork string) base.Hash {
+	return base.HexToHash(fmt.Sprintf("0x%s%x", fork, bn))
+}
+
+func testChain(first, last base.Blknum, fork string) blockHashMap {
+	hashes := make(blockHashMap)
+	for bn := first; bn <= last; bn++ {
+		hashes[bn] = blockHashRecord{
+			Bn:         bn,
+			Hash:       testHash(bn, fork),
+			ParentHash: testHash(bn-1, fork),
+		}
+	}
+	return hashes
+}
+
+func TestFindForkPoint(t *testing.T) {
+	known := testChain(100, 110, "a")
+
+	tests := []struct {
+		name   string
+		forkAt base.Blknum
+		latest base.Blknum
+		found  bool
+	}{
+		{name: "no reorg", forkAt: 200, latest: 120, found: false},
+		{name: "tip only", forkAt: 110, latest: 120, found: true},
+		{name: "deep", forkAt: 103, latest: 120, found: true},
+		{name: "node behind", forkAt: 108, latest: 107, found: false},
+	}
+
+	for _, tt := range tests {
+		getHash := func(bn base.Blknum) (base.Hash, error) {
+			if bn >= tt.forkAt {
+				return testHash(bn, "b"), nil
+			}
+			return testHash(bn, "a"), nil
+		}
+		forkBn, found, err := findForkPoint(known, tt.latest, getHash)
+		if err != nil {
+			t.Error(tt.name, "unexpected error", err)
+		}
+		if found != tt.found {
+			t.Error(tt.name, "expected found", tt.found, "got", found)
+		}
+		if found && forkBn != tt.forkAt {
+			t.Error(tt.name, "expected fork at", tt.forkAt, "got", forkBn)
+		}
+	}
+}
+
+func TestCheckParentHashes(t *testing.T) {
+	known := testChain(100, 110, "a")
+
+	if err := checkParentHashes(known, testChain(111, 120, "a")); err != nil {
+		t.Error("expected linked blocks to pass", err)
+	}
+
+	if err := checkParentHashes(known, testChain(111, 120, "b")); err == nil {
+		t.Error("expected a reorg at the boundary to fail")
+	}
+
+	scraped := testChain(111, 120, "a")
+	for bn, rec := range testChain(115, 120, "b") {
+		if bn > 115 {
+			scraped[bn] = rec
+		}
+	}
+	if err := checkParentHashes(known, scraped); err == nil {
+		t.Error("expected a reorg inside the scraped range to fail")
+	}
+}
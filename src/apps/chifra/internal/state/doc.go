@@ -6,5 +6,5 @@
  * the code inside of 'EXISTING_CODE' tags.
  */
 
-// Package statePkg handles the chifra state command. It The  tool retrieves the balance of an address (or list of addresses) at the given block (or blocks). Specify multiple addresses and/or multiple blocks if you wish, but you must specify at least one address. If no block is specified, the latest block is reported. You may also query to see if an address is a smart contract as well as retrieve a contract's byte code. 
+// Package statePkg handles the chifra state command. It The  tool retrieves the balance of an address (or list of addresses) at the given block (or blocks). Specify multiple addresses and/or multiple blocks if you wish, but you must specify at least one address. If no block is specified, the latest block is reported. You may also query to see if an address is a smart contract as well as retrieve a contract's byte code. With `--storage`, the tool reads a smart contract's storage slots instead. If you provide the contract's storage layout (from `solc --storage-layout` or your build artifacts) with `--layout`, you may name variables rather than slots, including mapping entries, array elements, and struct members, and the tool decodes their values (even when several share a slot). 
 package statePkg
@@ -0,0 +1,85 @@
+package statePkg
+
+import (
+	"context"
+	"errors"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/storage"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/tslib"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/ethereum/go-ethereum"
+)
+
+func (opts *StateOptions) HandleStorage() error {
+	chain := opts.Globals.Chain
+
+	var layout *storage.Layout
+	if len(opts.Layout) > 0 {
+		var err error
+		if layout, err = storage.LoadLayout(opts.Layout); err != nil {
+			return err
+		}
+	}
+
+	cnt := 0
+	ctx, cancel := context.WithCancel(context.Background())
+	fetchData := func(modelChan chan types.Modeler[types.RawStorageSlot], errorChan chan error) {
+		for _, addressStr := range opts.Addrs {
+			address := base.HexToAddress(addressStr)
+			for _, br := range opts.BlockIds {
+				blockNums, err := br.ResolveBlocks(chain)
+				if err != nil {
+					errorChan <- err
+					if errors.Is(err, ethereum.NotFound) {
+						continue
+					}
+					cancel()
+					return
+				}
+
+				for _, bn := range blockNums {
+					ts := base.Timestamp(0)
+					if opts.Globals.Verbose {
+						ts, _ = tslib.FromBnToTs(chain, bn)
+					}
+
+					read := func(slot base.Hash) (base.Hash, error) {
+						return opts.Conn.GetStorageAt(address, slot, bn)
+					}
+
+					for _, query := range opts.Storage {
+						items, err := storage.Read(layout, query, read)
+						if err != nil {
+							errorChan <- err
+							continue
+						}
+						for _, item := range items {
+							cnt++
+							modelChan <- &types.SimpleStorageSlot{
+								Address:     address,
+								BlockNumber: bn,
+								Timestamp:   ts,
+								Slot:        item.Slot,
+								Value:       item.Value,
+								Variable:    item.Name,
+								Type:        item.Type,
+								Offset:      item.Offset,
+								Decoded:     item.Decoded,
+							}
+						}
+					}
+				}
+			}
+		}
+		if cnt == 0 {
+			errorChan <- errors.New("no storage slots were reported")
+		}
+	}
+
+	extra := map[string]interface{}{
+		"layout": layout != nil,
+	}
+	return output.StreamMany(ctx, fetchData, opts.Globals.OutputOptsWithExtra(extra))
+}
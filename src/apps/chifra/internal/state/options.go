@@ -32,6 +32,8 @@ type StateOptions struct {
 	Call       string                   `json:"call,omitempty"`       // Call a smart contract with a solidity syntax, a four-byte and parameters, or encoded call data
 	Articulate bool                     `json:"articulate,omitempty"` // For the --call option only, articulate the retrieved data if ABIs can be found
 	ProxyFor   string                   `json:"proxyFor,omitempty"`   // For the --call option only, redirects calls to this implementation
+	Storage    []string                 `json:"storage,omitempty"`    // Read one or more storage slots (or, with --layout, variables) of a smart contract
+	Layout     string                   `json:"layout,omitempty"`     // For the --storage option only, a Solidity storage layout (JSON) used to decode the slots
 	Globals    globals.GlobalOptions    `json:"globals,omitempty"`    // The global options
 	Conn       *rpc.Connection          `json:"conn,omitempty"`       // The connection to the RPC server
 	BadFlag    error                    `json:"badFlag,omitempty"`    // An error flag if needed
@@ -51,6 +53,8 @@ func (opts *StateOptions) testLog() {
 	logger.TestLog(len(opts.Call) > 0, "Call: ", opts.Call)
 	logger.TestLog(opts.Articulate, "Articulate: ", opts.Articulate)
 	logger.TestLog(len(opts.ProxyFor) > 0, "ProxyFor: ", opts.ProxyFor)
+	logger.TestLog(len(opts.Storage) > 0, "Storage: ", opts.Storage)
+	logger.TestLog(len(opts.Layout) > 0, "Layout: ", opts.Layout)
 	opts.Conn.TestLog(opts.getCaches())
 	opts.Globals.TestLog()
 }
@@ -92,6 +96,13 @@ func stateFinishParseApi(w http.ResponseWriter, r *http.Request) *StateOptions {
 			opts.Articulate = true
 		case "proxyFor":
 			opts.ProxyFor = value[0]
+		case "storage":
+			for _, val := range value {
+				s := strings.Split(val, " ") // may contain space separated items
+				opts.Storage = append(opts.Storage, s...)
+			}
+		case "layout":
+			opts.Layout = value[0]
 		default:
 			if !copy.Globals.Caps.HasKey(key) {
 				opts.BadFlag = validate.Usage("Invalid key ({0}) in {1} route.", key, "state")
@@ -62,6 +62,8 @@ func (opts *StateOptions) StateInternal() (err error, handled bool) {
 		err = opts.HandleDecache()
 	} else if opts.Call != "" {
 		err = opts.HandleCall()
+	} else if len(opts.Storage) > 0 {
+		err = opts.HandleStorage()
 	} else {
 		err = opts.HandleShow()
 	}
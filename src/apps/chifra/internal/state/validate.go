@@ -12,7 +12,9 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/call"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/storage"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/validate"
 )
 
@@ -42,6 +44,37 @@ func (opts *StateOptions) validateState() error {
 		// do nothing for now
 
 	} else {
+		if len(opts.Layout) > 0 && len(opts.Storage) == 0 {
+			return validate.Usage("The {0} option is only available with the {1} option.", "--layout", "--storage")
+		}
+
+		if len(opts.Storage) > 0 {
+			if len(opts.Call) > 0 {
+				return validate.Usage("The {0} option is not available{1}.", "--storage", " with the --call option")
+			}
+
+			if len(opts.Parts) > 0 {
+				return validate.Usage("The {0} option is not available{1}.", "--parts", " with the --storage option")
+			}
+
+			if opts.Changes {
+				return validate.Usage("The {0} option is not available{1}.", "--changes", " with the --storage option")
+			}
+
+			if opts.NoZero {
+				return validate.Usage("The {0} option is not available{1}.", "--no_zero", " with the --storage option")
+			}
+
+			if len(opts.Layout) > 0 {
+				if !file.FileExists(opts.Layout) {
+					return validate.Usage("The {0} option ({1}) must {2}", "--layout", opts.Layout, "exist")
+				}
+				if _, err := storage.LoadLayout(opts.Layout); err != nil {
+					return err
+				}
+			}
+		}
+
 		if len(opts.Call) > 0 {
 			if len(opts.Parts) > 0 {
 				return validate.Usage("The {0} option is not available{1}.", "--parts", " with the --call option")
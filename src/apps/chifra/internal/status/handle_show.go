@@ -2,11 +2,15 @@ package statusPkg
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache/locations"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/colors"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
@@ -40,7 +44,13 @@ func (opts *StatusOptions) HandleShow() error {
 			}
 			var t CacheWalker
 			t.ctx, t.cancel = context.WithCancel(context.Background())
-			go walk.WalkCacheFolder(t.ctx, chain, mT, &t, filenameChan)
+			if opts.isInStore(mT) {
+				counterMap[mT].Path = walk.GetRootPathFromCacheType(chain, mT)
+				t.items = make(map[string]*locations.ItemInfo)
+				go opts.walkStore(&t, mT, filenameChan)
+			} else {
+				go walk.WalkCacheFolder(t.ctx, chain, mT, &t, filenameChan)
+			}
 		}
 
 		for result := range filenameChan {
@@ -75,14 +85,23 @@ func (opts *StatusOptions) HandleShow() error {
 						counterMap[cT].Path = walk.GetRootPathFromCacheType(chain, cT)
 					} else {
 						result.Data.(*CacheWalker).nSeen++
+						storeItem := result.Data.(*CacheWalker).takeItem(result.Path)
 						if result.Data.(*CacheWalker).nSeen >= opts.FirstRecord {
 							counterMap[cT].NFiles++
-							counterMap[cT].SizeInBytes += file.FileSize(result.Path)
+							if storeItem != nil {
+								counterMap[cT].SizeInBytes += int64(storeItem.Size())
+							} else {
+								counterMap[cT].SizeInBytes += file.FileSize(result.Path)
+							}
 							if opts.Globals.Verbose && counterMap[cT].NFiles <= opts.MaxRecords {
 								result.FileRange = base.RangeFromFilename(result.Path)
 								result.TsRange.First, _ = tslib.FromBnToTs(chain, result.FileRange.First)
 								result.TsRange.Last, _ = tslib.FromBnToTs(chain, result.FileRange.Last)
 								cI, _ := walk.GetCacheItem(chain, testMode, cT, &result)
+								if storeItem != nil && !testMode {
+									cI["fileDate"] = storeItem.LastAccess().Format("2006-01-02 15:04:05")
+									cI["sizeInBytes"] = storeItem.Size()
+								}
 								if isIndex(cT) {
 									bP := index.ToBloomPath(result.Path)
 									cI["bloomSizeBytes"] = file.FileSize(bP)
@@ -152,4 +171,58 @@ type CacheWalker struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 	nSeen  uint64
+	// items holds what the cache store reported about each path it sent, if the walk is of the
+	// cache store rather than a folder
+	items      map[string]*locations.ItemInfo
+	itemsMutex sync.Mutex
+}
+
+// takeItem returns (and forgets) what the cache store reported about the item at path, if the
+// walk is of the cache store
+func (w *CacheWalker) takeItem(path string) *locations.ItemInfo {
+	w.itemsMutex.Lock()
+	defer w.itemsMutex.Unlock()
+	item := w.items[path]
+	delete(w.items, path)
+	return item
+}
+
+// isInStore returns true if the items of the given cache type are kept in a cache store
+// other than the file system (such as the bolt database), in which case we can't walk a folder
+// to find them
+func (opts *StatusOptions) isInStore(cT walk.CacheType) bool {
+	if opts.Conn == nil || opts.Conn.Store == nil || opts.Conn.Store.Location() == cache.FsCache {
+		return false
+	}
+	switch cT {
+	case walk.Cache_Blocks, walk.Cache_Results, walk.Cache_Logs, walk.Cache_Slurps, walk.Cache_State,
+		walk.Cache_Statements, walk.Cache_Tokens, walk.Cache_Traces, walk.Cache_Transactions:
+		return true
+	}
+	return false
+}
+
+// walkStore sends the path of every item of the given cache type held in the cache store
+// to filenameChan, the same way walk.WalkCacheFolder does for items in folders
+func (opts *StatusOptions) walkStore(t *CacheWalker, cT walk.CacheType, filenameChan chan<- walk.CacheFileInfo) {
+	defer func() {
+		filenameChan <- walk.CacheFileInfo{Type: walk.Cache_NotACache}
+	}()
+
+	err := opts.Conn.Store.Items(walk.CacheTypeToFolder[cT], func(path string, info *locations.ItemInfo) error {
+		t.itemsMutex.Lock()
+		t.items[path] = info
+		t.itemsMutex.Unlock()
+		filenameChan <- walk.CacheFileInfo{Type: cT, Path: path, FileRange: base.RangeFromFilename(path), Data: t}
+
+		select {
+		case <-t.ctx.Done():
+			return t.ctx.Err()
+		default:
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		logger.Warn("could not read the", cT.String(), "cache:", err)
+	}
 }
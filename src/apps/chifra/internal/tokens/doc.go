@@ -6,5 +6,5 @@
  * the code inside of 'EXISTING_CODE' tags.
  */
 
-// Package tokensPkg handles the chifra tokens command. It Given the address of an ERC20 token contract, the  tool reports token balances for one or more additional addresses. Alternatively, the tool can report the token balances for multiple ERC20 tokens for a single addresses. In normal operation the **first item** in the address_list is assumed to be an ERC20 token contract whose balances are being queried, whereas the remainder of the list is assumed to be addresses on which to report. In --byAcct mode, **all addresses** in the address_list are assumed to be ERC20 token contracts, except the final one which is the account whose token balances are reported. You may optionally specify one or more blocks at which to report. If no block is specified, the latest block is assumed. You may also optionally specify which parts of the token data to extract. 
+// Package tokensPkg handles the chifra tokens command. It Given the address of an ERC20 token contract, the  tool reports token balances for one or more additional addresses. Alternatively, the tool can report the token balances for multiple ERC20 tokens for a single addresses. In normal operation the **first item** in the address_list is assumed to be an ERC20 token contract whose balances are being queried, whereas the remainder of the list is assumed to be addresses on which to report. In --byAcct mode, **all addresses** in the address_list are assumed to be ERC20 token contracts, except the final one which is the account whose token balances are reported. In --holders mode, give only the token's address. The tool rebuilds the token's full list of holders at each given block by replaying the Transfer events found in the token's appearances in the index. Holders are reported largest balance first. The balances of the top holders are checked against the token contract, and any mismatch is reported. This is useful for airdrop and governance snapshots. You may optionally specify one or more blocks at which to report. If no block is specified, the latest block is assumed. You may also optionally specify which parts of the token data to extract. 
 package tokensPkg
@@ -0,0 +1,175 @@
+package tokensPkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	listPkg "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/list"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/names"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/tslib"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
+	"github.com/ethereum/go-ethereum"
+)
+
+// holderSample is the number of top holders whose rebuilt balances are checked against the chain
+const holderSample = 10
+
+func (opts *TokensOptions) HandleHolders() error {
+	chain := opts.Globals.Chain
+	testMode := opts.Globals.TestMode
+	tokenAddr := base.HexToAddress(opts.Addrs[0])
+
+	monitorArray := make([]monitor.Monitor, 0, 1)
+	listOpts := listPkg.ListOptions{
+		Addrs:   opts.Addrs[:1],
+		Silent:  true,
+		Globals: opts.Globals,
+	}
+	if canceled, err := listOpts.HandleFreshenMonitors(&monitorArray); err != nil || canceled {
+		return err
+	}
+	mon := &monitorArray[0]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fetchData := func(modelChan chan types.Modeler[types.RawToken], errorChan chan error) {
+		snapshots := make([]base.Blknum, 0, len(opts.BlockIds))
+		for _, br := range opts.BlockIds {
+			blockNums, err := br.ResolveBlocks(chain)
+			if err != nil {
+				errorChan <- err
+				if errors.Is(err, ethereum.NotFound) {
+					continue
+				}
+				cancel()
+				return
+			}
+			snapshots = append(snapshots, blockNums...)
+		}
+		if len(snapshots) == 0 {
+			return
+		}
+		sort.Slice(snapshots, func(i, j int) bool {
+			return snapshots[i] < snapshots[j]
+		})
+
+		logs, err := opts.readTransfers(mon, snapshots[len(snapshots)-1])
+		if err != nil {
+			errorChan <- err
+			cancel()
+			return
+		}
+
+		holders := newHolderMap(tokenAddr)
+		next := 0
+		for _, bn := range snapshots {
+			for ; next < len(logs) && logs[next].BlockNumber <= bn; next++ {
+				holders.apply(&logs[next])
+			}
+
+			ts := base.Timestamp(0)
+			if opts.Globals.Verbose {
+				ts, _ = tslib.FromBnToTs(chain, bn)
+			}
+
+			ranked := holders.ranked()
+			for i, holder := range ranked {
+				if i < holderSample {
+					opts.checkHolder(tokenAddr, &holder, bn, errorChan)
+				}
+				modelChan <- &types.SimpleToken{
+					Holder:      holder.Holder,
+					Address:     tokenAddr,
+					Balance:     holder.Balance,
+					BlockNumber: bn,
+					Timestamp:   ts,
+					TokenType:   types.TokenErc20,
+				}
+			}
+		}
+	}
+
+	nameParts := names.Custom | names.Prefund | names.Regular
+	namesMap, err := names.LoadNamesMap(chain, nameParts, nil)
+	if err != nil {
+		return err
+	}
+
+	extra := map[string]interface{}{
+		"testMode": testMode,
+		"namesMap": namesMap,
+		"parts":    []string{"all_held"},
+	}
+
+	return output.StreamMany(ctx, fetchData, opts.Globals.OutputOptsWithExtra(extra))
+}
+
+// readTransfers returns the token's logs from each of its appearances up to and including the
+// given block sorted into the order in which they happened.
+func (opts *TokensOptions) readTransfers(mon *monitor.Monitor, lastBlock base.Blknum) ([]types.SimpleLog, error) {
+	filter := filter.NewFilter(
+		false,
+		base.BlockRange{First: 0, Last: lastBlock},
+		base.RecordRange{First: 0, Last: utils.NOPOS},
+	)
+
+	txMap, cnt, err := monitor.ReadAppearancesToMap[types.SimpleTransaction](mon, filter)
+	if err != nil {
+		return nil, err
+	} else if cnt == 0 {
+		return nil, fmt.Errorf("no appearances found for %s", mon.Address.Hex())
+	}
+
+	bar := logger.NewBar(logger.BarOptions{
+		Prefix:  mon.Address.Hex(),
+		Enabled: !opts.Globals.TestMode && len(opts.Globals.File) == 0,
+		Total:   int64(cnt),
+	})
+
+	if err := opts.Conn.ReadTransactions(txMap, nil, bar, false /* readTraces */); err != nil {
+		return nil, err
+	}
+
+	logs := make([]types.SimpleLog, 0, len(txMap))
+	for _, tx := range txMap {
+		if tx.Receipt == nil {
+			continue
+		}
+		for _, log := range tx.Receipt.Logs {
+			if log.Address == mon.Address {
+				logs = append(logs, log)
+			}
+		}
+	}
+
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].BlockNumber == logs[j].BlockNumber {
+			if logs[i].TransactionIndex == logs[j].TransactionIndex {
+				return logs[i].LogIndex < logs[j].LogIndex
+			}
+			return logs[i].TransactionIndex < logs[j].TransactionIndex
+		}
+		return logs[i].BlockNumber < logs[j].BlockNumber
+	})
+
+	return logs, nil
+}
+
+// checkHolder compares a holder's rebuilt balance to the balance reported by the token contract.
+// A mismatch (usually a token that rebases or changes balances without emitting Transfer events)
+// is reported, but does not stop the snapshot.
+func (opts *TokensOptions) checkHolder(tokenAddr base.Address, holder *holderBalance, bn base.Blknum, errorChan chan error) {
+	bal, err := opts.Conn.GetTokenBalanceAt(tokenAddr, holder.Holder, fmt.Sprintf("0x%x", bn))
+	if bal == nil {
+		errorChan <- err
+	} else if bal.Cmp(&holder.Balance) != 0 {
+		errorChan <- fmt.Errorf("the rebuilt balance of %s (%s) does not match its on-chain balance (%s) at block %d", holder.Holder.Hex(), holder.Balance.String(), bal.String(), bn)
+	}
+}
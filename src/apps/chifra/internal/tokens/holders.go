@@ -0,0 +1,72 @@
+package tokensPkg
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/articulate"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// holderBalance carries a holder's balance as rebuilt from the token's Transfer events
+type holderBalance struct {
+	Holder  base.Address
+	Balance big.Int
+}
+
+// holderMap rebuilds the balances of an ERC20 token's holders by replaying its Transfer events
+type holderMap struct {
+	token    base.Address
+	balances map[base.Address]*big.Int
+}
+
+func newHolderMap(token base.Address) *holderMap {
+	return &holderMap{
+		token:    token,
+		balances: make(map[base.Address]*big.Int),
+	}
+}
+
+// apply applies a single log to the balances if it is an ERC20 Transfer emitted by the token. ERC721
+// Transfers (which carry the token id as a fourth topic) are ignored. Mints (from the zero address)
+// and burns (to the zero address) change only one side of the ledger.
+func (h *holderMap) apply(log *types.SimpleLog) bool {
+	if log.Address != h.token || len(log.Topics) != 3 || log.Topics[0] != articulate.TransferTopic {
+		return false
+	}
+
+	value := base.HexToWei(log.Data)
+	if from := base.HexToAddress(log.Topics[1].Hex()); !from.IsZero() {
+		h.balanceOf(from).Sub(h.balanceOf(from), value)
+	}
+	if to := base.HexToAddress(log.Topics[2].Hex()); !to.IsZero() {
+		h.balanceOf(to).Add(h.balanceOf(to), value)
+	}
+	return true
+}
+
+func (h *holderMap) balanceOf(holder base.Address) *big.Int {
+	if h.balances[holder] == nil {
+		h.balances[holder] = new(big.Int)
+	}
+	return h.balances[holder]
+}
+
+// ranked returns the holders with a positive balance sorted by balance, largest first. Holders with
+// the same balance are sorted by address so the results are stable.
+func (h *holderMap) ranked() []holderBalance {
+	ret := make([]holderBalance, 0, len(h.balances))
+	for holder, balance := range h.balances {
+		if balance.Sign() > 0 {
+			ret = append(ret, holderBalance{Holder: holder, Balance: *new(big.Int).Set(balance)})
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if c := ret[i].Balance.Cmp(&ret[j].Balance); c != 0 {
+			return c > 0
+		}
+		return ret[i].Holder.Hex() < ret[j].Holder.Hex()
+	})
+	return ret
+}
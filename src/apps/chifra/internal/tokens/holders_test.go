@@ -0,0 +1,64 @@
+package tokensPkg
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/articulate"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func TestHolderMap(t *testing.T) {
+	token := base.HexToAddress("0x6b175474e89094c44da98b954eedeac495271d0f")
+	alice := base.HexToAddress("0xf503017d7baf7fbc0fff7492b751025c6a78179b")
+	bob := base.HexToAddress("0x054993ab0f2b1acc0fdc65405ee203b4271bebe6")
+	carol := base.HexToAddress("0x1db3439a222c519ab44bb1144fc28167b4fa6ee6")
+
+	transfer := func(from, to base.Address, value int64) types.SimpleLog {
+		return types.SimpleLog{
+			Address: token,
+			Topics: []base.Hash{
+				articulate.TransferTopic,
+				base.HexToHash(from.Hex()),
+				base.HexToHash(to.Hex()),
+			},
+			Data: fmt.Sprintf("0x%064x", value),
+		}
+	}
+
+	nft := transfer(base.ZeroAddr, carol, 0)
+	nft.Topics = append(nft.Topics, base.HexToHash("0x1"))
+	other := transfer(base.ZeroAddr, carol, 500)
+	other.Address = alice
+
+	holders := newHolderMap(token)
+	logs := []types.SimpleLog{
+		transfer(base.ZeroAddr, alice, 1000), // mint
+		transfer(alice, bob, 300),
+		transfer(alice, carol, 300),
+		transfer(carol, base.ZeroAddr, 300), // burn
+		nft,
+		other,
+	}
+	applied := 0
+	for i := range logs {
+		if holders.apply(&logs[i]) {
+			applied++
+		}
+	}
+	if applied != 4 {
+		t.Error("expected four transfers to be applied, got", applied)
+	}
+
+	ranked := holders.ranked()
+	if len(ranked) != 2 {
+		t.Fatal("expected two holders with a positive balance, got", len(ranked))
+	}
+	if ranked[0].Holder != alice || ranked[0].Balance.Int64() != 400 {
+		t.Error("wrong first holder", ranked[0].Holder.Hex(), ranked[0].Balance.String())
+	}
+	if ranked[1].Holder != bob || ranked[1].Balance.Int64() != 300 {
+		t.Error("wrong second holder", ranked[1].Holder.Hex(), ranked[1].Balance.String())
+	}
+}
@@ -30,6 +30,7 @@ type TokensOptions struct {
 	ByAcct   bool                     `json:"byAcct,omitempty"`   // Consider each address an ERC20 token except the last, whose balance is reported for each token
 	Changes  bool                     `json:"changes,omitempty"`  // Only report a balance when it changes from one block to the next
 	NoZero   bool                     `json:"noZero,omitempty"`   // Suppress the display of zero balance accounts
+	Holders  bool                     `json:"holders,omitempty"`  // Rebuild the holders of the ERC20 token and their balances at the given block(s) from its Transfer events
 	Globals  globals.GlobalOptions    `json:"globals,omitempty"`  // The global options
 	Conn     *rpc.Connection          `json:"conn,omitempty"`     // The connection to the RPC server
 	BadFlag  error                    `json:"badFlag,omitempty"`  // An error flag if needed
@@ -47,6 +48,7 @@ func (opts *TokensOptions) testLog() {
 	logger.TestLog(opts.ByAcct, "ByAcct: ", opts.ByAcct)
 	logger.TestLog(opts.Changes, "Changes: ", opts.Changes)
 	logger.TestLog(opts.NoZero, "NoZero: ", opts.NoZero)
+	logger.TestLog(opts.Holders, "Holders: ", opts.Holders)
 	opts.Conn.TestLog(opts.getCaches())
 	opts.Globals.TestLog()
 }
@@ -84,6 +86,8 @@ func tokensFinishParseApi(w http.ResponseWriter, r *http.Request) *TokensOptions
 			opts.Changes = true
 		case "noZero":
 			opts.NoZero = true
+		case "holders":
+			opts.Holders = true
 		default:
 			if !copy.Globals.Caps.HasKey(key) {
 				opts.BadFlag = validate.Usage("Invalid key ({0}) in {1} route.", key, "tokens")
@@ -93,7 +97,7 @@ func tokensFinishParseApi(w http.ResponseWriter, r *http.Request) *TokensOptions
 	opts.Conn = opts.Globals.FinishParseApi(w, r, opts.getCaches())
 
 	// EXISTING_CODE
-	if len(opts.Addrs) == 1 && len(opts.Parts) == 0 {
+	if len(opts.Addrs) == 1 && len(opts.Parts) == 0 && !opts.Holders {
 		opts.Parts = append(opts.Parts, "all")
 	}
 	if len(opts.Blocks) == 0 {
@@ -147,7 +151,7 @@ func tokensFinishParse(args []string) *TokensOptions {
 			}
 		}
 	}
-	if len(opts.Addrs) == 1 && len(opts.Parts) == 0 {
+	if len(opts.Addrs) == 1 && len(opts.Parts) == 0 && !opts.Holders {
 		opts.Parts = append(opts.Parts, "all")
 	}
 	if len(opts.Blocks) == 0 {
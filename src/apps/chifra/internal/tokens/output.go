@@ -60,6 +60,8 @@ func (opts *TokensOptions) TokensInternal() (err error, handled bool) {
 	handled = true
 	if opts.Globals.Decache {
 		err = opts.HandleDecache()
+	} else if opts.Holders {
+		err = opts.HandleHolders()
 	} else if len(opts.Parts) > 0 {
 		err = opts.HandleParts()
 	} else {
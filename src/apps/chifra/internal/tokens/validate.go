@@ -35,6 +35,18 @@ func (opts *TokensOptions) validateTokens() error {
 		return validate.Usage("The {0} is not yet implemented.", "--changes")
 	}
 
+	if opts.Holders {
+		if opts.ByAcct {
+			return validate.Usage("Please choose only one of {0}.", "--holders or --by_acct")
+		}
+		if len(opts.Parts) > 0 {
+			return validate.Usage("Please choose only one of {0}.", "--holders or --parts")
+		}
+		if len(opts.Addrs) != 1 {
+			return validate.Usage("The {0} option requires {1}.", "--holders", "exactly one token address")
+		}
+	}
+
 	if err != nil {
 		if invalidLiteral, ok := err.(*validate.InvalidIdentifierLiteralError); ok {
 			return invalidLiteral
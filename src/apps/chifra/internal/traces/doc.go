@@ -6,5 +6,5 @@
  * the code inside of 'EXISTING_CODE' tags.
  */
 
-// Package tracesPkg handles the chifra traces command. It The  tool retrieves a transaction's traces. You may specify multiple transaction identifiers per invocation. The --articulate option fetches the ABI from each encountered smart contract to better describe the reported data. The --filter option calls your node's trace_filter routine (if available) using a bang-separated string of the same values used by trace_fitler. 
+// Package tracesPkg handles the chifra traces command. It The  tool retrieves a transaction's traces. You may specify multiple transaction identifiers per invocation. The --articulate option fetches the ABI from each encountered smart contract to better describe the reported data. The --filter option calls your node's trace_filter routine (if available) using a bang-separated string of the same values used by trace_fitler. Traces come from your node's trace_block and trace_transaction routines (Erigon, Nethermind, Reth and others) or, if the node does not have them, from geth's debug_traceBlockByNumber and debug_traceTransaction routines using the callTracer. Either way, the traces are reported in the same format. The --filter option is not available with geth. 
 package tracesPkg
@@ -70,7 +70,7 @@ func (opts *WhenOptions) checkOneBlock(scanBar *progress.ScanBar, prev *types.Si
 
 	// This just simplifies the code below by removing the need to type cast
 	onDisc := types.SimpleNamedBlock{
-		BlockNumber: uint64(itemOnDisc.Bn),
+		BlockNumber: itemOnDisc.Bn,
 		Timestamp:   base.Timestamp(itemOnDisc.Ts),
 	}
 
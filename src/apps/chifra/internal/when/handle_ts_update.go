@@ -40,7 +40,7 @@ func (opts *WhenOptions) HandleTimestampUpdate() error {
 	logger.Info("Updating timestamps file from", cnt, "to", meta.Latest, fmt.Sprintf("(%d blocks)", (meta.Latest-cnt)))
 	for bn := cnt; bn < meta.Latest; bn++ {
 		block, _ := opts.Conn.GetBlockHeaderByNumber(bn)
-		record := tslib.TimestampRecord{Bn: uint32(block.BlockNumber), Ts: uint32(block.Timestamp)}
+		record := tslib.TimestampRecord{Bn: uint64(block.BlockNumber), Ts: uint32(block.Timestamp)}
 		timestamps = append(timestamps, record)
 		logger.Progress(true, "Adding block", bn, "to timestamp array")
 		if bn%1000 == 0 {
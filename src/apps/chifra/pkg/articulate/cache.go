@@ -10,6 +10,7 @@ type AbiCache struct {
 	AbiMap    abi.FunctionSyncMap
 	loadedMap abi.AddressSyncMap
 	skipMap   abi.AddressSyncMap
+	fourBytes *fourBytesDb
 }
 
 func NewAbiCache(chain string, loadKnown bool) *AbiCache {
@@ -18,6 +19,7 @@ func NewAbiCache(chain string, loadKnown bool) *AbiCache {
 		AbiMap:    abi.FunctionSyncMap{},
 		loadedMap: abi.AddressSyncMap{},
 		skipMap:   abi.AddressSyncMap{},
+		fourBytes: getFourBytesDb(),
 	}
 
 	if loadKnown {
@@ -11,6 +11,14 @@ import (
 var TransferTopic = base.HexToHash(
 	"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
 )
+
+// TransferSingleTopic and TransferBatchTopic are the ERC-1155 transfer events
+var TransferSingleTopic = base.HexToHash(
+	"0xc3d58168c5ae7397731d063d5bbf3d657854427343f4c083240f7aacaa2d0f62",
+)
+var TransferBatchTopic = base.HexToHash(
+	"0x4a39dc06d4c0dbc64b70af90fd698a233a518aa5d07e595d983b8c0526c8f7fb",
+)
 var ensTransferTopic = base.HexToHash(
 	"0xd4735d920b0f87494915f556dd9b54c8f309026070caea5c737245152564d266",
 )
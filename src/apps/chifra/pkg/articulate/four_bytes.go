@@ -0,0 +1,298 @@
+package articulate
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	goEthAbi "github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// The four-byte database is built by src/other/four_bytes. It holds the keccak encodings of
+// function and event signatures chunked into files by the first two bytes of the encoding
+// (so 0xa9059cbb... is found in ./a9/a905). Each chunk consists of a header, a table of
+// records, and a table of the (tightly packed) signature strings the records point to.
+
+// fourBytesHeader is the header of a four-byte database chunk
+type fourBytesHeader struct {
+	Magic          uint32
+	Hash           base.Hash
+	SignatureCount uint32
+}
+
+// fourBytesRecord is a single record in a four-byte database chunk
+type fourBytesRecord struct {
+	Encoding base.Hash
+	Offset   uint32
+	Len      uint32
+}
+
+// fourBytesSignature is an encoding along with the signature it was derived from
+type fourBytesSignature struct {
+	encoding  base.Hash
+	signature string
+}
+
+// fourBytesDb reads signatures from a four-byte database, keeping the chunks it reads in memory
+type fourBytesDb struct {
+	path   string
+	mutex  sync.Mutex
+	chunks map[string][]fourBytesSignature
+}
+
+var fourBytesOnce sync.Once
+var fourBytes *fourBytesDb
+
+// getFourBytesDb returns the four-byte database or nil if it has not been built
+func getFourBytesDb() *fourBytesDb {
+	fourBytesOnce.Do(func() {
+		path := filepath.Join(config.PathToRootConfig(), "abis", "four_bytes")
+		if file.FolderExists(path) {
+			fourBytes = newFourBytesDb(path)
+		}
+	})
+	return fourBytes
+}
+
+func newFourBytesDb(path string) *fourBytesDb {
+	return &fourBytesDb{
+		path:   path,
+		chunks: map[string][]fourBytesSignature{},
+	}
+}
+
+// lookup returns the (sorted) signatures whose encoding starts with the given bytes
+func (db *fourBytesDb) lookup(encoding []byte) []string {
+	if db == nil || len(encoding) < 2 {
+		return nil
+	}
+
+	key := hex.EncodeToString(encoding[:2])
+	db.mutex.Lock()
+	chunk, ok := db.chunks[key]
+	if !ok {
+		// A missing or unreadable chunk is treated as empty so we don't try to read it again
+		chunk, _ = readFourBytesChunk(filepath.Join(db.path, key[:2], key))
+		db.chunks[key] = chunk
+	}
+	db.mutex.Unlock()
+
+	ret := []string{}
+	for _, sig := range chunk {
+		if bytes.HasPrefix(sig.encoding.Bytes(), encoding) {
+			ret = append(ret, sig.signature)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// readFourBytesChunk reads all the signatures in a chunk file
+func readFourBytesChunk(path string) ([]fourBytesSignature, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readFourBytes(f)
+}
+
+func readFourBytes(in io.Reader) ([]fourBytesSignature, error) {
+	header := fourBytesHeader{}
+	if err := binary.Read(in, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+
+	records := make([]fourBytesRecord, header.SignatureCount)
+	if err := binary.Read(in, binary.LittleEndian, records); err != nil {
+		return nil, err
+	}
+
+	strs, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]fourBytesSignature, 0, len(records))
+	for _, record := range records {
+		end := uint64(record.Offset) + uint64(record.Len)
+		if end > uint64(len(strs)) {
+			return nil, fmt.Errorf("invalid four-byte record at offset %d", record.Offset)
+		}
+		ret = append(ret, fourBytesSignature{
+			encoding:  record.Encoding,
+			signature: string(strs[record.Offset:end]),
+		})
+	}
+	return ret, nil
+}
+
+// findFunction returns a function from the four-byte database whose signature hashes to the
+// input's selector and whose parameters decode the input's data. If several signatures do, the
+// first is returned and the others are listed in its Message.
+func (db *fourBytesDb) findFunction(input string) *types.SimpleFunction {
+	if db == nil || len(input) < 10 {
+		return nil
+	}
+	selector, err := hex.DecodeString(input[2:10])
+	if err != nil {
+		return nil
+	}
+	data, err := hex.DecodeString(input[10:])
+	if err != nil {
+		return nil
+	}
+
+	matches := []*types.SimpleFunction{}
+	for _, sig := range db.lookup(selector) {
+		name, args, err := parseSignature(sig)
+		if err != nil || !decodes(args, data) {
+			continue
+		}
+		method := goEthAbi.NewMethod(name, name, goEthAbi.Function, "", false, false, args, nil)
+		if !bytes.Equal(method.ID, selector) {
+			continue
+		}
+		matches = append(matches, types.FunctionFromAbiMethod(&method))
+	}
+	return firstOfMatches(matches)
+}
+
+// findEvent returns an event from the four-byte database whose encoding matches the log's first
+// topic and whose parameters decode the log. The leading parameters are taken to be the indexed
+// ones. If several signatures decode the log, the first is returned and the others are listed
+// in its Message.
+func (db *fourBytesDb) findEvent(log *types.SimpleLog) *types.SimpleFunction {
+	if db == nil || len(log.Topics) < 1 {
+		return nil
+	}
+	data, err := hex.DecodeString(strings.TrimPrefix(log.Data, "0x"))
+	if err != nil {
+		return nil
+	}
+
+	nIndexed := len(log.Topics) - 1
+	matches := []*types.SimpleFunction{}
+	for _, sig := range db.lookup(log.Topics[0].Bytes()) {
+		name, args, err := parseSignature(sig)
+		if err != nil || len(args) < nIndexed {
+			continue
+		}
+		for i := 0; i < nIndexed; i++ {
+			args[i].Indexed = true
+		}
+		if !decodes(args.NonIndexed(), data) {
+			continue
+		}
+		event := goEthAbi.NewEvent(name, name, false, args)
+		if event.ID != log.Topics[0].Common() {
+			continue
+		}
+		matches = append(matches, types.FunctionFromAbiEvent(&event))
+	}
+	return firstOfMatches(matches)
+}
+
+func firstOfMatches(matches []*types.SimpleFunction) *types.SimpleFunction {
+	if len(matches) == 0 {
+		return nil
+	}
+	if len(matches) > 1 {
+		others := make([]string, 0, len(matches)-1)
+		for _, match := range matches[1:] {
+			others = append(others, match.Signature)
+		}
+		matches[0].Message = "ambiguous, also matches " + strings.Join(others, ", ")
+	}
+	return matches[0]
+}
+
+// decodes returns true if the data is exactly the encoding of some values of the arguments' types
+func decodes(args goEthAbi.Arguments, data []byte) bool {
+	values, err := args.UnpackValues(data)
+	if err != nil {
+		return false
+	}
+	packed, err := args.Pack(values...)
+	return err == nil && bytes.Equal(packed, data)
+}
+
+// parseSignature splits a canonical signature such as `transfer(address,uint256)` into its name
+// and arguments (named val_0, val_1, and so on)
+func parseSignature(sig string) (name string, args goEthAbi.Arguments, err error) {
+	open := strings.Index(sig, "(")
+	if open < 1 || !strings.HasSuffix(sig, ")") {
+		return "", nil, fmt.Errorf("invalid signature %s", sig)
+	}
+
+	name = sig[:open]
+	for index, t := range splitTypes(sig[open+1 : len(sig)-1]) {
+		marshaling, err := toMarshaling(t, "val_"+fmt.Sprint(index))
+		if err != nil {
+			return "", nil, err
+		}
+		argType, err := goEthAbi.NewType(marshaling.Type, "", marshaling.Components)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, goEthAbi.Argument{Name: marshaling.Name, Type: argType})
+	}
+	return name, args, nil
+}
+
+// toMarshaling converts a canonical type (tuples written as `(type,type)`) into the form
+// go-ethereum builds types from
+func toMarshaling(t, name string) (goEthAbi.ArgumentMarshaling, error) {
+	if !strings.HasPrefix(t, "(") {
+		return goEthAbi.ArgumentMarshaling{Name: name, Type: t}, nil
+	}
+
+	end := strings.LastIndex(t, ")")
+	if end < 0 {
+		return goEthAbi.ArgumentMarshaling{}, fmt.Errorf("invalid tuple %s", t)
+	}
+	ret := goEthAbi.ArgumentMarshaling{Name: name, Type: "tuple" + t[end+1:]}
+	for index, component := range splitTypes(t[1:end]) {
+		marshaling, err := toMarshaling(component, "val_"+fmt.Sprint(index))
+		if err != nil {
+			return goEthAbi.ArgumentMarshaling{}, err
+		}
+		ret.Components = append(ret.Components, marshaling)
+	}
+	return ret, nil
+}
+
+// splitTypes splits a comma separated list of types, leaving the commas inside tuples alone
+func splitTypes(list string) []string {
+	if len(list) == 0 {
+		return nil
+	}
+
+	ret := []string{}
+	depth, start := 0, 0
+	for i, c := range list {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				ret = append(ret, list[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(ret, list[start:])
+}
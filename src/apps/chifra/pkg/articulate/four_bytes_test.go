@@ -0,0 +1,128 @@
+package articulate
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/abi"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// writeFourBytesChunk writes a chunk in the format produced by src/other/four_bytes
+func writeFourBytesChunk(t *testing.T, dir string, encoding []byte, signatures []string) {
+	t.Helper()
+	var buf bytes.Buffer
+	header := fourBytesHeader{Magic: 0xdeadbeef, SignatureCount: uint32(len(signatures))}
+	_ = binary.Write(&buf, binary.LittleEndian, header)
+	offset := uint32(0)
+	for _, sig := range signatures {
+		record := fourBytesRecord{Encoding: base.BytesToHash(encoding), Offset: offset, Len: uint32(len(sig))}
+		_ = binary.Write(&buf, binary.LittleEndian, record)
+		offset += uint32(len(sig))
+	}
+	for _, sig := range signatures {
+		buf.WriteString(sig)
+	}
+
+	key := hex.EncodeToString(encoding[:2])
+	if err := os.MkdirAll(filepath.Join(dir, key[:2]), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, key[:2], key), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFourBytesFunction(t *testing.T) {
+	dir := t.TempDir()
+	// Only the first signature hashes to the selector and decodes the data. The second can't decode
+	// it, and the last decodes it but does not hash to the selector.
+	writeFourBytesChunk(t, dir, crypto.Keccak256([]byte("transfer(address,uint256)")), []string{
+		"transfer(address,uint256)",
+		"many_msg_babbage(bytes1)",
+		"give(address,uint256)",
+	})
+	db := newFourBytesDb(dir)
+
+	input := "0xa9059cbb" +
+		"000000000000000000000000f503017d7baf7fbc0fff7492b751025c6a78179b" +
+		"00000000000000000000000000000000000000000000000000000000000003e8"
+	found := db.findFunction(input)
+	if found == nil {
+		t.Fatal("expected to find the function")
+	}
+	if found.Signature != "transfer(address,uint256)" || found.Message != "" {
+		t.Error("wrong function", found.Signature, found.Message)
+	}
+
+	abiCache := &AbiCache{}
+	if err := abiCache.ArticulateFunction(found, input[10:], ""); err != nil {
+		t.Fatal(err)
+	}
+	if found.Inputs[0].Value != "0xf503017d7baf7fbc0fff7492b751025c6a78179b" || found.Inputs[1].Value != "1000" {
+		t.Error("wrong values", found.Inputs)
+	}
+
+	if found = db.findFunction("0x12345678"); found != nil {
+		t.Error("expected a missing chunk to find nothing", found)
+	}
+}
+
+func TestFourBytesEvent(t *testing.T) {
+	dir := t.TempDir()
+	topic := crypto.Keccak256([]byte("Deposit(address,uint256)"))
+	writeFourBytesChunk(t, dir, topic, []string{
+		"Deposit(address,uint256)",
+	})
+	db := newFourBytesDb(dir)
+
+	log := types.SimpleLog{
+		Topics: []base.Hash{
+			base.BytesToHash(topic),
+			base.HexToHash("0x000000000000000000000000f503017d7baf7fbc0fff7492b751025c6a78179b"),
+		},
+		Data: "0x00000000000000000000000000000000000000000000000000000000000003e8",
+	}
+	articulated, err := articulateLog(&log, &abi.FunctionSyncMap{}, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if articulated == nil || articulated.Name != "Deposit" || articulated.Message != "" {
+		t.Fatal("expected to find the event", articulated)
+	}
+	if !articulated.Inputs[0].Indexed || articulated.Inputs[1].Indexed {
+		t.Error("expected the leading parameter to be indexed", articulated.Inputs)
+	}
+	if articulated.Inputs[0].Value != "0xf503017d7baf7fbc0fff7492b751025c6a78179b" || articulated.Inputs[1].Value != "1000" {
+		t.Error("wrong values", articulated.Inputs)
+	}
+
+	log.Topics = log.Topics[:1]
+	log.Data = "0x"
+	if articulated = db.findEvent(&log); articulated != nil {
+		t.Error("expected a log with too little data not to decode", articulated)
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	name, args, err := parseSignature("swap((uint256,address)[],bool,bytes)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "swap" || len(args) != 3 {
+		t.Fatal("wrong parse", name, args)
+	}
+	if args[0].Type.String() != "(uint256,address)[]" || args[1].Type.String() != "bool" || args[2].Name != "val_2" {
+		t.Error("wrong arguments", args)
+	}
+
+	if _, _, err = parseSignature("noParens"); err == nil {
+		t.Error("expected an error for an invalid signature")
+	}
+}
@@ -19,7 +19,7 @@ func (abiCache *AbiCache) ArticulateLog(log *types.SimpleLog) (err error) {
 	}
 
 	if !abiCache.skipMap.GetValue(address) {
-		if log.ArticulatedLog, err = articulateLog(log, &abiCache.AbiMap); err != nil {
+		if log.ArticulatedLog, err = articulateLog(log, &abiCache.AbiMap, abiCache.fourBytes); err != nil {
 			return err
 		}
 	}
@@ -27,7 +27,7 @@ func (abiCache *AbiCache) ArticulateLog(log *types.SimpleLog) (err error) {
 	return nil
 }
 
-func articulateLog(log *types.SimpleLog, abiMap *abi.FunctionSyncMap) (articulated *types.SimpleFunction, err error) {
+func articulateLog(log *types.SimpleLog, abiMap *abi.FunctionSyncMap, fourBytes *fourBytesDb) (articulated *types.SimpleFunction, err error) {
 	if len(log.Topics) < 1 {
 		return
 	}
@@ -35,12 +35,12 @@ func articulateLog(log *types.SimpleLog, abiMap *abi.FunctionSyncMap) (articulat
 	// Try to articulate the log using some common events
 	articulated = findCommonEvent(log)
 
-	// If we couldn't, then try to find the event in `abiMap`
+	// If we couldn't, then try to find the event in `abiMap` and then in the four-byte database
 	if articulated == nil {
 		selector := "0x" + hex.EncodeToString(log.Topics[0].Bytes())
 		if found := abiMap.GetValue(selector); found != nil {
 			articulated = found.Clone()
-		} else {
+		} else if articulated = fourBytes.findEvent(log); articulated == nil {
 			// If articulated is still nil, we don't have ABI for this event
 			return
 		}
@@ -18,7 +18,7 @@ func (abiCache *AbiCache) ArticulateTrace(trace *types.SimpleTrace) (err error)
 	}
 
 	if !abiCache.skipMap.GetValue(address) {
-		if trace.ArticulatedTrace, err = articulateTrace(trace, &abiCache.AbiMap); err != nil {
+		if trace.ArticulatedTrace, err = articulateTrace(trace, &abiCache.AbiMap, abiCache.fourBytes); err != nil {
 			return err
 		}
 	}
@@ -26,7 +26,7 @@ func (abiCache *AbiCache) ArticulateTrace(trace *types.SimpleTrace) (err error)
 	return nil
 }
 
-func articulateTrace(trace *types.SimpleTrace, abiMap *abi.FunctionSyncMap) (articulated *types.SimpleFunction, err error) {
+func articulateTrace(trace *types.SimpleTrace, abiMap *abi.FunctionSyncMap, fourBytes *fourBytesDb) (articulated *types.SimpleFunction, err error) {
 	input := trace.Action.Input
 	if len(input) < 10 {
 		return
@@ -34,6 +34,9 @@ func articulateTrace(trace *types.SimpleTrace, abiMap *abi.FunctionSyncMap) (art
 
 	encoding := input[:10]
 	articulated = abiMap.GetValue(encoding)
+	if articulated == nil {
+		articulated = fourBytes.findFunction(input)
+	}
 
 	if trace.Result == nil || articulated == nil {
 		return
@@ -37,6 +37,9 @@ func (abiCache *AbiCache) ArticulateTransaction(tx *types.SimpleTransaction) (er
 		selector = tx.Input[:10]
 		inputData := tx.Input[10:]
 		found = abiCache.AbiMap.GetValue(selector)
+		if found == nil {
+			found = abiCache.fourBytes.findFunction(tx.Input)
+		}
 		if found != nil {
 			tx.ArticulatedTx = found.Clone()
 			var outputData string
@@ -13,6 +13,7 @@ type StoreLocation uint
 const (
 	FsCache StoreLocation = iota
 	MemoryCache
+	BoltCache
 )
 
 // NoCache indicates that we are not caching or reading from the cache
@@ -60,24 +61,18 @@ type StoreOptions struct {
 	RootDir string
 	// If ReadOnly is true, then we will not write to the cache
 	ReadOnly bool
+	// If Limit is not zero, the store evicts its least recently used items
+	// to stay under Limit bytes
+	Limit uint64
 }
 
-func (s *StoreOptions) location() (loc Storer, err error) {
+func (s *StoreOptions) location(rootDir string) (loc Storer, err error) {
 	if s == nil {
 		// TODO: s can never be nil, we would have cored already
 		logger.Fatal("Implementation error in location.")
 		return
 	}
-	switch s.Location {
-	case MemoryCache:
-		loc, err = locations.Memory()
-	case FsCache:
-		fallthrough
-	default:
-		loc, err = locations.FileSystem()
-	}
-
-	return
+	return newStorer(s.Location, rootDir)
 }
 
 func (s *StoreOptions) rootDir() (dir string) {
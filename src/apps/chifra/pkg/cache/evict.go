@@ -0,0 +1,279 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Toucher is implemented by Storers that can record when an item was last used. Without
+// it, eviction falls back to the time each item was written.
+type Toucher interface {
+	Touch(path string) error
+}
+
+// evictionWeights makes some kinds of items stay in the cache longer than others. An item's
+// age is divided by its weight before items are compared, so an item with weight 4 is evicted
+// only after it has been unused four times as long as an item with weight 1. Statements are
+// expensive to rebuild (they require traces, prices, and previous balances), so they're kept
+// the longest.
+var evictionWeights = map[string]float64{
+	"statements": 4,
+	"states":     2,
+}
+
+// pruneTarget is the fraction of the limit to which the cache is reduced once it's exceeded.
+// Evicting past the limit means we don't walk the cache again after every write.
+const pruneTarget = 0.9
+
+// background tracks the usage computations and evictions running in the background so that
+// CloseStores can wait for them
+var background sync.WaitGroup
+
+// closeTimeout is how long CloseStores waits for the work running in the background before
+// stopping it. Stopped work is started again (from the beginning) by the next write.
+var closeTimeout = 2 * time.Second
+
+// stopping is set while CloseStores stops the work running in the background
+var stopping atomic.Bool
+
+// errStopped is returned by a walk of the store that was stopped by CloseStores
+var errStopped = errors.New("stopped by CloseStores")
+
+// waitForBackground waits for the work running in the background, stopping it if it takes
+// longer than closeTimeout
+func waitForBackground() {
+	done := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(closeTimeout):
+		stopping.Store(true)
+		<-done
+		stopping.Store(false)
+	}
+}
+
+type evictionCandidate struct {
+	path  string
+	size  uint64
+	score float64
+}
+
+// Usage returns the number of bytes currently held in the store
+func (s *Store) Usage() (uint64, error) {
+	walker, ok := s.location.(Walker)
+	if !ok {
+		return 0, errors.New("the items in this cache store cannot be enumerated")
+	}
+
+	total := uint64(0)
+	err := walker.Walk(s.rootDir, func(path string) error {
+		if stopping.Load() {
+			return errStopped
+		}
+		if info, err := s.location.Stat(path); err == nil {
+			total += uint64(info.Size())
+		}
+		return nil
+	})
+	return total, err
+}
+
+// Evict removes the least recently used items from the store until it holds no more than
+// target bytes. Items are ranked by how long it's been since they were last used divided by
+// the weight of their kind. Returns the number of items removed and the bytes freed.
+func (s *Store) Evict(target uint64) (nRemoved int, freed uint64, err error) {
+	nRemoved, freed, _, err = s.evict(target)
+	return
+}
+
+// evict does the work of Evict and also returns the number of bytes remaining in the store
+func (s *Store) evict(target uint64) (nRemoved int, freed, remaining uint64, err error) {
+	walker, ok := s.location.(Walker)
+	if !ok {
+		return 0, 0, 0, errors.New("the items in this cache store cannot be enumerated")
+	}
+
+	now := time.Now()
+	total := uint64(0)
+	candidates := []evictionCandidate{}
+	err = walker.Walk(s.rootDir, func(path string) error {
+		if stopping.Load() {
+			return errStopped
+		}
+		info, err := s.location.Stat(path)
+		if err != nil {
+			return nil
+		}
+		total += uint64(info.Size())
+		candidates = append(candidates, evictionCandidate{
+			path:  path,
+			size:  uint64(info.Size()),
+			score: now.Sub(info.LastAccess()).Seconds() / s.weight(path),
+		})
+		return nil
+	})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	for _, c := range candidates {
+		if total <= target {
+			break
+		}
+		if stopping.Load() {
+			return nRemoved, freed, total, errStopped
+		}
+		if err = s.location.Remove(c.path); err != nil {
+			return nRemoved, freed, total, fmt.Errorf("could not evict %s: %w", c.path, err)
+		}
+		total -= c.size
+		freed += c.size
+		nRemoved++
+	}
+
+	return nRemoved, freed, total, nil
+}
+
+// weight returns the eviction weight of the item at path based on the folder it's stored in
+func (s *Store) weight(path string) float64 {
+	rel, err := filepath.Rel(s.rootDir, path)
+	if err != nil {
+		return 1
+	}
+	kind := strings.Split(filepath.ToSlash(rel), "/")[0]
+	if w, ok := evictionWeights[kind]; ok {
+		return w
+	}
+	return 1
+}
+
+// touch records that the item at path was used, if the store is size-limited, writable,
+// and the Storer supports it
+func (s *Store) touch(path string) {
+	if s.limit == 0 || s.readOnly {
+		return
+	}
+	if toucher, ok := s.location.(Toucher); ok {
+		if err := toucher.Touch(path); err != nil {
+			printErr("touching", err)
+		}
+	}
+}
+
+// enforceLimit adds the change in size caused by a write to the store's usage and, if that
+// pushes the store past its limit, evicts items until it's back under the limit. Neither
+// blocks the write: the usage is computed by walking the store in the background after the
+// first write of each process (the changes made in the meantime are added once it's known)
+// and items are evicted in the background as well. CloseStores waits for both to finish (or
+// stops them).
+func (s *Store) enforceLimit(delta int64) {
+	if s.limit == 0 {
+		return
+	}
+
+	s.usageMutex.Lock()
+	defer s.usageMutex.Unlock()
+
+	switch {
+	case !s.usageKnown && !s.usagePending:
+		// The walk sees this write, so its change in size is not added again
+		s.usagePending = true
+		background.Add(1)
+		go s.computeUsage()
+		return
+	case !s.usageKnown || s.pruning:
+		// The usage is being computed or corrected, so the change is added once that's done
+		s.pendingDelta += delta
+		return
+	}
+
+	s.addUsage(delta)
+	s.pruneIfNeeded()
+}
+
+// computeUsage walks the store to find its usage, then adds the changes made by the writes
+// that happened during the walk. Some of those may already have been seen by the walk, so
+// the usage may be overstated until the next eviction corrects it. Evictions do the same
+// with the writes that happen while they run.
+func (s *Store) computeUsage() {
+	defer background.Done()
+
+	usage, err := s.Usage()
+
+	s.usageMutex.Lock()
+	defer s.usageMutex.Unlock()
+
+	s.usagePending = false
+	if errors.Is(err, errStopped) {
+		// The next write starts the walk again
+		return
+	}
+	if err != nil {
+		printErr("computing usage", err)
+		s.limit = 0 // we can't enforce the limit, so we stop trying
+		return
+	}
+	s.usage, s.usageKnown = usage, true
+	s.addUsage(s.pendingDelta)
+	s.pendingDelta = 0
+	s.pruneIfNeeded()
+}
+
+// addUsage adds delta to the usage. The mutex must be held.
+func (s *Store) addUsage(delta int64) {
+	if delta >= 0 || uint64(-delta) <= s.usage {
+		s.usage = uint64(int64(s.usage) + delta)
+	} else {
+		s.usage = 0
+	}
+}
+
+// pruneIfNeeded starts evicting items in the background if the store is past its limit
+// and is not already being pruned. The mutex must be held.
+func (s *Store) pruneIfNeeded() {
+	if s.usage <= s.limit || s.pruning {
+		return
+	}
+
+	s.pruning = true
+	background.Add(1)
+	go func() {
+		defer background.Done()
+
+		target := uint64(float64(s.limit) * pruneTarget)
+		_, _, remaining, err := s.evict(target)
+		stopped := errors.Is(err, errStopped)
+		if err != nil && !stopped {
+			printErr("evicting", err)
+		}
+
+		s.usageMutex.Lock()
+		defer s.usageMutex.Unlock()
+		s.usage, s.pruning = remaining, false
+		if stopped {
+			// The walk may not have been finished, so the next write finds the usage again
+			s.usageKnown, s.pendingDelta = false, 0
+			return
+		}
+		if s.pendingDelta != 0 {
+			s.addUsage(s.pendingDelta)
+			s.pendingDelta = 0
+			s.pruneIfNeeded()
+		}
+	}()
+}
@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// testEvictData is a cacheable item that may be stored in any top-level folder
+type testEvictData struct {
+	testStoreData
+	Kind string
+}
+
+func (t *testEvictData) CacheLocation() (string, string) {
+	return t.Kind, "bin"
+}
+
+func writeAged(t *testing.T, s *Store, kind, id string, age time.Duration) string {
+	value := &testEvictData{
+		testStoreData: testStoreData{Id: id, Value: strings.Repeat("x", 100)},
+		Kind:          kind,
+	}
+	if err := s.Write(value, nil); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := s.resolvePath(value)
+	if age > 0 {
+		when := time.Now().Add(-age)
+		if err := os.Chtimes(path, when, when); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestStoreEvict(t *testing.T) {
+	s, err := NewStore(&StoreOptions{
+		Location: FsCache,
+		RootDir:  t.TempDir(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldBlock := writeAged(t, s, "blocks", "1", 10*time.Hour)
+	newBlock := writeAged(t, s, "blocks", "2", 1*time.Hour)
+	// older than the old block, but weighted to stay longer
+	statement := writeAged(t, s, "statements", "3", 20*time.Hour)
+
+	total, err := s.Usage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	itemSize := total / 3
+
+	nRemoved, freed, err := s.Evict(total - itemSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nRemoved != 1 || freed != itemSize {
+		t.Error("expected one item to be evicted, got", nRemoved, freed)
+	}
+	if _, err := os.Stat(oldBlock); err == nil {
+		t.Error("expected the least recently used block to be evicted")
+	}
+	for _, path := range []string{newBlock, statement} {
+		if _, err := os.Stat(path); err != nil {
+			t.Error("expected", filepath.Base(path), "to remain in the cache")
+		}
+	}
+}
+
+func TestStoreLimit(t *testing.T) {
+	rootDir := t.TempDir()
+	unlimited, err := NewStore(&StoreOptions{Location: FsCache, RootDir: rootDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeAged(t, unlimited, "blocks", "1", time.Hour)
+	itemSize, _ := unlimited.Usage()
+
+	limited, err := NewStore(&StoreOptions{Location: FsCache, RootDir: rootDir, Limit: 3 * itemSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"2", "3", "4", "5"} {
+		writeAged(t, limited, "blocks", id, 0)
+	}
+	// Eviction happens in the background
+	if err := CloseStores(); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := limited.Usage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage > 3*itemSize {
+		t.Error("expected the store to stay under its limit, got", usage)
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, "blocks", "1.bin")); err == nil {
+		t.Error("expected the oldest item to be evicted")
+	}
+}
+
+func TestStoreBoltTouch(t *testing.T) {
+	rootDir := filepath.Join(t.TempDir(), "v1")
+	s, err := NewStore(&StoreOptions{Location: BoltCache, RootDir: rootDir, Limit: 1 << 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := &testEvictData{testStoreData: testStoreData{Id: "1", Value: "touched"}, Kind: "blocks"}
+	if err := s.Write(value, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := CloseStores(); err != nil {
+		t.Fatal(err)
+	}
+	written, err := s.Stat(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading the item does not lock out other readers, so it did not write to the database
+	time.Sleep(1100 * time.Millisecond)
+	if err := s.Read(&testEvictData{testStoreData: testStoreData{Id: "1"}, Kind: "blocks"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	other, err := bolt.Open(rootDir+".db", 0644, &bolt.Options{Timeout: 100 * time.Millisecond, ReadOnly: true})
+	if err != nil {
+		t.Fatal("reading an item wrote to the database:", err)
+	}
+	other.Close()
+
+	// The access time is stored when the store is closed
+	if err := CloseStores(); err != nil {
+		t.Fatal(err)
+	}
+	if touched, err := s.Stat(value); err != nil {
+		t.Fatal(err)
+	} else if !touched.LastAccess().After(written.LastAccess()) {
+		t.Error("expected the access time to be stored, got", touched.LastAccess(), "after", written.LastAccess())
+	}
+}
+
+func TestCloseStoresStopsBackground(t *testing.T) {
+	saved := closeTimeout
+	closeTimeout = 10 * time.Millisecond
+	defer func() { closeTimeout = saved }()
+
+	// A walk that never finishes on its own
+	started := make(chan struct{})
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		close(started)
+		for !stopping.Load() {
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	<-started
+
+	done := make(chan error)
+	go func() { done <- CloseStores() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("CloseStores did not stop the work running in the background")
+	}
+	if stopping.Load() {
+		t.Error("expected the next background work to be allowed to run")
+	}
+}
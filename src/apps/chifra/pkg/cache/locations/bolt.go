@@ -0,0 +1,369 @@
+package locations
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltBucket is the bucket in which all cache items are stored
+var boltBucket = []byte("items")
+
+// boltAccessBucket holds the last time each item was written or touched, keyed the same as boltBucket
+var boltAccessBucket = []byte("access")
+
+// Each Storer implementation is global and thread-safe to save resources
+// when reading/writing large number of items. Because each chain has its
+// own cache folder, there is one bolt database (and instance) per folder.
+var boltInstances = make(map[string]*boltStore)
+var boltMutex sync.Mutex
+
+// boltTimeout is how long we wait for another process to release the database
+const boltTimeout = 2 * time.Second
+
+// boltStore keeps every cache item in a single-file, embedded key-value store.
+// Keys are the item's path relative to the root of the cache. This avoids
+// exhausting inodes on file systems when the cache has millions of small items.
+//
+// The database is opened the first time it's used: read-only (which other readers
+// may share) until the first write, then read-write (which locks out every other
+// process). Close releases it so that other processes may open it.
+type boltStore struct {
+	rootDir  string
+	dbMutex  sync.RWMutex
+	db       *bolt.DB
+	writable bool
+	// touched holds the access times recorded by Touch that are not stored yet. They're
+	// stored by the next write (or by Close), so that reads never need a write transaction.
+	touched      map[string]time.Time
+	touchedMutex sync.Mutex
+}
+
+// boltWriteCloser buffers a cache item in memory and stores it in the database
+// when Close is called
+type boltWriteCloser struct {
+	bytes.Buffer
+	store *boltStore
+	key   []byte
+}
+
+func (w *boltWriteCloser) Close() error {
+	return w.store.update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(boltBucket).Put(w.key, w.Bytes()); err != nil {
+			return err
+		}
+		return putAccessTime(tx, w.key, time.Now())
+	})
+}
+
+// Bolt returns an instance of the bolt Storer for the cache rooted at rootDir. The
+// database lives next to the folder in a file named rootDir + ".db" and is not
+// opened until it's first used.
+func Bolt(rootDir string) (*boltStore, error) {
+	boltMutex.Lock()
+	defer boltMutex.Unlock()
+
+	rootDir = filepath.Clean(rootDir)
+	if instance, ok := boltInstances[rootDir]; ok {
+		return instance, nil
+	}
+
+	instance := &boltStore{
+		rootDir: rootDir,
+		touched: make(map[string]time.Time),
+	}
+	boltInstances[rootDir] = instance
+	return instance, nil
+}
+
+// path returns the path of the database file
+func (l *boltStore) path() string {
+	return l.rootDir + ".db"
+}
+
+// view runs fn in a read-only transaction. If the database does not exist yet, fn is
+// not called and there is nothing to read, so the store is left closed.
+func (l *boltStore) view(fn func(tx *bolt.Tx) error) error {
+	l.dbMutex.RLock()
+	if l.db != nil {
+		defer l.dbMutex.RUnlock()
+		return l.db.View(fn)
+	}
+	l.dbMutex.RUnlock()
+
+	l.dbMutex.Lock()
+	defer l.dbMutex.Unlock()
+	if l.db == nil {
+		if _, err := os.Stat(l.path()); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err := l.open(false); err != nil {
+			return err
+		}
+	}
+	return l.db.View(fn)
+}
+
+// update runs fn in a read-write transaction, reopening the database for writing first
+// if needed. The access times recorded since the last write are stored in the same
+// transaction.
+func (l *boltStore) update(fn func(tx *bolt.Tx) error) error {
+	withTouched := func(tx *bolt.Tx) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return l.putTouched(tx)
+	}
+
+	l.dbMutex.RLock()
+	if l.db != nil && l.writable {
+		defer l.dbMutex.RUnlock()
+		return l.db.Update(withTouched)
+	}
+	l.dbMutex.RUnlock()
+
+	l.dbMutex.Lock()
+	defer l.dbMutex.Unlock()
+	if l.db == nil || !l.writable {
+		if err := l.open(true); err != nil {
+			return err
+		}
+	}
+	return l.db.Update(withTouched)
+}
+
+// open (re)opens the database, read-write if writable is true. The mutex must be held.
+func (l *boltStore) open(writable bool) error {
+	if l.db != nil {
+		if err := l.db.Close(); err != nil {
+			return err
+		}
+		l.db = nil
+	}
+
+	if writable {
+		if err := os.MkdirAll(filepath.Dir(l.rootDir), FS_PERMISSIONS); err != nil {
+			return err
+		}
+	}
+
+	db, err := bolt.Open(l.path(), 0644, &bolt.Options{Timeout: boltTimeout, ReadOnly: !writable})
+	if err != nil {
+		return fmt.Errorf("could not open cache database %s: %w", l.path(), err)
+	}
+
+	if writable {
+		if err = db.Update(func(tx *bolt.Tx) error {
+			if _, err := tx.CreateBucketIfNotExists(boltBucket); err != nil {
+				return err
+			}
+			_, err := tx.CreateBucketIfNotExists(boltAccessBucket)
+			return err
+		}); err != nil {
+			db.Close()
+			return err
+		}
+	}
+
+	l.db, l.writable = db, writable
+	return nil
+}
+
+// Close stores the access times recorded since the last write, then closes the database,
+// if it's open. It's reopened if the store is used again.
+func (l *boltStore) Close() error {
+	var err error
+	l.touchedMutex.Lock()
+	nTouched := len(l.touched)
+	l.touchedMutex.Unlock()
+	if nTouched > 0 {
+		err = l.update(func(*bolt.Tx) error { return nil })
+	}
+
+	l.dbMutex.Lock()
+	defer l.dbMutex.Unlock()
+
+	if l.db == nil {
+		return err
+	}
+	if closeErr := l.db.Close(); err == nil {
+		err = closeErr
+	}
+	l.db, l.writable = nil, false
+	return err
+}
+
+// key converts the path of an item into its key in the database
+func (l *boltStore) key(path string) []byte {
+	return []byte(strings.TrimPrefix(filepath.Clean(path), l.rootDir+string(filepath.Separator)))
+}
+
+// Writer returns io.WriterCloser for the item at given path
+func (l *boltStore) Writer(path string) (io.WriteCloser, error) {
+	return &boltWriteCloser{
+		store: l,
+		key:   l.key(path),
+	}, nil
+}
+
+// Reader returns io.ReaderCloser for the item at given path
+func (l *boltStore) Reader(path string) (io.ReadCloser, error) {
+	var value []byte
+	err := l.view(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(boltBucket).Get(l.key(path)); v != nil {
+			// The value is only valid during the transaction, so we copy it
+			value = append([]byte{}, v...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		return nil, fmt.Errorf("%s: %w", path, ErrNotFound)
+	}
+	return io.NopCloser(bytes.NewReader(value)), nil
+}
+
+// Remove removes the item at given path
+func (l *boltStore) Remove(path string) error {
+	return l.RemoveMany([]string{path})
+}
+
+// RemoveMany removes the items at the given paths in a single transaction. The database
+// is only opened for writing if at least one of the items is present.
+func (l *boltStore) RemoveMany(paths []string) error {
+	present := make([][]byte, 0, len(paths))
+	err := l.view(func(tx *bolt.Tx) error {
+		for _, path := range paths {
+			if key := l.key(path); tx.Bucket(boltBucket).Get(key) != nil {
+				present = append(present, key)
+			}
+		}
+		return nil
+	})
+	if err != nil || len(present) == 0 {
+		return err
+	}
+
+	return l.update(func(tx *bolt.Tx) error {
+		for _, key := range present {
+			if err := tx.Bucket(boltAccessBucket).Delete(key); err != nil {
+				return err
+			}
+			if err := tx.Bucket(boltBucket).Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Touch marks the item at given path as recently used. The time is kept in memory until
+// the next write, so touching items does not require a write transaction.
+func (l *boltStore) Touch(path string) error {
+	l.touchedMutex.Lock()
+	defer l.touchedMutex.Unlock()
+	l.touched[string(l.key(path))] = time.Now()
+	return nil
+}
+
+// putTouched stores the access times recorded by Touch of the items that are still present
+func (l *boltStore) putTouched(tx *bolt.Tx) error {
+	l.touchedMutex.Lock()
+	touched := l.touched
+	l.touched = make(map[string]time.Time)
+	l.touchedMutex.Unlock()
+
+	for key, when := range touched {
+		if tx.Bucket(boltBucket).Get([]byte(key)) == nil {
+			continue
+		}
+		if err := putAccessTime(tx, []byte(key), when); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// touchedAt returns the access time recorded by Touch for the item with the given key
+// that is not stored yet, if any
+func (l *boltStore) touchedAt(key []byte) (time.Time, bool) {
+	l.touchedMutex.Lock()
+	defer l.touchedMutex.Unlock()
+	when, ok := l.touched[string(key)]
+	return when, ok
+}
+
+func putAccessTime(tx *bolt.Tx, key []byte, when time.Time) error {
+	value := make([]byte, 8)
+	binary.LittleEndian.PutUint64(value, uint64(when.Unix()))
+	return tx.Bucket(boltAccessBucket).Put(key, value)
+}
+
+func (l *boltStore) Stat(path string) (*ItemInfo, error) {
+	var info *ItemInfo
+	err := l.view(func(tx *bolt.Tx) error {
+		key := l.key(path)
+		if v := tx.Bucket(boltBucket).Get(key); v != nil {
+			info = &ItemInfo{
+				fileSize: len(v),
+				name:     filepath.Base(path),
+			}
+			if a := tx.Bucket(boltAccessBucket).Get(key); len(a) == 8 {
+				info.lastAccess = time.Unix(int64(binary.LittleEndian.Uint64(a)), 0)
+			}
+			if when, ok := l.touchedAt(key); ok {
+				info.lastAccess = when
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if info == nil {
+		return nil, fmt.Errorf("%s: %w", path, ErrNotFound)
+	}
+	return info, nil
+}
+
+// Walk calls fn with the path of every item in the database stored under rootDir (which
+// may be the folder the database was opened for or any folder within it). The keys are
+// collected before fn is called, so fn may safely read, write, or remove items.
+func (l *boltStore) Walk(rootDir string, fn func(path string) error) error {
+	prefix := []byte{}
+	if rel, err := filepath.Rel(l.rootDir, filepath.Clean(rootDir)); err == nil && rel != "." {
+		prefix = []byte(filepath.ToSlash(rel) + "/")
+	}
+
+	paths := []string{}
+	err := l.view(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltBucket).Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			paths = append(paths, filepath.Join(l.rootDir, string(k)))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if err := fn(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
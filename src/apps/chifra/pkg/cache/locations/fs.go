@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
 )
@@ -90,11 +91,35 @@ func (l *fileSystem) Stat(path string) (*ItemInfo, error) {
 	}
 
 	return &ItemInfo{
-		fileSize: int(info.Size()),
-		name:     info.Name(),
+		fileSize:   int(info.Size()),
+		name:       info.Name(),
+		lastAccess: info.ModTime(),
 	}, err
 }
 
+// Touch marks the item at given path as recently used. We use the modification time
+// rather than the access time because many file systems are mounted with noatime.
+func (l *fileSystem) Touch(path string) error {
+	now := time.Now()
+	return os.Chtimes(path, now, now)
+}
+
+// Walk calls fn with the path of every item stored under rootDir
+func (l *fileSystem) Walk(rootDir string, fn func(path string) error) error {
+	return filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		return fn(path)
+	})
+}
+
 func (l *fileSystem) makeParentDirectories(path string) error {
 	dirPath, _ := filepath.Split(path)
 	return os.MkdirAll(dirPath, FS_PERMISSIONS)
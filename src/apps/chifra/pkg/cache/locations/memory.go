@@ -96,7 +96,7 @@ func (l *memory) Stat(path string) (*ItemInfo, error) {
 		fileSize = item.buf.Len()
 	}
 	return &ItemInfo{
-		fileSize,
-		path,
+		fileSize: fileSize,
+		name:     path,
 	}, nil
 }
@@ -1,8 +1,11 @@
 package locations
 
+import "time"
+
 type ItemInfo struct {
-	fileSize int
-	name     string
+	fileSize   int
+	name       string
+	lastAccess time.Time
 }
 
 func (s *ItemInfo) Size() int {
@@ -12,3 +15,9 @@ func (s *ItemInfo) Size() int {
 func (s *ItemInfo) Name() string {
 	return s.name
 }
+
+// LastAccess returns the last time the item was written or (if the cache is size-limited) read.
+// Zero if the Storer does not track it.
+func (s *ItemInfo) LastAccess() time.Time {
+	return s.lastAccess
+}
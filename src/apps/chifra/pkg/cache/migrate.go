@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MigrateStore moves every item in a chain's cache from one backend to another. Each item is
+// removed from the source only after it has been written to the destination, so an interrupted
+// migration may simply be run again. The report function, if not nil, is called after each item
+// is moved. Returns the number of items moved.
+func MigrateStore(chain string, from, to StoreLocation, report func(path string, nMoved int)) (int, error) {
+	if from == to {
+		return 0, errors.New("the source and destination cache stores are the same")
+	}
+
+	fromOpts := &StoreOptions{Location: from, Chain: chain}
+	rootDir := fromOpts.rootDir()
+	src, err := fromOpts.location(rootDir)
+	if err != nil {
+		return 0, err
+	}
+
+	walker, ok := src.(Walker)
+	if !ok {
+		return 0, fmt.Errorf("the items in cache store %d cannot be enumerated", from)
+	}
+
+	toOpts := &StoreOptions{Location: to, Chain: chain}
+	dst, err := toOpts.location(toOpts.rootDir())
+	if err != nil {
+		return 0, err
+	}
+
+	nMoved := 0
+	err = walker.Walk(rootDir, func(path string) error {
+		if err := copyItem(src, dst, path); err != nil {
+			return fmt.Errorf("could not migrate %s: %w", path, err)
+		}
+		if err := src.Remove(path); err != nil {
+			return err
+		}
+		nMoved++
+		if report != nil {
+			report(path, nMoved)
+		}
+		return nil
+	})
+
+	return nMoved, err
+}
+
+// copyItem copies the raw bytes of a single cache item from one Storer to another
+func copyItem(src, dst Storer, path string) error {
+	reader, err := src.Reader(path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	writer, err := dst.Writer(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(writer, reader); err != nil {
+		// Don't leave a partial item in the destination
+		writer.Close()
+		_ = dst.Remove(path)
+		return err
+	}
+	return writer.Close()
+}
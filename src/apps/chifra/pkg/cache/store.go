@@ -33,21 +33,34 @@ var defaultStoreOnce sync.Once
 type Store struct {
 	resolvedPaths map[Locator]string
 	location      Storer
+	locationType  StoreLocation
 	rootDir       string
+	// If limit is not zero, the least recently used items are evicted when the
+	// store grows past limit bytes
+	limit        uint64
+	usage        uint64
+	usageKnown   bool
+	usagePending bool
+	pendingDelta int64
+	pruning      bool
+	usageMutex   sync.Mutex
 	// If readOnly is true, Store will not write to the cache, but
 	// still read (issue #3047)
 	readOnly bool
 }
 
 func NewStore(options *StoreOptions) (*Store, error) {
-	location, err := options.location()
+	rootDir := options.rootDir()
+	location, err := options.location(rootDir)
 	if err != nil {
 		return nil, err
 	}
 	return &Store{
-		location: location,
-		rootDir:  options.rootDir(),
-		readOnly: options.ReadOnly,
+		location:     location,
+		locationType: options.Location,
+		rootDir:      rootDir,
+		limit:        options.Limit,
+		readOnly:     options.ReadOnly,
 	}, nil
 }
 
@@ -100,21 +113,35 @@ func (s *Store) Write(value Locator, options *WriteOptions) (err error) {
 	trapChannel := sigintTrap.Enable(ctx, cancel, cleanOnQuit)
 	defer sigintTrap.Disable(trapChannel)
 
+	buffer := new(bytes.Buffer)
+	item := NewItem(buffer)
+	if err = item.Encode(value); err != nil {
+		printErr("encoding", err)
+		return
+	}
+
+	previousSize := 0
+	if s.limit > 0 {
+		if info, err := s.location.Stat(itemPath); err == nil {
+			previousSize = info.Size()
+		}
+	}
+
 	writer, err := s.location.Writer(itemPath)
 	if err != nil {
 		printErr("getting writer", err)
 		return
 	}
-	defer writer.Close()
 
-	buffer := new(bytes.Buffer)
-	item := NewItem(buffer)
-	if err = item.Encode(value); err != nil {
-		printErr("encoding", err)
+	n, err := buffer.WriteTo(writer)
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
 		return
 	}
-	_, err = buffer.WriteTo(writer)
 
+	s.enforceLimit(n - int64(previousSize))
 	return
 }
 
@@ -147,9 +174,12 @@ func (s *Store) Read(value Locator, options *ReadOptions) (err error) {
 	item := NewItem(buffer)
 	err = item.Decode(value)
 	if err != nil {
-		_ = os.Remove(itemPath)
+		_ = s.location.Remove(itemPath)
 		printErr("decoding", err)
+		return
 	}
+
+	s.touch(itemPath)
 	return
 }
 
@@ -180,6 +210,7 @@ func (s *Store) Remove(value Locator) (err error) {
 }
 
 func (s *Store) Decache(locators []Locator, processor func(*locations.ItemInfo) bool) (err error) {
+	itemPaths := make([]string, 0, len(locators))
 	for _, locator := range locators {
 		stats, err := s.Stat(locator)
 		if err != nil {
@@ -190,7 +221,21 @@ func (s *Store) Decache(locators []Locator, processor func(*locations.ItemInfo)
 		if !processor(stats) {
 			continue
 		}
-		if err := s.Remove(locator); err != nil {
+		if itemPath, err := s.resolvePath(locator); err == nil {
+			itemPaths = append(itemPaths, itemPath)
+		}
+	}
+
+	// Storers that keep their items in a database remove them all in one transaction
+	if remover, ok := s.location.(BatchRemover); ok {
+		if err := remover.RemoveMany(itemPaths); err != nil {
+			printErr("decache", err)
+		}
+		return nil
+	}
+
+	for _, itemPath := range itemPaths {
+		if err := s.location.Remove(itemPath); err != nil {
 			printErr("decache", err)
 		}
 	}
@@ -198,6 +243,28 @@ func (s *Store) Decache(locators []Locator, processor func(*locations.ItemInfo)
 	return nil
 }
 
+// Items calls fn with the path and information of every item stored in the given folder
+// of the store (for example, `blocks`)
+func (s *Store) Items(folder string, fn func(path string, info *locations.ItemInfo) error) error {
+	walker, ok := s.location.(Walker)
+	if !ok {
+		return errors.New("the items in this cache store cannot be enumerated")
+	}
+
+	return walker.Walk(path.Join(s.rootDir, folder), func(itemPath string) error {
+		info, err := s.location.Stat(itemPath)
+		if err != nil {
+			return nil
+		}
+		return fn(itemPath, info)
+	})
+}
+
+// Location returns the kind of Storer holding the items of the store
+func (s *Store) Location() StoreLocation {
+	return s.locationType
+}
+
 func (s *Store) ReadOnly() bool {
 	return s.readOnly
 }
@@ -2,7 +2,13 @@ package cache
 
 import (
 	"io"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache/locations"
+	bolt "go.etcd.io/bbolt"
 )
 
 // We have to create a struct that implements Cache(un)Marshaler and Locator
@@ -67,3 +73,128 @@ func TestStoreWrite(t *testing.T) {
 		t.Fatal("wrong value:", result.Value)
 	}
 }
+
+func TestStoreBolt(t *testing.T) {
+	value := &testStoreData{
+		Id:    "2",
+		Value: "unchained",
+	}
+	opts := &StoreOptions{
+		Location: BoltCache,
+		RootDir:  t.TempDir(),
+	}
+	cacheStore, err := NewStore(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cacheStore.Write(value, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	result := &testStoreData{
+		Id: "2",
+	}
+	if err := cacheStore.Read(result, nil); err != nil {
+		t.Fatal(err)
+	}
+	if result.Value != value.Value {
+		t.Fatal("wrong value:", result.Value)
+	}
+
+	if info, err := cacheStore.Stat(result); err != nil {
+		t.Fatal(err)
+	} else if info.Size() == 0 || info.Name() != "2.bin" {
+		t.Fatal("wrong stat:", info.Size(), info.Name())
+	}
+
+	if err := cacheStore.Remove(result); err != nil {
+		t.Fatal(err)
+	}
+	if err := cacheStore.Read(result, nil); err == nil {
+		t.Fatal("expected an error reading a removed item")
+	}
+}
+
+func TestStoreBoltOpen(t *testing.T) {
+	rootDir := filepath.Join(t.TempDir(), "v1")
+	dbPath := rootDir + ".db"
+	cacheStore, err := NewStore(&StoreOptions{
+		Location: BoltCache,
+		RootDir:  rootDir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading from a cache that was never written doesn't create the database
+	if err := cacheStore.Read(&testStoreData{Id: "1"}, nil); err == nil {
+		t.Fatal("expected an error reading from an empty cache")
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Fatal("the database was created by a read:", err)
+	}
+
+	for _, id := range []string{"1", "2"} {
+		if err := cacheStore.Write(&testStoreData{Id: id, Value: "value " + id}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Once closed, other processes may open the database for writing
+	if err := CloseStores(); err != nil {
+		t.Fatal(err)
+	}
+	other, err := bolt.Open(dbPath, 0644, &bolt.Options{Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal("the database is still locked:", err)
+	}
+	other.Close()
+
+	// Readers share the database
+	if err := cacheStore.Read(&testStoreData{Id: "1"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	other, err = bolt.Open(dbPath, 0644, &bolt.Options{Timeout: 100 * time.Millisecond, ReadOnly: true})
+	if err != nil {
+		t.Fatal("a reader locked out other readers:", err)
+	}
+	other.Close()
+
+	nItems := 0
+	if err := cacheStore.Items("test", func(path string, info *locations.ItemInfo) error {
+		nItems++
+		return nil
+	}); err != nil || nItems != 2 {
+		t.Fatal("wrong items:", nItems, err)
+	}
+
+	// Decaching writes to the database it's so far only read
+	locators := []Locator{&testStoreData{Id: "1"}, &testStoreData{Id: "2"}, &testStoreData{Id: "3"}}
+	nDecached := 0
+	if err := cacheStore.Decache(locators, func(*locations.ItemInfo) bool {
+		nDecached++
+		return true
+	}); err != nil || nDecached != 2 {
+		t.Fatal("wrong decache:", nDecached, err)
+	}
+	if _, err := cacheStore.Stat(&testStoreData{Id: "2"}); err == nil {
+		t.Fatal("expected an error for a decached item")
+	}
+
+	if err := CloseStores(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLocationFromName(t *testing.T) {
+	for name, want := range map[string]StoreLocation{"fs": FsCache, "memory": MemoryCache, "Bolt": BoltCache} {
+		if got, err := LocationFromName(name); err != nil || got != want {
+			t.Error("wrong location for", name, got, err)
+		}
+	}
+
+	if _, err := LocationFromName("unknown"); err == nil {
+		t.Error("expected an error for an unknown store")
+	}
+}
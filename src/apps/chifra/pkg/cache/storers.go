@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache/locations"
+)
+
+// StorerFactory returns a Storer for the cache rooted at rootDir
+type StorerFactory func(rootDir string) (Storer, error)
+
+// Walker is implemented by Storers that can enumerate the items they hold. The
+// cache migration requires the source Storer to be a Walker.
+type Walker interface {
+	Walk(rootDir string, fn func(path string) error) error
+}
+
+// BatchRemover is implemented by Storers that can remove many items at once more cheaply
+// than one at a time. Decache uses it when it's available.
+type BatchRemover interface {
+	RemoveMany(paths []string) error
+}
+
+type registeredStorer struct {
+	name    string
+	factory StorerFactory
+}
+
+var storers = make(map[StoreLocation]registeredStorer)
+var storersMutex sync.Mutex
+
+// closers are the Storers handed out so far that hold resources (such as an open database)
+// which must be released when the command finishes
+var closers = make(map[Storer]io.Closer)
+
+// RegisterStorer makes a cache backend available under the given location and name. The
+// name is how the backend is chosen with the `cacheStore` setting in trueBlocks.toml.
+// Registering a location or name twice replaces the previous registration.
+func RegisterStorer(location StoreLocation, name string, factory StorerFactory) {
+	storersMutex.Lock()
+	defer storersMutex.Unlock()
+
+	name = strings.ToLower(name)
+	for loc, existing := range storers {
+		if existing.name == name {
+			delete(storers, loc)
+		}
+	}
+	storers[location] = registeredStorer{
+		name:    name,
+		factory: factory,
+	}
+}
+
+// LocationFromName returns the location of the backend registered under the given name
+func LocationFromName(name string) (StoreLocation, error) {
+	storersMutex.Lock()
+	defer storersMutex.Unlock()
+
+	name = strings.ToLower(name)
+	for loc, existing := range storers {
+		if existing.name == name {
+			return loc, nil
+		}
+	}
+	return FsCache, fmt.Errorf("unknown cache store %s, expected one of [%s]", name, strings.Join(storerNames(), "|"))
+}
+
+// storerNames returns the sorted names of the registered backends. The mutex must be held.
+func storerNames() []string {
+	names := make([]string, 0, len(storers))
+	for _, existing := range storers {
+		names = append(names, existing.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newStorer(location StoreLocation, rootDir string) (Storer, error) {
+	storersMutex.Lock()
+	registered, ok := storers[location]
+	storersMutex.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no cache store registered for location %d", location)
+	}
+
+	storer, err := registered.factory(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	if closer, ok := storer.(io.Closer); ok {
+		storersMutex.Lock()
+		closers[storer] = closer
+		storersMutex.Unlock()
+	}
+	return storer, nil
+}
+
+// CloseStores waits for any eviction running in the background (stopping it if it takes more
+// than a few seconds), then releases the resources held by every Storer in use, such as the
+// lock on a cache database, so that other processes may use the cache. It's called when a
+// command finishes. A Storer used after it's closed reopens its resources.
+func CloseStores() error {
+	waitForBackground()
+
+	storersMutex.Lock()
+	defer storersMutex.Unlock()
+
+	var err error
+	for _, closer := range closers {
+		if closeErr := closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+func init() {
+	RegisterStorer(FsCache, "fs", func(string) (Storer, error) {
+		return locations.FileSystem()
+	})
+	RegisterStorer(MemoryCache, "memory", func(string) (Storer, error) {
+		return locations.Memory()
+	})
+	RegisterStorer(BoltCache, "bolt", func(rootDir string) (Storer, error) {
+		return locations.Bolt(rootDir)
+	})
+}
@@ -1,6 +1,9 @@
 package config
 
+import "reflect"
+
 // IsChainConfigured returns true if the chain is configured in the config file.
 func IsChainConfigured(needle string) bool {
-	return GetRootConfig().Chains[needle] != chainGroup{}
+	ch, ok := GetRootConfig().Chains[needle]
+	return ok && !reflect.DeepEqual(ch, chainGroup{})
 }
@@ -5,6 +5,8 @@
 package config
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -49,12 +51,106 @@ func GetRpcProvider(chain string) (string, error) {
 	return cleaned, nil
 }
 
+// GetRpcProviders returns all of the RPC providers for a chain. The first is the chain's
+// rpcProvider, followed by any others listed in rpcProviders.
+func GetRpcProviders(chain string) []string {
+	ch := GetRootConfig().Chains[chain]
+	ret := make([]string, 0, len(ch.RpcProviders)+1)
+	seen := make(map[string]bool, len(ch.RpcProviders)+1)
+	for _, provider := range append([]string{ch.RpcProvider}, ch.RpcProviders...) {
+		if len(strings.TrimSpace(provider)) == 0 {
+			continue
+		}
+		cleaned := cleanPrefix(strings.TrimSpace(provider))
+		if !seen[cleaned] {
+			seen[cleaned] = true
+			ret = append(ret, cleaned)
+		}
+	}
+	if len(ret) == 0 {
+		// Preserves the behavior of GetRpcProvider for chains with no provider
+		ret = append(ret, cleanPrefix(""))
+	}
+	return ret
+}
+
+// GetRpcRouting returns how commands are spread across a chain's RPC providers, either
+// `round-robin` (the default, in turn) or `latency` (the fastest healthy provider)
+func GetRpcRouting(chain string) string {
+	ch := GetRootConfig().Chains[chain]
+	if strings.ToLower(ch.RpcRouting) == "latency" {
+		return "latency"
+	}
+	return "round-robin"
+}
+
 // GetSymbol returns the expected chain id for a given chain
 func GetSymbol(chain string) string {
 	ch := GetRootConfig().Chains[chain]
 	return ch.Symbol
 }
 
+// GetCacheLimit returns the maximum size in bytes of the binary cache for a chain. Zero means the
+// cache is unlimited. The value may carry a KB, MB, GB, or TB suffix (powers of 1024).
+func GetCacheLimit(chain string) (uint64, error) {
+	ch := GetRootConfig().Chains[chain]
+	return parseByteSize(ch.CacheLimit)
+}
+
+// GetRpcRate returns the maximum number of requests per second sent to the chain's RPC provider.
+// Zero means there is no limit.
+func GetRpcRate(chain string) float64 {
+	ch := GetRootConfig().Chains[chain]
+	if ch.RpcRate < 0 {
+		return 0
+	}
+	return ch.RpcRate
+}
+
+// GetRpcBatch returns the maximum number of concurrent calls to the chain's RPC provider that may
+// be combined into a single batch request. Zero or one means calls are not batched.
+func GetRpcBatch(chain string) int {
+	ch := GetRootConfig().Chains[chain]
+	if ch.RpcBatch < 1 {
+		return 1
+	}
+	return ch.RpcBatch
+}
+
+var byteSizeSuffixes = []struct {
+	suffix string
+	mult   uint64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// parseByteSize parses values such as "500MB", "20 GB", or "1048576" into a number of bytes
+func parseByteSize(str string) (uint64, error) {
+	value := strings.ToUpper(strings.TrimSpace(str))
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	mult := uint64(1)
+	for _, s := range byteSizeSuffixes {
+		if strings.HasSuffix(value, s.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, s.suffix))
+			mult = s.mult
+			break
+		}
+	}
+
+	size, err := strconv.ParseFloat(value, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid cacheLimit %s", str)
+	}
+	return uint64(size * float64(mult)), nil
+}
+
 func cleanUrl(url string) string {
 	url = cleanPrefix(url)
 	if !strings.HasSuffix(url, "/") {
@@ -0,0 +1,35 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package config
+
+import "testing"
+
+func Test_parseByteSize(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected uint64
+		wantErr  bool
+	}{
+		{value: "", expected: 0},
+		{value: "1048576", expected: 1 << 20},
+		{value: "512B", expected: 512},
+		{value: "10KB", expected: 10 << 10},
+		{value: "1.5 mb", expected: 3 << 19},
+		{value: "20GB", expected: 20 << 30},
+		{value: "2TB", expected: 2 << 40},
+		{value: "lots", wantErr: true},
+		{value: "-1GB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseByteSize(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Error(tt.value, "unexpected error state", err)
+		}
+		if !tt.wantErr && got != tt.expected {
+			t.Error(tt.value, "expected", tt.expected, "got", got)
+		}
+	}
+}
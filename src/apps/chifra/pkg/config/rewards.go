@@ -0,0 +1,168 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RewardSchedule describes how the miners of a proof-of-work chain are paid. The schedule of a chain
+// is read from its `[chains.<chain>.rewards]` table. Chains that don't configure one use the built in
+// schedule for their chain id (mainnet and Ethereum Classic) or, failing that, pay no rewards.
+type RewardSchedule struct {
+	// Formula is either `ethash` (the default), under which an uncle's miner earns (uncle + 8 -
+	// block) / 8 of the block reward, or `ecip1017` (Ethereum Classic), under which the block
+	// reward drops by a fifth every EraLength blocks and, after the first era, an uncle's miner
+	// earns a flat 1/32 of the block reward. Under both, a block's miner earns an additional 1/32
+	// of the block reward for each uncle it includes.
+	Formula string `toml:"formula"`
+	// Steps are the block rewards (in wei) paid from each step's block on (the hard forks)
+	Steps []RewardStep `toml:"steps"`
+	// EraLength is the number of blocks in each era of an `ecip1017` schedule
+	EraLength uint64 `toml:"eraLength"`
+	// LastBlock, if not zero, is the last block paid a reward (for example, the last block before
+	// the merge)
+	LastBlock uint64 `toml:"lastBlock"`
+
+	rewards []*big.Int
+}
+
+// RewardStep is the block reward paid from a given block on
+type RewardStep struct {
+	Block  uint64 `toml:"block"`
+	Reward string `toml:"reward"`
+}
+
+const (
+	ethashFormula   = "ethash"
+	ecip1017Formula = "ecip1017"
+)
+
+// builtInRewards are the reward schedules of chains that don't configure their own, by chain id
+var builtInRewards = map[string]RewardSchedule{
+	// Ethereum mainnet: Byzantium (EIP-649) and Constantinople (EIP-1234) reduced the block
+	// reward, and rewards ended at the merge
+	"1": {
+		Formula: ethashFormula,
+		Steps: []RewardStep{
+			{Block: 0, Reward: "5000000000000000000"},
+			{Block: 4370000, Reward: "3000000000000000000"},
+			{Block: 7280000, Reward: "2000000000000000000"},
+		},
+		LastBlock: 15537393,
+	},
+	// Ethereum Classic: ECIP-1017's five million block eras
+	"61": {
+		Formula: ecip1017Formula,
+		Steps: []RewardStep{
+			{Block: 0, Reward: "5000000000000000000"},
+		},
+		EraLength: 5000000,
+	},
+}
+
+// GetRewardSchedule returns the chain's reward schedule
+func GetRewardSchedule(chain string) (*RewardSchedule, error) {
+	ch := GetRootConfig().Chains[chain]
+	schedule := ch.Rewards
+	if len(schedule.Steps) == 0 {
+		chainId := ch.ChainId
+		if len(chainId) == 0 && chain == "mainnet" {
+			chainId = "1"
+		}
+		schedule = builtInRewards[chainId]
+	}
+
+	if err := schedule.init(); err != nil {
+		return nil, fmt.Errorf("invalid rewards for chain %s: %w", chain, err)
+	}
+	return &schedule, nil
+}
+
+// init checks the schedule and parses its rewards
+func (s *RewardSchedule) init() error {
+	s.Formula = strings.ToLower(s.Formula)
+	switch s.Formula {
+	case "":
+		s.Formula = ethashFormula
+	case ethashFormula:
+	case ecip1017Formula:
+		if s.EraLength == 0 {
+			return fmt.Errorf("an %s schedule requires an eraLength", ecip1017Formula)
+		}
+	default:
+		return fmt.Errorf("unknown formula %s", s.Formula)
+	}
+
+	s.rewards = make([]*big.Int, 0, len(s.Steps))
+	for i, step := range s.Steps {
+		if i > 0 && step.Block <= s.Steps[i-1].Block {
+			return fmt.Errorf("steps must be in block order")
+		}
+		reward, ok := new(big.Int).SetString(strings.TrimSpace(step.Reward), 0)
+		if !ok || reward.Sign() < 0 {
+			return fmt.Errorf("invalid reward %s at block %d", step.Reward, step.Block)
+		}
+		s.rewards = append(s.rewards, reward)
+	}
+	return nil
+}
+
+// BlockReward returns the base reward paid to the miner of the given block. It does not include the
+// reward for the block's uncles (see NephewReward) nor the block's transaction fees.
+func (s *RewardSchedule) BlockReward(bn uint64) *big.Int {
+	if bn == 0 || (s.LastBlock != 0 && bn > s.LastBlock) {
+		return big.NewInt(0)
+	}
+
+	reward := big.NewInt(0)
+	for i, step := range s.Steps {
+		if step.Block > bn {
+			break
+		}
+		reward = s.rewards[i]
+	}
+
+	if era := s.era(bn); era > 0 {
+		// ECIP-1017: the reward is reduced by a fifth each era, so it's reward * 4^era / 5^era
+		e := big.NewInt(int64(era))
+		reduced := new(big.Int).Mul(reward, new(big.Int).Exp(big.NewInt(4), e, nil))
+		return reduced.Div(reduced, new(big.Int).Exp(big.NewInt(5), e, nil))
+	}
+	return new(big.Int).Set(reward)
+}
+
+// NephewReward returns the additional reward paid to the miner of the given block for including
+// nUncles uncles
+func (s *RewardSchedule) NephewReward(bn uint64, nUncles int) *big.Int {
+	perUncle := new(big.Int).Div(s.BlockReward(bn), big.NewInt(32))
+	return perUncle.Mul(perUncle, big.NewInt(int64(nUncles)))
+}
+
+// UncleReward returns the reward paid to the miner of the uncle numbered uncleBn included in the
+// given block
+func (s *RewardSchedule) UncleReward(bn, uncleBn uint64) *big.Int {
+	blockReward := s.BlockReward(bn)
+	if s.era(bn) > 0 {
+		return blockReward.Div(blockReward, big.NewInt(32))
+	}
+
+	if uncleBn >= bn || uncleBn+8 <= bn {
+		// Not a valid uncle of this block
+		return big.NewInt(0)
+	}
+	uncleReward := blockReward.Mul(blockReward, big.NewInt(int64(uncleBn+8-bn)))
+	return uncleReward.Div(uncleReward, big.NewInt(8))
+}
+
+// era returns the block's zero-based ECIP-1017 era. It's always zero for `ethash` schedules.
+func (s *RewardSchedule) era(bn uint64) uint64 {
+	if s.Formula != ecip1017Formula || s.EraLength == 0 || bn == 0 {
+		return 0
+	}
+	return (bn - 1) / s.EraLength
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+)
+
+func helperSchedule(t *testing.T, chainId string) *RewardSchedule {
+	schedule := builtInRewards[chainId]
+	if err := schedule.init(); err != nil {
+		t.Fatal(err)
+	}
+	return &schedule
+}
+
+func TestRewardSchedule_Mainnet(t *testing.T) {
+	schedule := helperSchedule(t, "1")
+
+	blockRewards := map[uint64]string{
+		0:        "0",
+		1:        "5000000000000000000",
+		4369999:  "5000000000000000000",
+		4370000:  "3000000000000000000",
+		7280000:  "2000000000000000000",
+		15537393: "2000000000000000000",
+		15537394: "0",
+	}
+	for bn, expected := range blockRewards {
+		if got := schedule.BlockReward(bn).String(); got != expected {
+			t.Error("wrong block reward at", bn, got)
+		}
+	}
+
+	if got := schedule.NephewReward(7280000, 2).String(); got != "125000000000000000" {
+		t.Error("wrong nephew reward", got)
+	}
+
+	uncleRewards := []struct {
+		bn       uint64
+		uncleBn  uint64
+		expected string
+	}{
+		{100, 99, "4375000000000000000"},
+		{100, 94, "1250000000000000000"},
+		{100, 100, "0"},
+		{100, 92, "0"},
+	}
+	for _, test := range uncleRewards {
+		if got := schedule.UncleReward(test.bn, test.uncleBn).String(); got != test.expected {
+			t.Error("wrong uncle reward at", test.bn, test.uncleBn, got)
+		}
+	}
+}
+
+func TestRewardSchedule_Classic(t *testing.T) {
+	schedule := helperSchedule(t, "61")
+
+	blockRewards := map[uint64]string{
+		5000000:  "5000000000000000000",
+		5000001:  "4000000000000000000",
+		10000001: "3200000000000000000",
+		15000001: "2560000000000000000",
+		20000001: "2048000000000000000",
+	}
+	for bn, expected := range blockRewards {
+		if got := schedule.BlockReward(bn).String(); got != expected {
+			t.Error("wrong block reward at", bn, got)
+		}
+	}
+
+	// Era zero pays uncles by depth, later eras a flat 1/32 of the block reward
+	if got := schedule.UncleReward(5000000, 4999999).String(); got != "4375000000000000000" {
+		t.Error("wrong era zero uncle reward", got)
+	}
+	if got := schedule.UncleReward(5000001, 4999995).String(); got != "125000000000000000" {
+		t.Error("wrong era one uncle reward", got)
+	}
+	if got := schedule.NephewReward(5000001, 1).String(); got != "125000000000000000" {
+		t.Error("wrong nephew reward", got)
+	}
+}
+
+func TestRewardSchedule_Invalid(t *testing.T) {
+	tests := []struct {
+		schedule RewardSchedule
+		wantErr  bool
+	}{
+		{schedule: RewardSchedule{}},
+		{schedule: RewardSchedule{Steps: []RewardStep{{Block: 0, Reward: "0x4563918244f40000"}}}},
+		{schedule: RewardSchedule{Formula: "ECIP1017", EraLength: 2000000, Steps: []RewardStep{{Block: 0, Reward: "5000000000000000000"}}}},
+		{schedule: RewardSchedule{Formula: "ecip1017", Steps: []RewardStep{{Block: 0, Reward: "5000000000000000000"}}}, wantErr: true},
+		{schedule: RewardSchedule{Formula: "clique"}, wantErr: true},
+		{schedule: RewardSchedule{Steps: []RewardStep{{Block: 10, Reward: "2"}, {Block: 5, Reward: "1"}}}, wantErr: true},
+		{schedule: RewardSchedule{Steps: []RewardStep{{Block: 0, Reward: "five"}}}, wantErr: true},
+		{schedule: RewardSchedule{Steps: []RewardStep{{Block: 0, Reward: "-1"}}}, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		if err := tt.schedule.init(); (err != nil) != tt.wantErr {
+			t.Error(i, "unexpected error state", err)
+		}
+	}
+}
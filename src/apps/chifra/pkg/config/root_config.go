@@ -27,13 +27,19 @@ type versionGroup struct {
 }
 
 type chainGroup struct {
-	Chain          string `toml:"chain"`
-	ChainId        string `toml:"chainId"`
-	LocalExplorer  string `toml:"localExplorer"`
-	RemoteExplorer string `toml:"remoteExplorer"`
-	RpcProvider    string `toml:"rpcProvider"`
-	IpfsGateway    string `toml:"ipfsGateway"`
-	Symbol         string `toml:"symbol"`
+	Chain          string         `toml:"chain"`
+	ChainId        string         `toml:"chainId"`
+	LocalExplorer  string         `toml:"localExplorer"`
+	RemoteExplorer string         `toml:"remoteExplorer"`
+	RpcProvider    string         `toml:"rpcProvider"`
+	RpcProviders   []string       `toml:"rpcProviders"`
+	RpcRouting     string         `toml:"rpcRouting"`
+	IpfsGateway    string         `toml:"ipfsGateway"`
+	Symbol         string         `toml:"symbol"`
+	CacheLimit     string         `toml:"cacheLimit"`
+	RpcRate        float64        `toml:"rpcRate"`
+	RpcBatch       int            `toml:"rpcBatch"`
+	Rewards        RewardSchedule `toml:"rewards"`
 }
 
 type keyGroup struct {
@@ -48,8 +54,25 @@ type grpcGroup struct {
 	UdsTimeout time.Duration `toml:"udsTimeout"`
 }
 
+// daemonGroup limits each client's use of chifra daemon's light and heavy routes
+type daemonGroup struct {
+	Light RouteLimits `toml:"light"`
+	Heavy RouteLimits `toml:"heavy"`
+}
+
+// RouteLimits limits each client's use of a class of chifra daemon's routes
+type RouteLimits struct {
+	// Rate is the number of requests per second a client may sustain (zero for no limit)
+	Rate float64 `toml:"rate"`
+	// Burst is the number of requests a client may make at once before Rate applies
+	Burst int `toml:"burst"`
+	// Concurrency is the number of requests a client may have running at once (zero for no limit)
+	Concurrency int `toml:"concurrency"`
+}
+
 type settingsGroup struct {
 	CachePath      string `toml:"cachePath"`
+	CacheStore     string `toml:"cacheStore"`
 	IndexPath      string `toml:"indexPath"`
 	DefaultChain   string `toml:"defaultChain"`
 	DefaultGateway string `toml:"defaultGateway"`
@@ -59,6 +82,7 @@ type ConfigFile struct {
 	Version  versionGroup
 	Settings settingsGroup
 	Grpc     grpcGroup
+	Daemon   daemonGroup
 	Keys     map[string]keyGroup
 	Chains   map[string]chainGroup
 }
@@ -79,9 +103,15 @@ func GetChainArray() []chainGroup {
 func init() {
 	trueBlocksViper.SetConfigName("trueBlocks") // trueBlocks.toml (so we can find it)
 	trueBlocksViper.SetDefault("Settings.CachePath", PathToRootConfig()+"cache/")
+	trueBlocksViper.SetDefault("Settings.CacheStore", "fs")
 	trueBlocksViper.SetDefault("Settings.IndexPath", PathToRootConfig()+"unchained/")
 	trueBlocksViper.SetDefault("Settings.DefaultChain", "mainnet")
 	trueBlocksViper.SetDefault("Settings.DefaultGateway", "https://ipfs.unchainedindex.io/ipfs")
+	trueBlocksViper.SetDefault("Daemon.Light.Rate", 10)
+	trueBlocksViper.SetDefault("Daemon.Light.Burst", 20)
+	trueBlocksViper.SetDefault("Daemon.Heavy.Rate", 1)
+	trueBlocksViper.SetDefault("Daemon.Heavy.Burst", 3)
+	trueBlocksViper.SetDefault("Daemon.Heavy.Concurrency", 2)
 }
 
 // GetRootConfig reads and the configuration located in trueBlocks.toml file. Note
@@ -168,6 +198,14 @@ func GetDefaultChain() string {
 	return GetRootConfig().Settings.DefaultChain
 }
 
+// GetCacheStore returns the name of the backend in which the binary cache is stored
+func GetCacheStore() string {
+	if store := GetRootConfig().Settings.CacheStore; len(store) > 0 {
+		return store
+	}
+	return "fs"
+}
+
 func PathFromXDG(envVar string) (string, error) {
 	// If present, we require both an existing path and a fully qualified path
 	xdg := os.Getenv(envVar)
@@ -222,6 +260,21 @@ func HasPinningKeys(chain string) bool {
 	return len(a)+len(b)+len(c) > 0
 }
 
+// GetDaemonKeys returns the credentials the daemon accepts: a read-only API key, an admin API key,
+// and the secret with which bearer tokens (HS256 JWTs) are signed. All three come from the
+// `[keys.daemon]` group (apiKey, secret, and jwt respectively). If all are empty, the daemon
+// does not require authentication.
+func GetDaemonKeys() (string, string, string) {
+	keys := GetRootConfig().Keys
+	return keys["daemon"].ApiKey, keys["daemon"].Secret, keys["daemon"].Jwt
+}
+
+// GetDaemonLimits returns the limits on each client's use of the daemon's light and heavy routes
+func GetDaemonLimits() (RouteLimits, RouteLimits) {
+	daemon := GetRootConfig().Daemon
+	return daemon.Light, daemon.Heavy
+}
+
 func HasEsKeys(chain string) bool {
 	keys := GetRootConfig().Keys
 	return len(keys["etherscan"].ApiKey) > 0
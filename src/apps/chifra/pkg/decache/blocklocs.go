@@ -31,6 +31,9 @@ func LocationsFromBlockIds(conn *rpc.Connection, ids []identifiers.Identifier, l
 				locations = append(locations, &types.SimpleBlock[string]{
 					BlockNumber: bn,
 				})
+				locations = append(locations, &types.SimpleBlockFee{
+					BlockNumber: bn,
+				})
 				for index := range rawBlock.Transactions {
 					txToRemove := &types.SimpleTransaction{
 						BlockNumber:      bn,
@@ -4,8 +4,25 @@
 
 package file
 
+import (
+	"encoding/binary"
+	"os"
+)
+
 const (
 	// MagicNumber is used to check data validity
 	MagicNumber      = 0xdeadbeef
 	SmallMagicNumber = uint16(0xdead)
+	// SmallMagicNumber64 marks monitor files that store 64-bit block numbers
+	SmallMagicNumber64 = uint16(0xbeef)
 )
+
+// ReadSmallMagic returns the two-byte magic number at the start of the file (zero if the file is
+// missing or empty)
+func ReadSmallMagic(path string) (magic uint16) {
+	if f, err := os.Open(path); err == nil {
+		_ = binary.Read(f, binary.LittleEndian, &magic)
+		f.Close()
+	}
+	return
+}
@@ -20,9 +20,10 @@ func (f *AppearanceFilter) Sort(fromDisc []index.AppearanceRecord) {
 			if f.sortBy == Reversed {
 				i, j = j, i
 			}
-			si := (uint64(fromDisc[i].BlockNumber) << 32) + uint64(fromDisc[i].TransactionId)
-			sj := (uint64(fromDisc[j].BlockNumber) << 32) + uint64(fromDisc[j].TransactionId)
-			return si < sj
+			if fromDisc[i].BlockNumber != fromDisc[j].BlockNumber {
+				return fromDisc[i].BlockNumber < fromDisc[j].BlockNumber
+			}
+			return fromDisc[i].TransactionId < fromDisc[j].TransactionId
 		})
 	}
 }
@@ -0,0 +1,2 @@
+// Package graph builds weighted, directed graphs of the value flows and calls between addresses
+package graph
@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Write writes the graph to w in the given format, one of `graphml`, `dot`, or `edges` (a
+// CSV edge list)
+func (g *Graph) Write(w io.Writer, format string) error {
+	bw := bufio.NewWriter(w)
+	switch format {
+	case "graphml":
+		g.writeGraphML(bw)
+	case "dot":
+		g.writeDot(bw)
+	case "edges":
+		g.writeEdges(bw)
+	default:
+		return fmt.Errorf("unknown graph format %s", format)
+	}
+	return bw.Flush()
+}
+
+func (g *Graph) writeGraphML(w *bufio.Writer) {
+	fmt.Fprintln(w, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(w, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	fmt.Fprintln(w, `  <key id="name" for="node" attr.name="name" attr.type="string"/>`)
+	fmt.Fprintln(w, `  <key id="hop" for="node" attr.name="hop" attr.type="long"/>`)
+	fmt.Fprintln(w, `  <key id="asset" for="edge" attr.name="asset" attr.type="string"/>`)
+	fmt.Fprintln(w, `  <key id="weight" for="edge" attr.name="weight" attr.type="long"/>`)
+	fmt.Fprintln(w, `  <key id="value" for="edge" attr.name="value" attr.type="string"/>`)
+	fmt.Fprintln(w, `  <graph id="G" edgedefault="directed">`)
+	for _, node := range g.Nodes() {
+		fmt.Fprintf(w, "    <node id=\"%s\">\n", node.Address.Hex())
+		if len(node.Name) > 0 {
+			fmt.Fprintf(w, "      <data key=\"name\">%s</data>\n", escapeXml(node.Name))
+		}
+		fmt.Fprintf(w, "      <data key=\"hop\">%d</data>\n", node.Hop)
+		fmt.Fprintln(w, "    </node>")
+	}
+	for i, edge := range g.Edges() {
+		fmt.Fprintf(w, "    <edge id=\"e%d\" source=\"%s\" target=\"%s\">\n", i, edge.From.Hex(), edge.To.Hex())
+		fmt.Fprintf(w, "      <data key=\"asset\">%s</data>\n", edge.Asset.Hex())
+		fmt.Fprintf(w, "      <data key=\"weight\">%d</data>\n", edge.Count)
+		fmt.Fprintf(w, "      <data key=\"value\">%s</data>\n", edge.Value.String())
+		fmt.Fprintln(w, "    </edge>")
+	}
+	fmt.Fprintln(w, "  </graph>")
+	fmt.Fprintln(w, "</graphml>")
+}
+
+func (g *Graph) writeDot(w *bufio.Writer) {
+	fmt.Fprintln(w, "digraph G {")
+	for _, node := range g.Nodes() {
+		label := node.Address.Hex()
+		if len(node.Name) > 0 {
+			label = node.Name + "\\n" + label
+		}
+		fmt.Fprintf(w, "  \"%s\" [label=\"%s\", hop=%d];\n", node.Address.Hex(), escapeDot(label), node.Hop)
+	}
+	for _, edge := range g.Edges() {
+		fmt.Fprintf(w, "  \"%s\" -> \"%s\" [weight=%d, asset=\"%s\", value=\"%s\"];\n",
+			edge.From.Hex(), edge.To.Hex(), edge.Count, edge.Asset.Hex(), edge.Value.String())
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func (g *Graph) writeEdges(w *bufio.Writer) {
+	fmt.Fprintln(w, "from,to,asset,weight,value")
+	for _, edge := range g.Edges() {
+		fmt.Fprintf(w, "%s,%s,%s,%d,%s\n", edge.From.Hex(), edge.To.Hex(), edge.Asset.Hex(), edge.Count, edge.Value.String())
+	}
+}
+
+func escapeXml(str string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(str))
+	return sb.String()
+}
+
+func escapeDot(str string) string {
+	return strings.ReplaceAll(str, `"`, `\"`)
+}
@@ -0,0 +1,223 @@
+package graph
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/articulate"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// Node is an address in the graph. Hop is the number of steps the address is from the nearest
+// seed (seeds are at hop zero).
+type Node struct {
+	Address base.Address
+	Name    string
+	Hop     uint64
+}
+
+// Edge aggregates all of the interactions from one address to another in a single asset. Count
+// (the edge's weight) is the number of interactions and Value is the total amount of the asset
+// (in its smallest unit) that moved. Calls that move no value are counted with a zero value.
+type Edge struct {
+	From  base.Address
+	To    base.Address
+	Asset base.Address
+	Count uint64
+	Value big.Int
+}
+
+type edgeKey struct {
+	from, to, asset base.Address
+}
+
+type txKey struct {
+	bn   base.Blknum
+	txid base.Txnum
+}
+
+// Graph is a weighted, directed graph of the value flows and calls between addresses
+type Graph struct {
+	nodes map[base.Address]*Node
+	edges map[edgeKey]*Edge
+	txs   map[txKey]bool
+}
+
+// NewGraph returns an empty graph containing the given seed addresses
+func NewGraph(seeds []base.Address) *Graph {
+	g := &Graph{
+		nodes: make(map[base.Address]*Node),
+		edges: make(map[edgeKey]*Edge),
+		txs:   make(map[txKey]bool),
+	}
+	for _, seed := range seeds {
+		g.addNode(seed, 0)
+	}
+	return g
+}
+
+// addNode adds the address at the given hop (or moves it closer to the seeds if it's already present).
+// It returns true if the address is new.
+func (g *Graph) addNode(addr base.Address, hop uint64) bool {
+	if node, ok := g.nodes[addr]; ok {
+		if hop < node.Hop {
+			node.Hop = hop
+		}
+		return false
+	}
+	g.nodes[addr] = &Node{Address: addr, Hop: hop}
+	return true
+}
+
+// AddEdge records an interaction (and the amount of the asset it moved, if any) between two
+// addresses found at the given hop. The zero address and precompiles are ignored.
+func (g *Graph) AddEdge(from, to, asset base.Address, value *big.Int, hop uint64) {
+	if base.IsPrecompile(from.Hex()) || base.IsPrecompile(to.Hex()) {
+		return
+	}
+
+	g.addNode(from, hop)
+	g.addNode(to, hop)
+
+	key := edgeKey{from, to, asset}
+	edge, ok := g.edges[key]
+	if !ok {
+		edge = &Edge{From: from, To: to, Asset: asset}
+		g.edges[key] = edge
+	}
+	edge.Count++
+	if value != nil {
+		edge.Value.Add(&edge.Value, value)
+	}
+}
+
+// HasTransaction returns true if the transaction has already been added to the graph
+func (g *Graph) HasTransaction(bn base.Blknum, txid base.Txnum) bool {
+	return g.txs[txKey{bn, txid}]
+}
+
+// AddTransaction records the interactions in a transaction found while exploring an address at
+// the given hop: the transaction itself, its traces (if present), and any token transfers in its
+// logs. Addresses new to the graph are placed at hop + 1. A transaction shared by more than one
+// of the explored addresses is recorded only once.
+func (g *Graph) AddTransaction(tx *types.SimpleTransaction, hop uint64) {
+	if g.HasTransaction(tx.BlockNumber, tx.TransactionIndex) {
+		return
+	}
+	g.txs[txKey{tx.BlockNumber, tx.TransactionIndex}] = true
+
+	next := hop + 1
+	eth := base.FAKE_ETH_ADDRESS
+
+	to := tx.To
+	if to.IsZero() && tx.Receipt != nil {
+		to = tx.Receipt.ContractAddress
+	}
+	g.AddEdge(tx.From, to, eth, &tx.Value, next)
+
+	for _, trace := range tx.Traces {
+		if len(trace.TraceAddress) == 0 {
+			// The top-level trace repeats the transaction
+			continue
+		}
+		if trace.Action == nil {
+			continue
+		}
+		switch trace.TraceType {
+		case "call":
+			g.AddEdge(trace.Action.From, trace.Action.To, eth, &trace.Action.Value, next)
+		case "create":
+			if trace.Result != nil {
+				g.AddEdge(trace.Action.From, trace.Result.Address, eth, &trace.Action.Value, next)
+			}
+		case "suicide":
+			g.AddEdge(trace.Action.Address, trace.Action.RefundAddress, eth, &trace.Action.Balance, next)
+		}
+	}
+
+	if tx.Receipt != nil {
+		for _, log := range tx.Receipt.Logs {
+			if len(log.Topics) < 3 || log.Topics[0] != articulate.TransferTopic {
+				continue
+			}
+			from := base.HexToAddress(log.Topics[1].Hex())
+			to := base.HexToAddress(log.Topics[2].Hex())
+			amount := big.NewInt(1) // ERC-721 transfers carry the token ID, not an amount
+			if len(log.Topics) == 3 {
+				amount = base.HexToWei(log.Data)
+			}
+			g.AddEdge(from, to, log.Address, amount, next)
+		}
+	}
+}
+
+// AddressesAt returns the addresses at the given hop sorted by address. If there are more than
+// max such addresses, only the max addresses with the most interactions (ties going to the lower
+// address) are returned.
+func (g *Graph) AddressesAt(hop uint64, max int) []base.Address {
+	ret := make([]base.Address, 0)
+	for _, node := range g.Nodes() {
+		if node.Hop == hop {
+			ret = append(ret, node.Address)
+		}
+	}
+	if len(ret) <= max {
+		return ret
+	}
+
+	counts := make(map[base.Address]uint64, len(ret))
+	for _, edge := range g.edges {
+		counts[edge.From] += edge.Count
+		if edge.To != edge.From {
+			counts[edge.To] += edge.Count
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return counts[ret[i]] > counts[ret[j]]
+	})
+	ret = ret[:max]
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Hex() < ret[j].Hex()
+	})
+	return ret
+}
+
+// SetNames labels the nodes with the names found in the map
+func (g *Graph) SetNames(namesMap map[base.Address]types.SimpleName) {
+	for addr, node := range g.nodes {
+		if name, ok := namesMap[addr]; ok {
+			node.Name = name.Name
+		}
+	}
+}
+
+// Nodes returns the graph's nodes sorted by address
+func (g *Graph) Nodes() []*Node {
+	ret := make([]*Node, 0, len(g.nodes))
+	for _, node := range g.nodes {
+		ret = append(ret, node)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Address.Hex() < ret[j].Address.Hex()
+	})
+	return ret
+}
+
+// Edges returns the graph's edges sorted by sender, recipient, and asset
+func (g *Graph) Edges() []*Edge {
+	ret := make([]*Edge, 0, len(g.edges))
+	for _, edge := range g.edges {
+		ret = append(ret, edge)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].From != ret[j].From {
+			return ret[i].From.Hex() < ret[j].From.Hex()
+		}
+		if ret[i].To != ret[j].To {
+			return ret[i].To.Hex() < ret[j].To.Hex()
+		}
+		return ret[i].Asset.Hex() < ret[j].Asset.Hex()
+	})
+	return ret
+}
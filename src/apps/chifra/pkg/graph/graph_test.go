@@ -0,0 +1,99 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/articulate"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func TestAddTransaction(t *testing.T) {
+	seed := base.HexToAddress("0x1111111111111111111111111111111111111111")
+	router := base.HexToAddress("0x2222222222222222222222222222222222222222")
+	pool := base.HexToAddress("0x3333333333333333333333333333333333333333")
+	token := base.HexToAddress("0x4444444444444444444444444444444444444444")
+
+	tx := types.SimpleTransaction{
+		From:    seed,
+		To:      router,
+		Receipt: &types.SimpleReceipt{},
+	}
+	tx.Value.SetInt64(100)
+	tx.Traces = []types.SimpleTrace{
+		{TraceType: "call", Action: &types.SimpleTraceAction{From: seed, To: router}},
+		{TraceType: "call", TraceAddress: []uint64{0}, Action: &types.SimpleTraceAction{From: router, To: pool}},
+		{TraceType: "call", TraceAddress: []uint64{1}, Action: &types.SimpleTraceAction{From: router, To: pool}},
+	}
+	tx.Traces[1].Action.Value.SetInt64(100)
+	tx.Receipt.Logs = []types.SimpleLog{{
+		Address: token,
+		Topics: []base.Hash{
+			articulate.TransferTopic,
+			base.HexToHash("0x000000000000000000000000" + pool.Hex()[2:]),
+			base.HexToHash("0x000000000000000000000000" + seed.Hex()[2:]),
+		},
+		Data: "0x00000000000000000000000000000000000000000000000000000000000003e8",
+	}}
+
+	g := NewGraph([]base.Address{seed})
+	g.AddTransaction(&tx, 0)
+	// The same transaction found while exploring another address is not counted again
+	g.AddTransaction(&tx, 1)
+
+	if len(g.Nodes()) != 3 {
+		t.Fatal("expected three nodes, got", len(g.Nodes()))
+	}
+	if hop := g.AddressesAt(1, 10); len(hop) != 2 || hop[0] != router || hop[1] != pool {
+		t.Error("wrong addresses at hop one", hop)
+	}
+	if hop := g.AddressesAt(1, 1); len(hop) != 1 || hop[0] != router {
+		t.Error("expected only the most active address at hop one", hop)
+	}
+
+	edges := g.Edges()
+	if len(edges) != 3 {
+		t.Fatal("expected three edges, got", len(edges))
+	}
+	if e := edges[0]; e.From != seed || e.To != router || e.Count != 1 || e.Value.Int64() != 100 {
+		t.Error("wrong transaction edge", e)
+	}
+	if e := edges[1]; e.From != router || e.To != pool || e.Count != 2 || e.Value.Int64() != 100 {
+		t.Error("wrong trace edge", e)
+	}
+	if e := edges[2]; e.From != pool || e.To != seed || e.Asset != token || e.Value.Int64() != 1000 {
+		t.Error("wrong transfer edge", e)
+	}
+
+	var buf bytes.Buffer
+	if err := g.Write(&buf, "edges"); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 4 || lines[0] != "from,to,asset,weight,value" {
+		t.Error("wrong edge list", lines)
+	}
+
+	buf.Reset()
+	g.SetNames(map[base.Address]types.SimpleName{router: {Name: "Router & Co"}})
+	if err := g.Write(&buf, "graphml"); err != nil {
+		t.Fatal(err)
+	}
+	var parsed struct {
+		Nodes []struct{} `xml:"graph>node"`
+		Edges []struct{} `xml:"graph>edge"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil || len(parsed.Nodes) != 3 || len(parsed.Edges) != 3 {
+		t.Error("invalid graphml", err, len(parsed.Nodes), len(parsed.Edges))
+	}
+
+	buf.Reset()
+	if err := g.Write(&buf, "dot"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "digraph G {") || strings.Count(buf.String(), "->") != 3 {
+		t.Error("wrong dot output", buf.String())
+	}
+}
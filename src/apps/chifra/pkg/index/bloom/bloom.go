@@ -163,7 +163,7 @@ func (bl *ChunkBloom) ReadBloomHeader() error {
 	}
 
 	bl.HeaderSize = int64(unsafe.Sizeof(bl.Header))
-	if bl.Header.Hash.Hex() != unchained.HeaderMagicHash {
+	if !unchained.IsHeaderMagicHash(bl.Header.Hash.Hex()) {
 		return ErrInvalidBloomHash
 	}
 
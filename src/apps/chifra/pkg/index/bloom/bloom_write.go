@@ -9,6 +9,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/unchained"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/version"
 	"github.com/ethereum/go-ethereum/crypto"
 )
@@ -32,7 +33,10 @@ func (bl *ChunkBloom) WriteBloom(chain, bloomFn string) ( /* changed */ bool, er
 
 			_, _ = fp.Seek(0, io.SeekStart) // already true, but can't hurt
 			bl.Header.Magic = file.SmallMagicNumber
-			bl.Header.Hash = base.BytesToHash(crypto.Keccak256([]byte(version.ManifestVersion)))
+			if bl.Header.Hash.Hex() != unchained.HeaderMagicHash64 {
+				// Unless its chunk stores 64-bit block numbers, the bloom carries the current version
+				bl.Header.Hash = base.BytesToHash(crypto.Keccak256([]byte(version.ManifestVersion)))
+			}
 			if err = binary.Write(fp, binary.LittleEndian, bl.Header); err != nil {
 				return false, err
 			}
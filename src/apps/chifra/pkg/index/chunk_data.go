@@ -27,7 +27,9 @@ const (
 // this address's list of appearances starts followed by the Count of appearance records for the given address.
 //
 // The AppearanceTable contains nAppeeances pairs of <blockNumber.transactionId> pairs arranged by the Offset
-// and Count pairs found in the corresponding AddressTable records.
+// and Count pairs found in the corresponding AddressTable records. The transactionId is a 4-byte integer. The
+// blockNumber is a 4-byte integer unless the header's hash is unchained.HeaderMagicHash64, in which case it's
+// an 8-byte integer.
 type ChunkData struct {
 	File           *os.File
 	Header         IndexHeaderRecord
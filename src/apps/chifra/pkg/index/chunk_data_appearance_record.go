@@ -2,21 +2,81 @@ package index
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
+	"math"
 	"os"
 )
 
 const (
-	// AppRecordWidth - size of Appearance Record
+	// AppRecordWidth - size of Appearance Record (32-bit block numbers)
 	AppRecordWidth = 8
+	// AppRecordWidth64 - size of Appearance Record (64-bit block numbers)
+	AppRecordWidth64 = 12
 )
 
-// AppearanceRecord is a single record in the Appearance table
+// AppearanceRecord is a single record in the Appearance table. Chunks (and monitors) written before
+// block numbers were widened store them as four-byte integers (see appearanceRecord32). Newer chunks
+// store this structure as is.
 type AppearanceRecord struct {
-	BlockNumber   uint32 `json:"blockNumber"`
+	BlockNumber   uint64 `json:"blockNumber"`
 	TransactionId uint32 `json:"transactionIndex"`
 }
 
+// appearanceRecord32 is an AppearanceRecord as stored in chunks (and monitors) with 32-bit block numbers
+type appearanceRecord32 struct {
+	BlockNumber   uint32
+	TransactionId uint32
+}
+
+// Needs64 returns true if any of the appearances' block numbers do not fit in 32 bits
+func Needs64(apps []AppearanceRecord) bool {
+	for _, app := range apps {
+		if app.BlockNumber > math.MaxUint32 {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadAppearanceRecords reads len(apps) appearances of the given width (AppRecordWidth or AppRecordWidth64)
+func ReadAppearanceRecords(r io.Reader, width int64, apps []AppearanceRecord) error {
+	if width == AppRecordWidth64 {
+		return binary.Read(r, binary.LittleEndian, apps)
+	}
+
+	apps32 := make([]appearanceRecord32, len(apps))
+	if err := binary.Read(r, binary.LittleEndian, apps32); err != nil {
+		return err
+	}
+	for i, app := range apps32 {
+		apps[i] = AppearanceRecord{
+			BlockNumber:   uint64(app.BlockNumber),
+			TransactionId: app.TransactionId,
+		}
+	}
+	return nil
+}
+
+// WriteAppearanceRecords writes the appearances with the given width (AppRecordWidth or AppRecordWidth64)
+func WriteAppearanceRecords(w io.Writer, width int64, apps []AppearanceRecord) error {
+	if width == AppRecordWidth64 {
+		return binary.Write(w, binary.LittleEndian, apps)
+	}
+
+	apps32 := make([]appearanceRecord32, len(apps))
+	for i, app := range apps {
+		if app.BlockNumber > math.MaxUint32 {
+			return fmt.Errorf("block number %d does not fit in a 32-bit appearance record", app.BlockNumber)
+		}
+		apps32[i] = appearanceRecord32{
+			BlockNumber:   uint32(app.BlockNumber),
+			TransactionId: app.TransactionId,
+		}
+	}
+	return binary.Write(w, binary.LittleEndian, apps32)
+}
+
 func (chunk *ChunkData) ReadAppearanceRecordsAndResetOffset(addrRecord *AddressRecord) (apps []AppearanceRecord, err error) {
 	offset, err := chunk.File.Seek(0, io.SeekCurrent)
 	if err != nil {
@@ -37,7 +97,7 @@ func (chunk *ChunkData) ReadAppearanceRecordsAndResetOffset(addrRecord *AddressR
 }
 
 func (chunk *ChunkData) ReadAppearanceRecords(addrRecord *AddressRecord) (apps []AppearanceRecord, err error) {
-	readLocation := int64(HeaderWidth + AddrRecordWidth*chunk.Header.AddressCount + AppRecordWidth*addrRecord.Offset)
+	readLocation := chunk.AppTableStart + chunk.Header.AppRecordWidth()*int64(addrRecord.Offset)
 
 	_, err = chunk.File.Seek(readLocation, io.SeekStart)
 	if err != nil {
@@ -45,11 +105,16 @@ func (chunk *ChunkData) ReadAppearanceRecords(addrRecord *AddressRecord) (apps [
 	}
 
 	apps = make([]AppearanceRecord, addrRecord.Count)
-	err = binary.Read(chunk.File, binary.LittleEndian, &apps)
+	err = ReadAppearanceRecords(chunk.File, chunk.Header.AppRecordWidth(), apps)
 
 	return
 }
 
-func (app *AppearanceRecord) ReadAppearance(file *os.File) (err error) {
-	return binary.Read(file, binary.LittleEndian, app)
+// ReadAppearance reads the next appearance (of the given width) from the file
+func (app *AppearanceRecord) ReadAppearance(file *os.File, width int64) (err error) {
+	apps := []AppearanceRecord{{}}
+	if err = ReadAppearanceRecords(file, width, apps); err == nil {
+		*app = apps[0]
+	}
+	return
 }
@@ -17,7 +17,8 @@ import (
 
 // IndexHeaderRecord is the first 44 bytes of an ChunkData. This structure carries a magic number (4 bytes),
 // a version specifier (32 bytes), and two four-byte integers representing the number of records in each
-// of the two tables.
+// of the two tables. The version specifier determines the width of the block numbers in the appearance
+// table (see AppRecordWidth).
 type IndexHeaderRecord struct {
 	Magic           uint32
 	Hash            base.Hash
@@ -25,6 +26,20 @@ type IndexHeaderRecord struct {
 	AppearanceCount uint32
 }
 
+// AppRecordWidth returns the width of the chunk's appearance records, AppRecordWidth64 for chunks
+// that store 64-bit block numbers and AppRecordWidth otherwise
+func (h *IndexHeaderRecord) AppRecordWidth() int64 {
+	if h.Is64() {
+		return AppRecordWidth64
+	}
+	return AppRecordWidth
+}
+
+// Is64 returns true if the chunk stores 64-bit block numbers
+func (h *IndexHeaderRecord) Is64() bool {
+	return h.Hash.Hex() == unchained.HeaderMagicHash64
+}
+
 func (h *IndexHeaderRecord) String() string {
 	b, _ := json.MarshalIndent(h, "", " ")
 	return string(b)
@@ -62,7 +77,7 @@ func ReadChunkHeader(fileName string, checkHash bool) (header IndexHeaderRecord,
 	}
 
 	headerHash := header.Hash.Hex()
-	hasMagicHash := headerHash == unchained.HeaderMagicHash
+	hasMagicHash := unchained.IsHeaderMagicHash(headerHash)
 	if !hasMagicHash {
 		return header, fmt.Errorf("header has incorrect hash in %s, expected %s, got %s", fileName, unchained.HeaderMagicHash, headerHash)
 	}
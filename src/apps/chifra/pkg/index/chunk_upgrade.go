@@ -0,0 +1,108 @@
+package index
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index/bloom"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/unchained"
+)
+
+// UpgradeChunk rewrites the chunk (both the index portion and the bloom filter) with 64-bit block
+// numbers. It returns false if the chunk already has them. Note that upgraded chunks no longer
+// match the IPFS hashes in the manifest, and their index portions no longer match its sizes (see
+// Size32).
+func UpgradeChunk(chain, path string) ( /* changed */ bool, error) {
+	indexFn := ToIndexPath(path)
+	chunk, err := NewChunkData(indexFn)
+	if err != nil {
+		return false, err
+	}
+	defer chunk.Close()
+
+	if chunk.Header.Is64() {
+		return false, nil
+	}
+
+	addressTable := make([]AddressRecord, chunk.Header.AddressCount)
+	if err = binary.Read(chunk.File, binary.LittleEndian, addressTable); err != nil {
+		return false, err
+	}
+	appearanceTable := make([]AppearanceRecord, chunk.Header.AppearanceCount)
+	if err = ReadAppearanceRecords(chunk.File, AppRecordWidth, appearanceTable); err != nil {
+		return false, err
+	}
+	chunk.Close()
+
+	// Read the bloom before touching anything so a bad bloom leaves the chunk as it was
+	bl := bloom.ChunkBloom{}
+	if err = bl.ReadBloom(ToBloomPath(indexFn)); err != nil {
+		return false, err
+	}
+
+	tmpPath := filepath.Join(config.PathToCache(chain), "tmp")
+	if backupFn, err := file.MakeBackup(tmpPath, indexFn); err == nil {
+		defer func() {
+			if file.FileExists(backupFn) {
+				// If the backup file exists, something failed, so we replace the original file.
+				_ = os.Rename(backupFn, indexFn)
+				_ = os.Remove(backupFn) // seems redundant, but may not be on some operating systems
+			}
+		}()
+
+		if fp, err := os.OpenFile(indexFn, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644); err == nil {
+			defer fp.Close() // defers are last in, first out
+
+			header := chunk.Header
+			header.Hash = base.HexToHash(unchained.HeaderMagicHash64)
+			if err = binary.Write(fp, binary.LittleEndian, header); err != nil {
+				return false, err
+			}
+			if err = binary.Write(fp, binary.LittleEndian, addressTable); err != nil {
+				return false, err
+			}
+			if err = WriteAppearanceRecords(fp, AppRecordWidth64, appearanceTable); err != nil {
+				return false, err
+			}
+			if err = fp.Sync(); err != nil {
+				return false, err
+			}
+
+			bl.Header.Hash = header.Hash
+			if _, err = bl.WriteBloom(chain, ToBloomPath(indexFn)); err != nil {
+				return false, err
+			}
+
+			// Success. Remove the backup so it doesn't replace the orignal
+			os.Remove(backupFn)
+			return true, nil
+
+		} else {
+			return false, err
+		}
+
+	} else {
+		return false, err
+	}
+}
+
+// Size32 returns the size the chunk's index portion has with 32-bit block numbers. For chunks that
+// were upgraded with UpgradeChunk, this is the size recorded in the manifest. For other chunks, it
+// is the size of the file.
+func Size32(path string) (int64, error) {
+	indexFn := ToIndexPath(path)
+	header, err := ReadChunkHeader(indexFn, false)
+	if err != nil {
+		return 0, err
+	}
+
+	size := file.FileSize(indexFn)
+	if header.Is64() {
+		size -= int64(header.AppearanceCount) * (AppRecordWidth64 - AppRecordWidth)
+	}
+	return size, nil
+}
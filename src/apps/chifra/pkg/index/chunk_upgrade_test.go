@@ -0,0 +1,135 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index/bloom"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/unchained"
+)
+
+// helperChunkPath returns the path to a chunk in a temporary index (and cache) folder
+func helperChunkPath(t *testing.T, rng string) string {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	indexPath := t.TempDir()
+	for _, folder := range []string{"finalized", "blooms"} {
+		if err := os.MkdirAll(filepath.Join(indexPath, folder), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(indexPath, "finalized", rng+".bin")
+}
+
+// helperReadChunk returns the appearances of each address in the chunk
+func helperReadChunk(t *testing.T, path string) (IndexHeaderRecord, AddressAppearanceMap) {
+	chunk, err := NewChunkData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chunk.Close()
+
+	addrs := make([]AddressRecord, chunk.Header.AddressCount)
+	for i := range addrs {
+		if err = addrs[i].ReadAddress(chunk.File); err != nil {
+			t.Fatal(err)
+		}
+	}
+	appMap := make(AddressAppearanceMap, len(addrs))
+	for i := range addrs {
+		apps, err := chunk.ReadAppearanceRecords(&addrs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		appMap[addrs[i].Address.Hex()] = apps
+	}
+	return chunk.Header, appMap
+}
+
+func helperBloomHash(t *testing.T, path string) string {
+	bl := bloom.ChunkBloom{}
+	if err := bl.ReadBloom(ToBloomPath(path)); err != nil {
+		t.Fatal(err)
+	}
+	return bl.Header.Hash.Hex()
+}
+
+func TestUpgradeChunk(t *testing.T) {
+	path := helperChunkPath(t, "000000001-000000010")
+	appMap := AddressAppearanceMap{
+		"0x0371a82e4a9d0a4312f3ee2ac9c6958512891372": {{BlockNumber: 1, TransactionId: 0}, {BlockNumber: 9, TransactionId: 4}},
+		"0x3d493c51a916f86d6d1c04824b3a7431e61a3ca3": {{BlockNumber: 10, TransactionId: 2}},
+	}
+	if _, err := WriteChunk("mainnet", path, appMap, 3, false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Chunks are written with 32-bit block numbers when they fit...
+	header, got := helperReadChunk(t, path)
+	if header.Is64() || file.FileSize(path) != HeaderWidth+2*AddrRecordWidth+3*AppRecordWidth {
+		t.Fatal("chunk should have 32-bit block numbers", header.Hash.Hex(), file.FileSize(path))
+	}
+	if !reflect.DeepEqual(got, appMap) {
+		t.Error("wrong appearances", got)
+	}
+
+	size32 := file.FileSize(path)
+	if size, err := Size32(path); size != size32 || err != nil {
+		t.Error("wrong 32-bit size", size, err)
+	}
+
+	// ...and upgraded once
+	if changed, err := UpgradeChunk("mainnet", path); !changed || err != nil {
+		t.Fatal("chunk should have been upgraded", err)
+	}
+	if changed, err := UpgradeChunk("mainnet", path); changed || err != nil {
+		t.Fatal("chunk should have been upgraded only once", err)
+	}
+
+	header, got = helperReadChunk(t, path)
+	if !header.Is64() || file.FileSize(path) != HeaderWidth+2*AddrRecordWidth+3*AppRecordWidth64 {
+		t.Fatal("chunk should have 64-bit block numbers", header.Hash.Hex(), file.FileSize(path))
+	}
+	if !reflect.DeepEqual(got, appMap) {
+		t.Error("wrong appearances after upgrade", got)
+	}
+	if hash := helperBloomHash(t, path); hash != unchained.HeaderMagicHash64 {
+		t.Error("wrong bloom hash after upgrade", hash)
+	}
+	if _, err := ReadChunkHeader(path, true); err != nil {
+		t.Error(err)
+	}
+
+	// The manifest's sizes are those of 32-bit chunks
+	if size, err := Size32(path); size != size32 || err != nil {
+		t.Error("wrong 32-bit size after upgrade", size, "expected", size32, err)
+	}
+}
+
+func TestWriteChunk64(t *testing.T) {
+	path := helperChunkPath(t, "4999999999-5000000001")
+	appMap := AddressAppearanceMap{
+		"0xe1c15164dcfe79431f8421b5a311a829cf0907f3": {{BlockNumber: 4999999999, TransactionId: 1}, {BlockNumber: 5000000001, TransactionId: 0}},
+	}
+	if _, err := WriteChunk("mainnet", path, appMap, 2, false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	header, got := helperReadChunk(t, path)
+	if !header.Is64() || header.Hash != base.HexToHash(unchained.HeaderMagicHash64) {
+		t.Fatal("chunk should have 64-bit block numbers", header.Hash.Hex())
+	}
+	if !reflect.DeepEqual(got, appMap) {
+		t.Error("wrong appearances", got)
+	}
+	if hash := helperBloomHash(t, path); hash != unchained.HeaderMagicHash64 {
+		t.Error("wrong bloom hash", hash)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/manifest"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/pinning"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/unchained"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/version"
 	"github.com/ethereum/go-ethereum/crypto"
 )
@@ -106,12 +107,19 @@ func WriteChunk(chain, fileName string, addrAppearanceMap AddressAppearanceMap,
 			// defer fp.Close() // Note -- we don't defer because we want to close the file and possibly pin it below...
 
 			_, _ = fp.Seek(0, io.SeekStart) // already true, but can't hurt
+			// Chunks are written with 32-bit block numbers (as published in the Unchained Index) unless
+			// one of the block numbers is too large
 			header := IndexHeaderRecord{
 				Magic:           file.MagicNumber,
 				Hash:            base.BytesToHash(crypto.Keccak256([]byte(version.ManifestVersion))),
 				AddressCount:    uint32(len(addressTable)),
 				AppearanceCount: uint32(len(appearanceTable)),
 			}
+			if Needs64(appearanceTable) {
+				header.Hash = base.HexToHash(unchained.HeaderMagicHash64)
+			}
+			bl.Header.Hash = header.Hash
+
 			if err = binary.Write(fp, binary.LittleEndian, header); err != nil {
 				return nil, err
 			}
@@ -120,7 +128,7 @@ func WriteChunk(chain, fileName string, addrAppearanceMap AddressAppearanceMap,
 				return nil, err
 			}
 
-			if err = binary.Write(fp, binary.LittleEndian, appearanceTable); err != nil {
+			if err = WriteAppearanceRecords(fp, header.AppRecordWidth(), appearanceTable); err != nil {
 				return nil, err
 			}
 
@@ -161,7 +161,7 @@ func HasValidIndexHeader(fileName string) (bool, error) {
 		msg := fmt.Sprintf("%s: Magic number expected (0x%x) got (0x%x)", rng, header.Magic, file.MagicNumber)
 		return false, errors.New(msg)
 
-	} else if header.Hash.Hex() != unchained.HeaderMagicHash {
+	} else if !unchained.IsHeaderMagicHash(header.Hash.Hex()) {
 		msg := fmt.Sprintf("%s: Header hash expected (%s) got (%s)", rng, header.Hash.Hex(), unchained.HeaderMagicHash)
 		return false, errors.New(msg)
 	}
@@ -0,0 +1,279 @@
+package ledger
+
+import (
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/names"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// Entity is a group of addresses (for example, the wallets of a single organization) that are
+// reconciled as if they were a single account. The first member is the entity's primary address.
+type Entity struct {
+	Name     string
+	Members  []base.Address
+	isMember map[base.Address]bool
+}
+
+// NewEntity returns an Entity with the given (de-duplicated) members
+func NewEntity(name string, members []base.Address) *Entity {
+	e := &Entity{
+		Name:     name,
+		isMember: make(map[base.Address]bool, len(members)),
+	}
+	for _, member := range members {
+		if !e.isMember[member] {
+			e.isMember[member] = true
+			e.Members = append(e.Members, member)
+		}
+	}
+	return e
+}
+
+// LoadEntity returns the Entity described by spec, which is a comma separated list of addresses, the
+// path to a file containing one address per line, or a tag from the names database. All named
+// addresses carrying the tag are members of the entity. If inlineOnly is true (as it is for the API,
+// where reading files or the names database on the server's behalf is not allowed), only a list of
+// addresses is accepted.
+func LoadEntity(chain, spec string, inlineOnly bool) (*Entity, error) {
+	members := []base.Address{}
+	name := spec
+
+	if isAddressList(spec) {
+		name = "entity"
+		for _, addr := range strings.Split(spec, ",") {
+			members = append(members, base.HexToAddress(strings.TrimSpace(addr)))
+		}
+
+	} else if inlineOnly {
+		return nil, fmt.Errorf("the entity must be a comma separated list of addresses")
+
+	} else if file.FileExists(spec) {
+		name = strings.TrimSuffix(filepath.Base(spec), filepath.Ext(spec))
+		for i, line := range file.AsciiFileToLines(spec) {
+			line = strings.TrimSpace(line)
+			if len(line) == 0 || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if !base.IsValidAddress(line) {
+				return nil, fmt.Errorf("invalid address on line %d of entity file %s", i+1, spec)
+			}
+			members = append(members, base.HexToAddress(line))
+		}
+
+	} else {
+		parts := names.Custom | names.Regular
+		namesMap, err := names.LoadNamesMap(chain, parts, nil)
+		if err != nil {
+			return nil, err
+		}
+		for addr, n := range namesMap {
+			if strings.EqualFold(n.Tags, spec) {
+				members = append(members, addr)
+			}
+		}
+		sort.Slice(members, func(i, j int) bool {
+			return members[i].Hex() < members[j].Hex()
+		})
+	}
+
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no addresses found for entity %s", spec)
+	}
+
+	return NewEntity(name, members), nil
+}
+
+// isAddressList returns true if spec is one or more comma separated addresses
+func isAddressList(spec string) bool {
+	for _, addr := range strings.Split(spec, ",") {
+		if !base.IsValidAddress(strings.TrimSpace(addr)) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsMember returns true if the address is one of the entity's members
+func (e *Entity) IsMember(addr base.Address) bool {
+	return e.isMember[addr]
+}
+
+// balanceFunc returns holder's balance of the asset described by the statement at the given block
+type balanceFunc func(s *types.SimpleStatement, holder base.Address, bn base.Blknum) *big.Int
+
+// Consolidate merges the statements produced by each member's Ledger into statements for the
+// entity as a whole. Statements for the same asset movement (same transaction and log) are
+// combined, transfers between members are netted out (gas is kept), and the balances become the
+// entity's per-asset balances. Statements with nothing left after netting are dropped.
+func (e *Entity) Consolidate(conn *rpc.Connection, statements []*types.SimpleStatement) []*types.SimpleStatement {
+	return e.consolidate(statements, func(s *types.SimpleStatement, holder base.Address, bn base.Blknum) *big.Int {
+		var bal *big.Int
+		blockStr := fmt.Sprintf("0x%x", bn)
+		if s.IsEth() {
+			bal, _ = conn.GetBalanceAt(holder, bn)
+		} else if s.TokenType.IsNft() {
+			bal, _ = conn.GetNftBalanceAt(s.AssetAddr, holder, &s.TokenId, s.TokenType, blockStr)
+		} else {
+			bal, _ = conn.GetTokenBalanceAt(s.AssetAddr, holder, blockStr)
+		}
+		return bal
+	})
+}
+
+func (e *Entity) consolidate(statements []*types.SimpleStatement, balanceAt balanceFunc) []*types.SimpleStatement {
+	type groupKey struct {
+		bn, txid, logIndex base.Blknum
+		asset              string
+	}
+
+	groups := make([][]*types.SimpleStatement, 0, len(statements))
+	groupMap := make(map[groupKey]int)
+	for _, s := range sortedStatements(statements) {
+		key := groupKey{s.BlockNumber, s.TransactionIndex, s.LogIndex, assetKey(s)}
+		if idx, ok := groupMap[key]; ok {
+			groups[idx] = append(groups[idx], s)
+		} else {
+			groupMap[key] = len(groups)
+			groups = append(groups, []*types.SimpleStatement{s})
+		}
+	}
+
+	// balances holds the last known balance of each member for each asset
+	balances := make(map[string]map[base.Address]*big.Int)
+	prevApps := make(map[string]base.Blknum)
+
+	ret := make([]*types.SimpleStatement, 0, len(groups))
+	for _, group := range groups {
+		first := group[0]
+		aKey := assetKey(first)
+
+		present := make(map[base.Address]bool, len(group))
+		for _, s := range group {
+			present[s.AccountedFor] = true
+		}
+
+		bals := balances[aKey]
+		if bals == nil {
+			bals = make(map[base.Address]*big.Int, len(e.Members))
+			for _, member := range e.Members {
+				bal := new(big.Int)
+				if !present[member] && first.BlockNumber > 0 {
+					if b := balanceAt(first, member, first.BlockNumber-1); b != nil {
+						bal = b
+					}
+				}
+				bals[member] = bal
+			}
+			balances[aKey] = bals
+		}
+
+		merged := types.SimpleStatement{
+			AccountedFor:       e.Members[0],
+			Sender:             first.Sender,
+			Recipient:          first.Recipient,
+			BlockNumber:        first.BlockNumber,
+			TransactionIndex:   first.TransactionIndex,
+			TransactionHash:    first.TransactionHash,
+			LogIndex:           first.LogIndex,
+			Timestamp:          first.Timestamp,
+			AssetAddr:          first.AssetAddr,
+			AssetSymbol:        first.AssetSymbol,
+			Decimals:           first.Decimals,
+			PriceSource:        first.PriceSource,
+			ReconciliationType: first.ReconciliationType,
+			PrevAppBlk:         prevApps[aKey],
+			TokenType:          first.TokenType,
+		}
+		merged.TokenId.Set(&first.TokenId)
+		merged.PrevBal.Set(sumBalances(bals))
+
+		seen := make(map[base.Address]bool, len(group))
+		for _, s := range group {
+			if !seen[s.AccountedFor] {
+				seen[s.AccountedFor] = true
+				bals[s.AccountedFor] = new(big.Int).Set(&s.BegBal)
+			}
+		}
+		merged.BegBal.Set(sumBalances(bals))
+
+		for _, s := range group {
+			if merged.SpotPrice == 0 && s.SpotPrice != 0 {
+				merged.SpotPrice = s.SpotPrice
+				merged.PriceSource = s.PriceSource
+			}
+			merged.AmountIn.Add(&merged.AmountIn, &s.AmountIn)
+			merged.AmountOut.Add(&merged.AmountOut, &s.AmountOut)
+			if !e.IsMember(counterparty(s)) {
+				merged.InternalIn.Add(&merged.InternalIn, &s.InternalIn)
+				merged.InternalOut.Add(&merged.InternalOut, &s.InternalOut)
+				merged.SelfDestructIn.Add(&merged.SelfDestructIn, &s.SelfDestructIn)
+				merged.SelfDestructOut.Add(&merged.SelfDestructOut, &s.SelfDestructOut)
+			}
+			merged.GasOut.Add(&merged.GasOut, &s.GasOut)
+			merged.MinerBaseRewardIn.Add(&merged.MinerBaseRewardIn, &s.MinerBaseRewardIn)
+			merged.MinerNephewRewardIn.Add(&merged.MinerNephewRewardIn, &s.MinerNephewRewardIn)
+			merged.MinerTxFeeIn.Add(&merged.MinerTxFeeIn, &s.MinerTxFeeIn)
+			merged.MinerUncleRewardIn.Add(&merged.MinerUncleRewardIn, &s.MinerUncleRewardIn)
+			merged.PrefundIn.Add(&merged.PrefundIn, &s.PrefundIn)
+			merged.WithdrawalIn.Add(&merged.WithdrawalIn, &s.WithdrawalIn)
+			merged.CorrectingIn.Add(&merged.CorrectingIn, &s.CorrectingIn)
+			merged.CorrectingOut.Add(&merged.CorrectingOut, &s.CorrectingOut)
+			if len(s.CorrectingReason) > 0 {
+				merged.CorrectingReason = s.CorrectingReason
+			}
+			bals[s.AccountedFor] = new(big.Int).Set(&s.EndBal)
+		}
+		merged.EndBal.Set(sumBalances(bals))
+
+		// The value moved by the transaction (or log) itself has a single sender and recipient. If
+		// both are members, it appears as much in one member's AmountOut as in the other's AmountIn.
+		between := new(big.Int).Set(&merged.AmountOut)
+		if merged.AmountIn.Cmp(between) < 0 {
+			between.Set(&merged.AmountIn)
+		}
+		merged.AmountIn.Sub(&merged.AmountIn, between)
+		merged.AmountOut.Sub(&merged.AmountOut, between)
+
+		if merged.TotalIn().Sign() == 0 && merged.TotalOut().Sign() == 0 {
+			continue
+		}
+
+		prevApps[aKey] = merged.BlockNumber
+		ret = append(ret, &merged)
+	}
+
+	return ret
+}
+
+// counterparty returns the address on the other side of the statement's internal transfers and self
+// destructs. Transfers between two members are netted out.
+func counterparty(s *types.SimpleStatement) base.Address {
+	if s.Sender == s.AccountedFor {
+		return s.Recipient
+	}
+	return s.Sender
+}
+
+// assetKey identifies an asset (or, for NFTs, a single token ID) held by the entity
+func assetKey(s *types.SimpleStatement) string {
+	if s.TokenType.IsNft() {
+		return s.AssetAddr.Hex() + "-" + s.TokenId.String()
+	}
+	return s.AssetAddr.Hex()
+}
+
+func sumBalances(bals map[base.Address]*big.Int) *big.Int {
+	sum := new(big.Int)
+	for _, bal := range bals {
+		sum.Add(sum, bal)
+	}
+	return sum
+}
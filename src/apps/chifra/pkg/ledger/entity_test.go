@@ -0,0 +1,163 @@
+package ledger
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func entityStatement(acct, sender, recipient base.Address, bn base.Blknum, beg, in, out, gas, end int64) *types.SimpleStatement {
+	s := &types.SimpleStatement{
+		AccountedFor: acct,
+		Sender:       sender,
+		Recipient:    recipient,
+		BlockNumber:  bn,
+		AssetAddr:    base.FAKE_ETH_ADDRESS,
+	}
+	s.BegBal.SetInt64(beg)
+	s.AmountIn.SetInt64(in)
+	s.AmountOut.SetInt64(out)
+	s.GasOut.SetInt64(gas)
+	s.EndBal.SetInt64(end)
+	return s
+}
+
+func TestEntityConsolidate(t *testing.T) {
+	a := base.HexToAddress("0x1111111111111111111111111111111111111111")
+	b := base.HexToAddress("0x2222222222222222222222222222222222222222")
+	x := base.HexToAddress("0x3333333333333333333333333333333333333333")
+	token := base.HexToAddress("0x4444444444444444444444444444444444444444")
+
+	tokenOut := entityStatement(a, a, b, 30, 10, 0, 10, 0, 0)
+	tokenOut.AssetAddr = token
+	tokenOut.LogIndex = 2
+	tokenIn := entityStatement(b, a, b, 30, 0, 10, 0, 0, 10)
+	tokenIn.AssetAddr = token
+	tokenIn.LogIndex = 2
+
+	statements := []*types.SimpleStatement{
+		entityStatement(b, a, b, 20, 50, 40, 0, 0, 90),
+		entityStatement(a, x, a, 10, 0, 100, 0, 0, 100),
+		entityStatement(a, a, b, 20, 100, 0, 40, 1, 59),
+		tokenOut,
+		tokenIn,
+	}
+
+	queried := 0
+	balanceAt := func(s *types.SimpleStatement, holder base.Address, bn base.Blknum) *big.Int {
+		queried++
+		if holder != b || bn != 9 {
+			t.Error("unexpected balance query", holder.Hex(), bn)
+		}
+		return big.NewInt(50)
+	}
+
+	e := NewEntity("test", []base.Address{a, b, a})
+	if len(e.Members) != 2 || !e.IsMember(b) || e.IsMember(x) {
+		t.Fatal("wrong members", e.Members)
+	}
+
+	consolidated := e.consolidate(statements, balanceAt)
+	if queried != 1 {
+		t.Error("expected one balance query, got", queried)
+	}
+	if len(consolidated) != 2 {
+		t.Fatal("expected the internal token transfer to be netted out, got", len(consolidated))
+	}
+
+	income := consolidated[0]
+	if income.AccountedFor != a || income.BegBal.Int64() != 50 || income.AmountIn.Int64() != 100 ||
+		income.EndBal.Int64() != 150 || !income.Reconciled() {
+		t.Error("wrong external income", income)
+	}
+
+	internal := consolidated[1]
+	if internal.AmountIn.Sign() != 0 || internal.AmountOut.Sign() != 0 || internal.GasOut.Int64() != 1 ||
+		internal.BegBal.Int64() != 150 || internal.EndBal.Int64() != 149 || internal.PrevAppBlk != 10 || !internal.Reconciled() {
+		t.Error("wrong internal transfer", internal)
+	}
+}
+
+// TestEntityConsolidateInternal checks that a transaction between two members which also sends
+// value (in its traces) to an outside address is not netted out
+func TestEntityConsolidateInternal(t *testing.T) {
+	a := base.HexToAddress("0x1111111111111111111111111111111111111111")
+	b := base.HexToAddress("0x2222222222222222222222222222222222222222")
+	x := base.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	fromA := entityStatement(a, a, b, 20, 100, 0, 40, 1, 59)
+	toB := entityStatement(b, b, x, 20, 0, 40, 0, 0, 10)
+	toB.InternalOut.SetInt64(30)
+
+	noBalances := func(s *types.SimpleStatement, holder base.Address, bn base.Blknum) *big.Int {
+		t.Error("unexpected balance query", holder.Hex(), bn)
+		return nil
+	}
+
+	e := NewEntity("test", []base.Address{a, b})
+	consolidated := e.consolidate([]*types.SimpleStatement{fromA, toB}, noBalances)
+	if len(consolidated) != 1 {
+		t.Fatal("expected one statement, got", len(consolidated))
+	}
+
+	s := consolidated[0]
+	if s.AmountIn.Sign() != 0 || s.AmountOut.Sign() != 0 || s.InternalOut.Int64() != 30 || s.GasOut.Int64() != 1 ||
+		s.BegBal.Int64() != 100 || s.EndBal.Int64() != 69 || !s.Reconciled() {
+		t.Error("wrong statement", s)
+	}
+}
+
+func TestLoadEntityFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "treasury.txt")
+	contents := "# the treasury\n0x1111111111111111111111111111111111111111\n\n0x2222222222222222222222222222222222222222\n"
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := LoadEntity("mainnet", path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Name != "treasury" || len(e.Members) != 2 || e.Members[1] != base.HexToAddress("0x2222222222222222222222222222222222222222") {
+		t.Error("wrong entity", e.Name, e.Members)
+	}
+
+	if err := os.WriteFile(path, []byte("not-an-address\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadEntity("mainnet", path, false); err == nil {
+		t.Error("expected an error for an invalid address")
+	}
+}
+
+func TestLoadEntityInline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "treasury.txt")
+	if err := os.WriteFile(path, []byte("0x1111111111111111111111111111111111111111\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := LoadEntity("mainnet", "0x1111111111111111111111111111111111111111, 0x2222222222222222222222222222222222222222", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Members) != 2 || e.Members[1] != base.HexToAddress("0x2222222222222222222222222222222222222222") {
+		t.Error("wrong entity", e.Name, e.Members)
+	}
+
+	// Files (and names tags) are not read for the API
+	if _, err := LoadEntity("mainnet", path, true); err == nil {
+		t.Error("expected an error for a file when only inline addresses are allowed")
+	}
+
+	if err := os.WriteFile(path, []byte("secret-contents\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadEntity("mainnet", path, false); err == nil || strings.Contains(err.Error(), "secret-contents") {
+		t.Error("expected an error that does not include the file's contents", err)
+	}
+}
@@ -0,0 +1,369 @@
+package ledger
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/pricing"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/tslib"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// longTermSeconds is the holding period after which a disposal is considered long term
+const longTermSeconds = 365 * 24 * 60 * 60
+
+// lot is a quantity of an asset acquired at a single time for a single unit price. A lot acquired
+// without a known price is unpriced and takes no part in realized or unrealized gains.
+type lot struct {
+	qty       *big.Int
+	unitCost  float64
+	priced    bool
+	timestamp base.Timestamp
+}
+
+// assetLots carries the open lots of a single asset (or, for NFTs, a single token ID) along
+// with the values accumulated during the current period
+type assetLots struct {
+	addr        base.Address
+	symbol      string
+	decimals    uint64
+	tokenId     big.Int
+	tokenType   types.TokenType
+	lots        []*lot
+	lastPrice   float64
+	active      bool
+	qtyIn       big.Int
+	qtyOut      big.Int
+	unpricedOut big.Int
+	proceeds    float64
+	cost        float64
+	shortTerm   float64
+	longTerm    float64
+}
+
+// blockFunc returns the last block at or before the given timestamp
+type blockFunc func(ts base.Timestamp) (base.Blknum, bool)
+
+// priceFunc returns the spot price of the asset described by the statement at the statement's block
+type priceFunc func(s *types.SimpleStatement) float64
+
+// GetGainsFromStatements consumes the statements produced by a Ledger for accountedFor and returns
+// the realized and unrealized gains for each asset in each period. Inflows open new lots priced at
+// the statement's spot price. Outflows (including gas) close lots in the order given by method
+// (one of `fifo`, `lifo`, or `hifo`). Period is one of `year`, `quarter`, or `month`. A row is
+// reported for every period from the first statement's through the one containing the timestamp
+// `through`. Assets held through a period without statements are priced at the period's last block.
+// Quantities bought or sold without a price are reported as unpriced rather than given a gain.
+func GetGainsFromStatements(conn *rpc.Connection, testMode bool, accountedFor base.Address, statements []*types.SimpleStatement, method, period string, through base.Timestamp) []*types.SimpleGain {
+	blockAt := func(ts base.Timestamp) (base.Blknum, bool) {
+		bn, err := tslib.FromTsToBn(conn.Chain, ts)
+		return bn, err == nil
+	}
+	priceAt := func(s *types.SimpleStatement) float64 {
+		price, _, _ := pricing.PriceUsd(conn, testMode, s)
+		return price
+	}
+	return getGains(accountedFor, statements, method, period, through, blockAt, priceAt)
+}
+
+func getGains(accountedFor base.Address, statements []*types.SimpleStatement, method, period string, through base.Timestamp, blockAt blockFunc, priceAt priceFunc) []*types.SimpleGain {
+	sorted := sortedStatements(statements)
+
+	gains := make([]*types.SimpleGain, 0)
+	assets := make(map[string]*assetLots)
+	keys := make([]string, 0)
+
+	// closeQuiet reports each period without statements that follows the one containing from
+	// and ends before (or, if inclusive, contains) until
+	closeQuiet := func(from, until base.Timestamp, inclusive bool) {
+		for ts := periodEnd(from, period) + 1; ts <= until; ts = periodEnd(ts, period) + 1 {
+			p := periodOf(ts, period)
+			end := periodEnd(ts, period)
+			if end >= until {
+				if !inclusive {
+					return
+				}
+				end = until
+			}
+			if bn, ok := blockAt(end); ok {
+				gains = append(gains, closeQuietPeriod(accountedFor, assets, keys, p, method, bn, end, priceAt)...)
+			}
+		}
+	}
+
+	curPeriod := ""
+	var lastBlock base.Blknum
+	var lastTs base.Timestamp
+	for _, s := range sorted {
+		p := periodOf(s.Timestamp, period)
+		if p != curPeriod && len(curPeriod) > 0 {
+			gains = append(gains, closePeriod(accountedFor, assets, keys, curPeriod, method, lastBlock, lastTs)...)
+			closeQuiet(lastTs, s.Timestamp, false)
+		}
+		curPeriod = p
+		lastBlock = s.BlockNumber
+		lastTs = s.Timestamp
+
+		key := assetKey(s)
+		asset := assets[key]
+		if asset == nil {
+			asset = &assetLots{
+				addr:      s.AssetAddr,
+				symbol:    s.AssetSymbol,
+				decimals:  s.Decimals,
+				tokenId:   s.TokenId,
+				tokenType: s.TokenType,
+			}
+			// The history may start with a balance we know nothing about. We treat it as if
+			// it were acquired at the time (and price) of the first statement.
+			if s.BegBal.Sign() > 0 {
+				asset.lots = append(asset.lots, &lot{
+					qty:       new(big.Int).Set(&s.BegBal),
+					unitCost:  s.SpotPrice,
+					priced:    s.SpotPrice > 0,
+					timestamp: s.Timestamp,
+				})
+			}
+			assets[key] = asset
+			keys = append(keys, key)
+		}
+
+		asset.active = true
+		if s.SpotPrice > 0 {
+			asset.lastPrice = s.SpotPrice
+		}
+
+		if in := s.TotalIn(); in.Sign() > 0 {
+			asset.qtyIn.Add(&asset.qtyIn, in)
+			asset.lots = append(asset.lots, &lot{
+				qty:       in,
+				unitCost:  s.SpotPrice,
+				priced:    s.SpotPrice > 0,
+				timestamp: s.Timestamp,
+			})
+		}
+
+		if out := s.TotalOut(); out.Sign() > 0 {
+			asset.qtyOut.Add(&asset.qtyOut, out)
+			asset.dispose(out, s.SpotPrice, s.Timestamp, method)
+		}
+	}
+
+	if len(curPeriod) > 0 {
+		gains = append(gains, closePeriod(accountedFor, assets, keys, curPeriod, method, lastBlock, lastTs)...)
+		closeQuiet(lastTs, through, true)
+	}
+
+	return gains
+}
+
+// closeQuietPeriod reports on every asset still held at the end of a period without statements,
+// pricing each at the period's last block (or, failing that, at its last known price)
+func closeQuietPeriod(accountedFor base.Address, assets map[string]*assetLots, keys []string, period, method string, bn base.Blknum, ts base.Timestamp, priceAt priceFunc) []*types.SimpleGain {
+	for _, key := range keys {
+		asset := assets[key]
+		if endBal, _, _ := asset.holdings(); endBal.Sign() == 0 {
+			continue
+		}
+		s := types.SimpleStatement{
+			AssetAddr:   asset.addr,
+			AssetSymbol: asset.symbol,
+			Decimals:    asset.decimals,
+			BlockNumber: bn,
+			Timestamp:   ts,
+			TokenType:   asset.tokenType,
+		}
+		s.TokenId.Set(&asset.tokenId)
+		if price := priceAt(&s); price > 0 {
+			asset.lastPrice = price
+		}
+	}
+	return closePeriod(accountedFor, assets, keys, period, method, bn, ts)
+}
+
+// closePeriod reports on every asset that was active or still held during the period and
+// resets each asset's per-period values
+func closePeriod(accountedFor base.Address, assets map[string]*assetLots, keys []string, period, method string, bn base.Blknum, ts base.Timestamp) []*types.SimpleGain {
+	ret := make([]*types.SimpleGain, 0, len(keys))
+	for _, key := range keys {
+		asset := assets[key]
+		endBal, pricedBal, heldCost := asset.holdings()
+		if !asset.active && endBal.Sign() == 0 {
+			continue
+		}
+
+		// Only priced lots have a cost basis to compare with their market value
+		if asset.lastPrice == 0 {
+			pricedBal, heldCost = new(big.Int), 0
+		}
+		marketValue := toUnits(pricedBal, asset.decimals) * asset.lastPrice
+		ret = append(ret, &types.SimpleGain{
+			AccountedFor:   accountedFor,
+			AssetAddr:      asset.addr,
+			AssetSymbol:    asset.symbol,
+			BlockNumber:    bn,
+			CostBasis:      asset.cost,
+			Decimals:       asset.decimals,
+			EndBal:         *endBal,
+			HeldCostBasis:  heldCost,
+			LongTermGain:   asset.longTerm,
+			MarketValue:    marketValue,
+			Method:         method,
+			Period:         period,
+			Proceeds:       asset.proceeds,
+			QuantityIn:     *new(big.Int).Set(&asset.qtyIn),
+			QuantityOut:    *new(big.Int).Set(&asset.qtyOut),
+			RealizedGain:   asset.proceeds - asset.cost,
+			ShortTermGain:  asset.shortTerm,
+			SpotPrice:      asset.lastPrice,
+			Timestamp:      ts,
+			TokenId:        asset.tokenId,
+			TokenType:      asset.tokenType,
+			UnpricedBal:    *new(big.Int).Sub(endBal, pricedBal),
+			UnpricedOut:    *new(big.Int).Set(&asset.unpricedOut),
+			UnrealizedGain: marketValue - heldCost,
+		})
+
+		asset.active = false
+		asset.qtyIn.SetUint64(0)
+		asset.qtyOut.SetUint64(0)
+		asset.unpricedOut.SetUint64(0)
+		asset.proceeds, asset.cost, asset.shortTerm, asset.longTerm = 0, 0, 0, 0
+	}
+	return ret
+}
+
+// dispose removes qty from the asset's open lots in the order given by method and accumulates
+// the proceeds, cost, and gains of the disposal. Any amount that is sold without a price, taken
+// from an unpriced lot, or not covered by open lots has no known gain and is counted as unpriced.
+func (a *assetLots) dispose(qty *big.Int, price float64, ts base.Timestamp, method string) {
+	remaining := new(big.Int).Set(qty)
+	for remaining.Sign() > 0 && len(a.lots) > 0 {
+		idx := a.nextLot(method)
+		l := a.lots[idx]
+
+		take := remaining
+		if l.qty.Cmp(remaining) < 0 {
+			take = l.qty
+		}
+		if price > 0 && l.priced {
+			units := toUnits(take, a.decimals)
+			a.record(units*price, units*l.unitCost, ts-l.timestamp)
+		} else {
+			a.unpricedOut.Add(&a.unpricedOut, take)
+		}
+
+		remaining = new(big.Int).Sub(remaining, take)
+		l.qty = new(big.Int).Sub(l.qty, take)
+		if l.qty.Sign() == 0 {
+			a.lots = append(a.lots[:idx], a.lots[idx+1:]...)
+		}
+	}
+
+	a.unpricedOut.Add(&a.unpricedOut, remaining)
+}
+
+// record accumulates a single (partial) disposal
+func (a *assetLots) record(proceeds, cost float64, held base.Timestamp) {
+	a.proceeds += proceeds
+	a.cost += cost
+	if held > longTermSeconds {
+		a.longTerm += proceeds - cost
+	} else {
+		a.shortTerm += proceeds - cost
+	}
+}
+
+// nextLot returns the index of the lot to be relieved next given the method
+func (a *assetLots) nextLot(method string) int {
+	switch method {
+	case "lifo":
+		return len(a.lots) - 1
+	case "hifo":
+		best := 0
+		for i, l := range a.lots {
+			if l.unitCost > a.lots[best].unitCost {
+				best = i
+			}
+		}
+		return best
+	default:
+		return 0
+	}
+}
+
+// holdings returns the quantity of the asset's open lots, the quantity of its priced lots, and
+// the cost basis of its priced lots
+func (a *assetLots) holdings() (*big.Int, *big.Int, float64) {
+	qty := new(big.Int)
+	priced := new(big.Int)
+	cost := 0.0
+	for _, l := range a.lots {
+		qty.Add(qty, l.qty)
+		if l.priced {
+			priced.Add(priced, l.qty)
+			cost += toUnits(l.qty, a.decimals) * l.unitCost
+		}
+	}
+	return qty, priced, cost
+}
+
+// toUnits converts an amount in the asset's smallest denomination to whole units
+func toUnits(amount *big.Int, decimals uint64) float64 {
+	f := new(big.Float).SetInt(amount)
+	if decimals > 0 {
+		divisor := new(big.Int).Exp(big.NewInt(10), new(big.Int).SetUint64(decimals), nil)
+		f.Quo(f, new(big.Float).SetInt(divisor))
+	}
+	ret, _ := f.Float64()
+	return ret
+}
+
+// sortedStatements returns a copy of the statements in chronological order
+func sortedStatements(statements []*types.SimpleStatement) []*types.SimpleStatement {
+	sorted := make([]*types.SimpleStatement, len(statements))
+	copy(sorted, statements)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].BlockNumber == sorted[j].BlockNumber {
+			if sorted[i].TransactionIndex == sorted[j].TransactionIndex {
+				return sorted[i].LogIndex < sorted[j].LogIndex
+			}
+			return sorted[i].TransactionIndex < sorted[j].TransactionIndex
+		}
+		return sorted[i].BlockNumber < sorted[j].BlockNumber
+	})
+	return sorted
+}
+
+// periodEnd returns the last second of the period containing the timestamp
+func periodEnd(ts base.Timestamp, period string) base.Timestamp {
+	t := time.Unix(ts, 0).UTC()
+	switch period {
+	case "month":
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start.AddDate(0, 1, 0).Unix() - 1
+	case "quarter":
+		start := time.Date(t.Year(), time.Month((int(t.Month())-1)/3*3+1), 1, 0, 0, 0, 0, time.UTC)
+		return start.AddDate(0, 3, 0).Unix() - 1
+	default:
+		start := time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return start.AddDate(1, 0, 0).Unix() - 1
+	}
+}
+
+// periodOf returns the name of the period containing the timestamp
+func periodOf(ts base.Timestamp, period string) string {
+	t := time.Unix(ts, 0).UTC()
+	switch period {
+	case "month":
+		return fmt.Sprintf("%04d-%02d", t.Year(), t.Month())
+	case "quarter":
+		return fmt.Sprintf("%04d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+	default:
+		return fmt.Sprintf("%04d", t.Year())
+	}
+}
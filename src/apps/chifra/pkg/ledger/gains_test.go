@@ -0,0 +1,170 @@
+package ledger
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func gainStatement(bn base.Blknum, date string, in, out int64, price float64) *types.SimpleStatement {
+	t, _ := time.Parse("2006-01-02", date)
+	s := &types.SimpleStatement{
+		AssetAddr:   base.FAKE_ETH_ADDRESS,
+		AssetSymbol: "TST",
+		BlockNumber: bn,
+		Timestamp:   t.Unix(),
+		SpotPrice:   price,
+	}
+	s.AmountIn.SetInt64(in)
+	s.AmountOut.SetInt64(out)
+	return s
+}
+
+func TestGetGainsFromStatements(t *testing.T) {
+	statements := []*types.SimpleStatement{
+		gainStatement(4, "2021-08-01", 0, 5, 4),
+		gainStatement(1, "2020-01-01", 10, 0, 1),
+		gainStatement(2, "2020-06-01", 10, 0, 3),
+		gainStatement(3, "2021-07-01", 10, 0, 2),
+	}
+
+	expected := map[string]struct {
+		cost, short, long float64
+	}{
+		"fifo": {5, 0, 15},
+		"lifo": {10, 10, 0},
+		"hifo": {15, 0, 5},
+	}
+
+	acct := base.HexToAddress("0xf503017d7baf7fbc0fff7492b751025c6a78179b")
+	for method, want := range expected {
+		gains := getGains(acct, statements, method, "year", 0, nil, nil)
+		if len(gains) != 2 {
+			t.Fatal(method, "expected two periods, got", len(gains))
+		}
+
+		first := gains[0]
+		if first.Period != "2020" || first.QuantityIn.Int64() != 20 || first.EndBal.Int64() != 20 ||
+			first.RealizedGain != 0 || first.HeldCostBasis != 40 || first.MarketValue != 60 || first.UnrealizedGain != 20 {
+			t.Error(method, "wrong first period", first)
+		}
+
+		second := gains[1]
+		if second.Period != "2021" || second.BlockNumber != 4 || second.QuantityIn.Int64() != 10 ||
+			second.QuantityOut.Int64() != 5 || second.EndBal.Int64() != 25 {
+			t.Error(method, "wrong second period", second)
+		}
+		if second.Proceeds != 20 || second.CostBasis != want.cost || second.RealizedGain != 20-want.cost ||
+			second.ShortTermGain != want.short || second.LongTermGain != want.long {
+			t.Error(method, "wrong realized gain", second)
+		}
+		if second.HeldCostBasis != 60-want.cost || second.UnrealizedGain != second.MarketValue-second.HeldCostBasis {
+			t.Error(method, "wrong unrealized gain", second)
+		}
+	}
+}
+
+func TestGetGainsWithOpeningBalance(t *testing.T) {
+	s1 := gainStatement(1, "2022-02-01", 0, 0, 2)
+	s1.BegBal.SetInt64(4)
+	s1.Decimals = 1
+	s2 := gainStatement(2, "2022-05-01", 0, 60, 5)
+	s2.Decimals = 1
+
+	gains := getGains(base.Address{}, []*types.SimpleStatement{s1, s2}, "fifo", "quarter", 0, nil, nil)
+	if len(gains) != 2 || gains[0].Period != "2022-Q1" || gains[1].Period != "2022-Q2" {
+		t.Fatal("wrong periods", gains)
+	}
+
+	// 0.4 units from the opening lot at 2, the remaining 5.6 units have no known cost
+	q2 := gains[1]
+	if q2.CostBasis != 0.8 || q2.Proceeds != 2 || q2.EndBal.Sign() != 0 || q2.UnrealizedGain != 0 || q2.UnpricedOut.Int64() != 56 {
+		t.Error("wrong disposal beyond open lots", q2)
+	}
+	if big.NewInt(60).Cmp(&q2.QuantityOut) != 0 {
+		t.Error("wrong quantity out", q2.QuantityOut.String())
+	}
+}
+
+func TestGetGainsUnpriced(t *testing.T) {
+	statements := []*types.SimpleStatement{
+		gainStatement(1, "2020-01-01", 10, 0, 0),
+		gainStatement(2, "2020-02-01", 10, 0, 2),
+		gainStatement(3, "2020-03-01", 0, 15, 4),
+		gainStatement(4, "2020-04-01", 0, 2, 0),
+	}
+
+	// The unpriced lot (bought first) is neither a gain when sold nor a loss when held
+	gains := getGains(base.Address{}, statements, "fifo", "year", 0, nil, nil)
+	if len(gains) != 1 {
+		t.Fatal("expected one period, got", len(gains))
+	}
+	g := gains[0]
+	if g.Proceeds != 20 || g.CostBasis != 10 || g.RealizedGain != 10 || g.UnpricedOut.Int64() != 12 {
+		t.Error("wrong realized gain", g)
+	}
+	if g.EndBal.Int64() != 3 || g.UnpricedBal.Sign() != 0 || g.HeldCostBasis != 6 || g.MarketValue != 12 || g.UnrealizedGain != 6 {
+		t.Error("wrong unrealized gain", g)
+	}
+
+	// Without any price, nothing is valued
+	gains = getGains(base.Address{}, statements[:1], "fifo", "year", 0, nil, nil)
+	if g := gains[0]; g.UnpricedBal.Int64() != 10 || g.HeldCostBasis != 0 || g.MarketValue != 0 || g.UnrealizedGain != 0 {
+		t.Error("wrong unpriced balance", g)
+	}
+}
+
+func TestGetGainsQuietPeriods(t *testing.T) {
+	statements := []*types.SimpleStatement{
+		gainStatement(1, "2020-01-15", 10, 0, 1),
+		gainStatement(9, "2020-04-15", 0, 5, 2),
+	}
+
+	through, _ := time.Parse("2006-01-02", "2020-06-10")
+	blockAt := func(ts base.Timestamp) (base.Blknum, bool) {
+		return base.Blknum(time.Unix(ts, 0).UTC().Month()), true
+	}
+	priceAt := func(s *types.SimpleStatement) float64 {
+		return float64(s.BlockNumber)
+	}
+
+	gains := getGains(base.Address{}, statements, "fifo", "month", through.Unix(), blockAt, priceAt)
+	expected := []struct {
+		period     string
+		bn         base.Blknum
+		realized   float64
+		unrealized float64
+	}{
+		{"2020-01", 1, 0, 0},
+		{"2020-02", 2, 0, 10},
+		{"2020-03", 3, 0, 20},
+		{"2020-04", 9, 5, 5},
+		{"2020-05", 5, 0, 20},
+		{"2020-06", 6, 0, 25},
+	}
+	if len(gains) != len(expected) {
+		t.Fatal("wrong number of periods", len(gains))
+	}
+	for i, want := range expected {
+		g := gains[i]
+		if g.Period != want.period || g.BlockNumber != want.bn || g.RealizedGain != want.realized || g.UnrealizedGain != want.unrealized {
+			t.Error("wrong period", i, g.Period, g.BlockNumber, g.RealizedGain, g.UnrealizedGain)
+		}
+	}
+	if gains[5].Timestamp != through.Unix() {
+		t.Error("the last period should end at the end of the range", gains[5].Timestamp)
+	}
+}
+
+func TestPeriodOf(t *testing.T) {
+	ts, _ := time.Parse("2006-01-02", "2023-11-15")
+	tests := map[string]string{"year": "2023", "quarter": "2023-Q4", "month": "2023-11"}
+	for period, want := range tests {
+		if got := periodOf(ts.Unix(), period); got != want {
+			t.Error("periodOf", period, "got", got, "want", want)
+		}
+	}
+}
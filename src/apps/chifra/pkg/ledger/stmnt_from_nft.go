@@ -0,0 +1,228 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/articulate"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/colors"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// nftTransfer is a single movement of a token ID extracted from an ERC-721 or ERC-1155 log
+type nftTransfer struct {
+	tokenType types.TokenType
+	sender    base.Address
+	recipient base.Address
+	tokenId   *big.Int
+	value     *big.Int
+}
+
+// getStatementsFromLog returns the statements (if any) for a given log. ERC-20 transfers produce
+// a single statement. ERC-721 and ERC-1155 transfers produce one statement per token ID.
+func (l *Ledger) getStatementsFromLog(conn *rpc.Connection, log *types.SimpleLog) ([]*types.SimpleStatement, error) {
+	transfers, err := nftTransfersFromLog(log)
+	if err != nil {
+		return nil, err
+	}
+
+	if transfers == nil {
+		statement, err := l.getStatementFromLog(conn, log)
+		if statement == nil {
+			return nil, err
+		}
+		return []*types.SimpleStatement{statement}, err
+	}
+
+	statements := make([]*types.SimpleStatement, 0, len(transfers))
+	for _, transfer := range transfers {
+		statement, err := l.getNftStatement(conn, log, &transfer)
+		if err != nil {
+			return statements, err
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+// nftTransfersFromLog returns the token ID movements in an ERC-721 Transfer or ERC-1155 TransferSingle or
+// TransferBatch log. Returns nil if the log is not one of those.
+func nftTransfersFromLog(log *types.SimpleLog) ([]nftTransfer, error) {
+	if len(log.Topics) != 4 {
+		return nil, nil
+	}
+
+	switch log.Topics[0] {
+	case articulate.TransferTopic:
+		// ERC-721 indexes the token ID, which is how we tell it apart from an ERC-20 transfer
+		return []nftTransfer{{
+			tokenType: types.TokenErc721,
+			sender:    base.HexToAddress(log.Topics[1].Hex()),
+			recipient: base.HexToAddress(log.Topics[2].Hex()),
+			tokenId:   new(big.Int).SetBytes(log.Topics[3].Bytes()),
+			value:     big.NewInt(1),
+		}}, nil
+
+	case articulate.TransferSingleTopic:
+		words, err := dataToWords(log.Data)
+		if err != nil || len(words) != 2 {
+			return nil, fmt.Errorf("invalid TransferSingle data in log %d.%d.%d", log.BlockNumber, log.TransactionIndex, log.LogIndex)
+		}
+		return []nftTransfer{{
+			tokenType: types.TokenErc1155,
+			sender:    base.HexToAddress(log.Topics[2].Hex()),
+			recipient: base.HexToAddress(log.Topics[3].Hex()),
+			tokenId:   words[0],
+			value:     words[1],
+		}}, nil
+
+	case articulate.TransferBatchTopic:
+		words, err := dataToWords(log.Data)
+		if err != nil {
+			return nil, err
+		}
+		ids, values, err := decodeBatch(words)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TransferBatch data in log %d.%d.%d: %w", log.BlockNumber, log.TransactionIndex, log.LogIndex, err)
+		}
+		transfers := make([]nftTransfer, 0, len(ids))
+		for i := range ids {
+			transfers = append(transfers, nftTransfer{
+				tokenType: types.TokenErc1155,
+				sender:    base.HexToAddress(log.Topics[2].Hex()),
+				recipient: base.HexToAddress(log.Topics[3].Hex()),
+				tokenId:   ids[i],
+				value:     values[i],
+			})
+		}
+		return transfers, nil
+	}
+
+	return nil, nil
+}
+
+// getNftStatement returns a statement for the movement of a single token ID. The balances are the
+// number of that token ID held by the accountedFor address.
+func (l *Ledger) getNftStatement(conn *rpc.Connection, log *types.SimpleLog, transfer *nftTransfer) (*types.SimpleStatement, error) {
+	sym := log.Address.Prefix(6)
+	name := l.Names[log.Address]
+	if name.Address == log.Address && name.Symbol != "" {
+		sym = name.Symbol
+	}
+
+	key := l.ctxKey(log.BlockNumber, log.TransactionIndex)
+	ctx := l.Contexts[key]
+
+	balanceAt := func(bn base.Blknum) (*big.Int, error) {
+		return conn.GetNftBalanceAt(log.Address, l.AccountFor, transfer.tokenId, transfer.tokenType, fmt.Sprintf("0x%x", bn))
+	}
+
+	pBal, err := balanceAt(ctx.PrevBlock)
+	if err != nil {
+		return nil, err
+	}
+	bBal, err := balanceAt(ctx.CurBlock - 1)
+	if err != nil {
+		return nil, err
+	}
+	eBal, err := balanceAt(ctx.CurBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := types.SimpleStatement{
+		AccountedFor:     l.AccountFor,
+		Sender:           transfer.sender,
+		Recipient:        transfer.recipient,
+		BlockNumber:      log.BlockNumber,
+		TransactionIndex: log.TransactionIndex,
+		LogIndex:         log.LogIndex,
+		TransactionHash:  log.TransactionHash,
+		Timestamp:        log.Timestamp,
+		AssetAddr:        log.Address,
+		AssetSymbol:      sym,
+		Decimals:         0,
+		SpotPrice:        0.0,
+		PriceSource:      "not-priced",
+		PrevAppBlk:       ctx.PrevBlock,
+		PrevBal:          *pBal,
+		BegBal:           *bBal,
+		EndBal:           *eBal,
+		TokenId:          *transfer.tokenId,
+		TokenType:        transfer.tokenType,
+	}
+
+	ofInterest := false
+	if l.AccountFor == ret.Sender {
+		ret.AmountOut = *transfer.value
+		ofInterest = true
+	}
+
+	// Do not collapse, may be both (self-send)
+	if l.AccountFor == ret.Recipient {
+		ret.AmountIn = *transfer.value
+		ofInterest = true
+	}
+
+	if ofInterest {
+		id := fmt.Sprintf("%d.%d.%d", ret.BlockNumber, ret.TransactionIndex, ret.LogIndex)
+		if !l.trialBalance("NFTS", &ret) {
+			logger.Warn(colors.Yellow+"Transaction", id, "token", transfer.tokenId.String(), "does not reconcile"+colors.Off)
+		} else {
+			logger.Progress(true, colors.Green+"Transaction", id, "token", transfer.tokenId.String(), "reconciled"+colors.Off)
+		}
+	}
+
+	return &ret, nil
+}
+
+// dataToWords splits the hex data of a log into 32-byte words
+func dataToWords(data string) ([]*big.Int, error) {
+	hex := strings.TrimPrefix(data, "0x")
+	if len(hex)%64 != 0 {
+		return nil, errors.New("log data is not a whole number of words")
+	}
+	words := make([]*big.Int, 0, len(hex)/64)
+	for i := 0; i < len(hex); i += 64 {
+		word, ok := new(big.Int).SetString(hex[i:i+64], 16)
+		if !ok {
+			return nil, errors.New("log data is not hex")
+		}
+		words = append(words, word)
+	}
+	return words, nil
+}
+
+// decodeBatch decodes the two uint256[] arrays (ids and values) carried in the data of a TransferBatch log
+func decodeBatch(words []*big.Int) (ids, values []*big.Int, err error) {
+	array := func(offsetWord int) ([]*big.Int, error) {
+		if offsetWord >= len(words) || !words[offsetWord].IsUint64() || words[offsetWord].Uint64()%32 != 0 {
+			return nil, errors.New("invalid array offset")
+		}
+		start := int(words[offsetWord].Uint64() / 32)
+		if start >= len(words) || !words[start].IsUint64() {
+			return nil, errors.New("invalid array length")
+		}
+		n := int(words[start].Uint64())
+		if start+1+n > len(words) {
+			return nil, errors.New("array is longer than the data")
+		}
+		return words[start+1 : start+1+n], nil
+	}
+
+	if ids, err = array(0); err != nil {
+		return nil, nil, err
+	}
+	if values, err = array(1); err != nil {
+		return nil, nil, err
+	}
+	if len(ids) != len(values) {
+		return nil, nil, errors.New("ids and values differ in length")
+	}
+	return ids, values, nil
+}
@@ -0,0 +1,72 @@
+package ledger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/articulate"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func word(v uint64) string {
+	return fmt.Sprintf("%064x", v)
+}
+
+func TestNftTransfersFromLog(t *testing.T) {
+	operator := base.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000001")
+	from := base.HexToHash("0x000000000000000000000000f503017d7baf7fbc0fff7492b751025c6a78179b")
+	to := base.HexToHash("0x00000000000000000000000038d1a2f1e1a9a0f4a5a3e8c5bb86e4ab45d81310")
+
+	erc20 := types.SimpleLog{
+		Topics: []base.Hash{articulate.TransferTopic, from, to},
+		Data:   "0x" + word(100),
+	}
+	if transfers, err := nftTransfersFromLog(&erc20); err != nil || transfers != nil {
+		t.Error("expected an ERC-20 transfer to be ignored", transfers, err)
+	}
+
+	erc721 := types.SimpleLog{
+		Topics: []base.Hash{articulate.TransferTopic, from, to, base.HexToHash("0x" + word(42))},
+	}
+	transfers, err := nftTransfersFromLog(&erc721)
+	if err != nil || len(transfers) != 1 {
+		t.Fatal("expected one ERC-721 transfer", transfers, err)
+	}
+	if tr := transfers[0]; !tr.tokenType.IsErc721() || tr.tokenId.Uint64() != 42 || tr.value.Uint64() != 1 ||
+		tr.sender != base.HexToAddress(from.Hex()) || tr.recipient != base.HexToAddress(to.Hex()) {
+		t.Error("wrong ERC-721 transfer", tr)
+	}
+
+	single := types.SimpleLog{
+		Topics: []base.Hash{articulate.TransferSingleTopic, operator, from, to},
+		Data:   "0x" + word(7) + word(3),
+	}
+	transfers, err = nftTransfersFromLog(&single)
+	if err != nil || len(transfers) != 1 {
+		t.Fatal("expected one ERC-1155 transfer", transfers, err)
+	}
+	if tr := transfers[0]; !tr.tokenType.IsErc1155() || tr.tokenId.Uint64() != 7 || tr.value.Uint64() != 3 ||
+		tr.sender != base.HexToAddress(from.Hex()) {
+		t.Error("wrong ERC-1155 transfer", tr)
+	}
+
+	batch := types.SimpleLog{
+		Topics: []base.Hash{articulate.TransferBatchTopic, operator, from, to},
+		Data:   "0x" + word(64) + word(160) + word(2) + word(1) + word(2) + word(2) + word(10) + word(20),
+	}
+	transfers, err = nftTransfersFromLog(&batch)
+	if err != nil || len(transfers) != 2 {
+		t.Fatal("expected two ERC-1155 transfers", transfers, err)
+	}
+	for i, tr := range transfers {
+		if tr.tokenId.Uint64() != uint64(i+1) || tr.value.Uint64() != uint64(10*(i+1)) {
+			t.Error("wrong batch transfer", i, tr)
+		}
+	}
+
+	batch.Data = "0x" + word(64) + word(160) + word(2) + word(1) + word(2) + word(1) + word(10)
+	if _, err = nftTransfersFromLog(&batch); err == nil {
+		t.Error("expected mismatched arrays to fail")
+	}
+}
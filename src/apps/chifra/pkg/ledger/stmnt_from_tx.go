@@ -89,6 +89,8 @@ func (l *Ledger) GetStatementsFromTransaction(conn *rpc.Connection, trans *types
 					ret.MinerNephewRewardIn = trans.Rewards.Nephew
 					ret.MinerTxFeeIn = trans.Rewards.TxFee
 					ret.MinerUncleRewardIn = trans.Rewards.Uncle
+				} else if trans.TransactionIndex == types.Withdrawal {
+					ret.WithdrawalIn = trans.Value
 				} else {
 					ret.AmountIn = trans.Value
 				}
@@ -121,14 +123,16 @@ func (l *Ledger) GetStatementsFromTransaction(conn *rpc.Connection, trans *types
 		for _, log := range trans.Receipt.Logs {
 			log := log
 			if l.assetOfInterest(log.Address) && log.ContainsAddress(l.AccountFor) {
-				if statement, err := l.getStatementFromLog(conn, &log); statement != nil {
+				logStatements, err := l.getStatementsFromLog(conn, &log)
+				for _, statement := range logStatements {
 					if statement.Sender == l.AccountFor || statement.Recipient == l.AccountFor {
 						add := !l.NoZero || statement.MoneyMoved()
 						if add {
 							statements = append(statements, statement)
 						}
 					}
-				} else if err != nil {
+				}
+				if err != nil {
 					logger.Warn(l.TestMode, "Error getting statement from log: ", err)
 				}
 			}
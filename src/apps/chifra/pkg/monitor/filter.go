@@ -1,11 +1,6 @@
 package monitor
 
 import (
-	"encoding/binary"
-	"fmt"
-	"io"
-	"os"
-
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
@@ -13,34 +8,6 @@ import (
 )
 
 func (mon *Monitor) ReadAndFilterAppearances(filt *filter.AppearanceFilter) (apps []types.SimpleAppearance, cnt int, err error) {
-	readAppearances := func(apps *[]index.AppearanceRecord) (err error) {
-		if int64(len(*apps)) > mon.Count() {
-			err = fmt.Errorf("array is larger than the size of the file (%d,%d)", len(*apps), mon.Count())
-			return
-		}
-
-		if mon.ReadFp == nil {
-			path := mon.Path()
-			mon.ReadFp, err = os.OpenFile(path, os.O_RDONLY, 0644)
-			if err != nil {
-				return
-			}
-		}
-
-		// Seek past the header to get to the first record
-		_, err = mon.ReadFp.Seek(index.AppRecordWidth, io.SeekStart)
-		if err != nil {
-			return
-		}
-
-		err = binary.Read(mon.ReadFp, binary.LittleEndian, apps)
-		if err != nil {
-			return
-		}
-
-		return
-	}
-
 	filt.Reset()
 
 	if mon.Count() == 0 {
@@ -49,7 +16,7 @@ func (mon *Monitor) ReadAndFilterAppearances(filt *filter.AppearanceFilter) (app
 	}
 
 	fromDisc := make([]index.AppearanceRecord, mon.Count())
-	if err := readAppearances(&fromDisc); err != nil {
+	if err := mon.readAppearances(fromDisc); err != nil {
 		mon.Close()
 		return nil, 0, err
 	} else if len(fromDisc) == 0 {
@@ -22,12 +22,22 @@ import (
 )
 
 // Header is the header of the Monitor file. Note that it's the same width as an index.AppearanceRecord
-// therefor one should not change its size
+// therefor one should not change its size. Monitors whose Magic is file.SmallMagicNumber64 store this
+// structure (and 64-bit block numbers) as is. Older monitors, whose Magic is file.SmallMagicNumber,
+// store a header32 (and 32-bit block numbers).
 type Header struct {
 	Magic       uint16 `json:"-"`
 	Unused      bool   `json:"-"`
 	Deleted     bool   `json:"deleted,omitempty"`
-	LastScanned uint32 `json:"lastScanned,omitempty"`
+	LastScanned uint64 `json:"lastScanned,omitempty"`
+}
+
+// header32 is the Header as stored in monitors with 32-bit block numbers
+type header32 struct {
+	Magic       uint16
+	Unused      bool
+	Deleted     bool
+	LastScanned uint32
 }
 
 // Monitor carries information about a Monitor file and its header
@@ -37,6 +47,7 @@ type Monitor struct {
 	Chain   string       `json:"-"`
 	ReadFp  *os.File     `json:"-"`
 	Header
+	width int64 // the width of the file's records once known (see recordWidth)
 }
 
 const (
@@ -121,11 +132,33 @@ func (mon *Monitor) Count() int64 {
 		return 0
 	}
 	s := file.FileSize(mon.Path())
-	w := int64(index.AppRecordWidth)
+	w := mon.recordWidth()
 	n := s / w
 	return n - 1
 }
 
+// recordWidth returns the width of the monitor's records (and its header), index.AppRecordWidth64 if
+// the monitor stores 64-bit block numbers and index.AppRecordWidth otherwise. The file's magic number
+// decides (the in-memory header may not have been read yet) unless there is no file. Once known, the
+// width is kept until the monitor's header is read or written again.
+func (mon *Monitor) recordWidth() int64 {
+	if mon.width == 0 {
+		if file.FileSize(mon.Path()) == 0 {
+			return widthOf(mon.Magic)
+		}
+		mon.width = widthOf(file.ReadSmallMagic(mon.Path()))
+	}
+	return mon.width
+}
+
+// widthOf returns the width of the records of a monitor with the given magic number
+func widthOf(magic uint16) int64 {
+	if magic == file.SmallMagicNumber64 {
+		return index.AppRecordWidth64
+	}
+	return index.AppRecordWidth
+}
+
 // IsOpen returns true if the underlying monitor file is opened.
 func (mon *Monitor) IsOpen() bool {
 	return mon.ReadFp != nil
@@ -167,6 +200,7 @@ func (mon *Monitor) Remove() (bool, error) {
 		return false, errors.New("cannot remove a monitor that is not deleted")
 	}
 	file.Remove(mon.Path())
+	mon.width = 0
 	return !file.FileExists(mon.Path()), nil
 }
 
@@ -26,7 +26,27 @@ func (mon *Monitor) ReadMonitorHeader() (err error) {
 	}
 
 	if file.FileSize(mon.Path()) > 0 {
-		return binary.Read(mon.ReadFp, binary.LittleEndian, &mon.Header)
+		// The header's magic number tells us how wide it (and the records) are
+		var magic uint16
+		if err = binary.Read(io.NewSectionReader(mon.ReadFp, 0, 2), binary.LittleEndian, &magic); err != nil {
+			return
+		}
+		mon.width = widthOf(magic)
+
+		if mon.width == index.AppRecordWidth64 {
+			return binary.Read(mon.ReadFp, binary.LittleEndian, &mon.Header)
+		}
+
+		var header header32
+		if err = binary.Read(mon.ReadFp, binary.LittleEndian, &header); err != nil {
+			return
+		}
+		mon.Header = Header{
+			Magic:       header.Magic,
+			Unused:      header.Unused,
+			Deleted:     header.Deleted,
+			LastScanned: uint64(header.LastScanned),
+		}
 	}
 
 	return
@@ -49,18 +69,39 @@ func (mon *Monitor) ReadAppearanceAt(idx int64, app *index.AppearanceRecord) (er
 	}
 
 	// This index is one based because we have to skip over the header
-	byteIndex := int64(idx) * index.AppRecordWidth
+	width := mon.recordWidth()
+	byteIndex := int64(idx) * width
 	_, err = mon.ReadFp.Seek(byteIndex, io.SeekStart)
 	if err != nil {
 		return
 	}
 
-	err = binary.Read(mon.ReadFp, binary.LittleEndian, &app.BlockNumber)
+	return app.ReadAppearance(mon.ReadFp, width)
+}
+
+// readAppearances reads the first len(apps) appearances from the monitor. The file remains open.
+func (mon *Monitor) readAppearances(apps []index.AppearanceRecord) (err error) {
+	if int64(len(apps)) > mon.Count() {
+		err = fmt.Errorf("array is larger than the size of the file (%d,%d)", len(apps), mon.Count())
+		return
+	}
+
+	if mon.ReadFp == nil {
+		path := mon.Path()
+		mon.ReadFp, err = os.OpenFile(path, os.O_RDONLY, 0644)
+		if err != nil {
+			return
+		}
+	}
+
+	// Seek past the header to get to the first record
+	width := mon.recordWidth()
+	_, err = mon.ReadFp.Seek(width, io.SeekStart)
 	if err != nil {
 		return
 	}
-	err = binary.Read(mon.ReadFp, binary.LittleEndian, &app.TransactionId)
-	return
+
+	return index.ReadAppearanceRecords(mon.ReadFp, width, apps)
 }
 
 // ReadAppearancesToMap reads all appearances from the monitor and returns a map of the appearances to the given type.
@@ -15,21 +15,19 @@ func (mon *Monitor) RemoveDups() (int64, int64, error) {
 	}
 	defer mon.Close()
 
-	if apps, cnt, err := mon.ReadAndFilterAppearances(filter.NewEmptyFilter()); err != nil {
+	apps := make([]index.AppearanceRecord, mon.Count())
+	if err := mon.readAppearances(apps); err != nil {
 		return mon.Count(), mon.Count(), err
-	} else if cnt == 0 {
+	} else if len(apps) == 0 {
 		return mon.Count(), mon.Count(), nil
 	} else {
 		cntBefore := mon.Count()
 		cntAfter := cntBefore
+		filter.NewEmptyFilter().Sort(apps)
 
 		var prev index.AppearanceRecord
 		deDupped := make([]index.AppearanceRecord, 0, mon.Count())
-		for i, app := range apps {
-			iApp := index.AppearanceRecord{
-				BlockNumber:   app.BlockNumber,
-				TransactionId: app.TransactionIndex,
-			}
+		for i, iApp := range apps {
 			if i == 0 || (prev.BlockNumber != iApp.BlockNumber || prev.TransactionId != iApp.TransactionId) {
 				deDupped = append(deDupped, iApp)
 			}
@@ -102,7 +102,7 @@ func Test_Monitor_ReadApps(t *testing.T) {
 		for i, app := range apps {
 			tA := app
 			tA.Address = mon.Address
-			tA.BlockNumber = testApps[i].BlockNumber
+			tA.BlockNumber = uint32(testApps[i].BlockNumber)
 			tA.TransactionIndex = testApps[i].TransactionId
 			if tA != app {
 				t.Error("Record", i, "as read (", app, ") is not equal to testApp", tA)
@@ -175,6 +175,46 @@ func Test_Monitor_Delete(t *testing.T) {
 	}
 }
 
+func Test_Monitor_Upgrade(t *testing.T) {
+	mon := GetTestMonitor(t)
+	defer file.Remove(mon.Path())
+
+	if mon.recordWidth() != index.AppRecordWidth || file.FileSize(mon.Path()) != 32 {
+		t.Fatal("New monitor should have 32-bit block numbers")
+	}
+
+	// An appearance whose block number does not fit in 32 bits upgrades the monitor
+	apps := []index.AppearanceRecord{{BlockNumber: 5000000000, TransactionId: 3}}
+	if err := mon.WriteAppearancesAppend(5000000001, &apps); err != nil {
+		t.Fatal(err)
+	}
+	if mon.recordWidth() != index.AppRecordWidth64 || mon.Count() != nTests+1 || file.FileSize(mon.Path()) != 60 {
+		t.Fatal("Monitor should have been upgraded to 64-bit block numbers", mon.Count(), file.FileSize(mon.Path()))
+	}
+
+	var got index.AppearanceRecord
+	for i, expected := range append(testApps, apps...) {
+		if err := mon.ReadAppearanceAt(int64(i+1), &got); got != expected || err != nil {
+			t.Error("Expected:", expected, "Got:", got, err)
+		}
+	}
+
+	mon.Close()
+	mon.Header = Header{}
+	mon.width = 0
+	if err := mon.ReadMonitorHeader(); err != nil || mon.Magic != file.SmallMagicNumber64 || mon.LastScanned != 5000000001 {
+		t.Error("Wrong header", mon.Header, err)
+	}
+	if mon.width != index.AppRecordWidth64 {
+		t.Error("Reading the header should have kept the width of the records", mon.width)
+	}
+	mon.Close()
+
+	if removed, err := mon.TruncateTo("mainnet", 1001002); !removed || err != nil || mon.Count() != 2 || mon.LastScanned != 1001002 {
+		t.Error("Truncated monitor should keep two records", mon.Count(), mon.LastScanned, err)
+	}
+}
+
 func GetTestMonitor(t *testing.T) Monitor {
 	// Create a new, empty monitor
 	testAddr := "0x049029dd41661e58f99271a0112dfd34695f7000"
@@ -7,8 +7,10 @@ package monitor
 import (
 	"encoding/binary"
 	"io"
+	"math"
 	"os"
 
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 )
@@ -16,7 +18,14 @@ import (
 // TODO: Protect against overwriting files on disc
 
 // WriteMonHeader writes the monitor's header
-func (mon *Monitor) WriteMonHeader(deleted bool, lastScanned uint32, force bool) (err error) {
+func (mon *Monitor) WriteMonHeader(deleted bool, lastScanned uint64, force bool) (err error) {
+	if lastScanned > math.MaxUint32 {
+		if _, err = mon.Upgrade(); err != nil {
+			return
+		}
+	}
+
+	width := mon.recordWidth()
 	f, err := os.OpenFile(mon.Path(), os.O_WRONLY|os.O_CREATE, 0644)
 	if err != nil {
 		return
@@ -29,14 +38,31 @@ func (mon *Monitor) WriteMonHeader(deleted bool, lastScanned uint32, force bool)
 	}
 
 	_, _ = f.Seek(0, io.SeekStart)
-	err = binary.Write(f, binary.LittleEndian, mon.Header)
+	err = mon.writeHeader(f, width)
 	return
 }
 
+// writeHeader writes the monitor's header with the given width (that of the monitor's records)
+func (mon *Monitor) writeHeader(w io.Writer, width int64) error {
+	mon.width = width
+	if width == index.AppRecordWidth64 {
+		mon.Magic = file.SmallMagicNumber64
+		return binary.Write(w, binary.LittleEndian, mon.Header)
+	}
+
+	mon.Magic = file.SmallMagicNumber
+	return binary.Write(w, binary.LittleEndian, header32{
+		Magic:       mon.Magic,
+		Unused:      mon.Unused,
+		Deleted:     mon.Deleted,
+		LastScanned: uint32(mon.LastScanned),
+	})
+}
+
 // WriteAppearancesAppend appends appearances to the end of the file, updates the header with
 // lastScanned (if later) and returns the number of records written. Note that we should
 // be writing to a temporary file.
-func (mon *Monitor) WriteAppearancesAppend(lastScanned uint32, apps *[]index.AppearanceRecord) error {
+func (mon *Monitor) WriteAppearancesAppend(lastScanned uint64, apps *[]index.AppearanceRecord) error {
 	if !mon.Staged {
 		logger.Fatal("Trying to write to a non-staged file. Should not happen.")
 
@@ -63,8 +89,19 @@ func (mon *Monitor) WriteAppearancesAppend(lastScanned uint32, apps *[]index.App
 
 // TODO: Protect against overwriting files on disc
 
-// WriteAppearances writes appearances to a Monitor
+// WriteAppearances writes appearances to a Monitor. The monitor keeps the width of its block
+// numbers unless one of the appearances needs 64 bits, in which case it's upgraded.
 func (mon *Monitor) WriteAppearances(apps []index.AppearanceRecord, append bool) (int64, error) {
+	width := mon.recordWidth()
+	if width == index.AppRecordWidth && index.Needs64(apps) {
+		if append {
+			if _, err := mon.Upgrade(); err != nil {
+				return 0, err
+			}
+		}
+		width = index.AppRecordWidth64
+	}
+
 	var f *os.File
 	var err error
 	path := mon.Path()
@@ -77,25 +114,57 @@ func (mon *Monitor) WriteAppearances(apps []index.AppearanceRecord, append bool)
 		return 0, err
 	}
 
-	_, _ = f.Seek(index.AppRecordWidth, io.SeekStart)
-
-	b := make([]byte, 4)
-	for _, app := range apps {
-		binary.LittleEndian.PutUint32(b, app.BlockNumber)
-		_, err = f.Write(b)
-		if err != nil {
-			f.Close()
-			return 0, err
-		}
-		binary.LittleEndian.PutUint32(b, app.TransactionId)
-		_, err = f.Write(b)
-		if err != nil {
+	if !append {
+		// The caller writes the final header, but the file must carry its magic number until then
+		if err = mon.writeHeader(f, width); err != nil {
 			f.Close()
 			return 0, err
 		}
 	}
 
+	if err = index.WriteAppearanceRecords(f, width, apps); err != nil {
+		f.Close()
+		return 0, err
+	}
+
 	f.Close() // do not defer this, we need to close it so the fileSize is right
 	_, _ = mon.Reload(false /* create */)
 	return mon.Count(), nil
 }
+
+// Upgrade rewrites the monitor with 64-bit block numbers. It returns false if the monitor already
+// has them. A monitor that does not yet exist is created with 64-bit block numbers.
+func (mon *Monitor) Upgrade() (bool, error) {
+	if mon.recordWidth() == index.AppRecordWidth64 {
+		return false, nil
+	}
+
+	if file.FileSize(mon.Path()) == 0 {
+		mon.Magic = file.SmallMagicNumber64
+		return false, nil
+	}
+
+	defer mon.Close()
+	if err := mon.ReadMonitorHeader(); err != nil {
+		return false, err
+	}
+	apps := make([]index.AppearanceRecord, mon.Count())
+	if err := mon.readAppearances(apps); err != nil {
+		return false, err
+	}
+	mon.Close()
+
+	f, err := os.OpenFile(mon.Path(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	if err = mon.writeHeader(f, index.AppRecordWidth64); err != nil {
+		return false, err
+	}
+	if err = index.WriteAppearanceRecords(f, index.AppRecordWidth64, apps); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -1,29 +1,28 @@
 package monitor
 
 import (
-	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
 )
 
-func (mon *Monitor) TruncateTo(chain string, num uint32) (bool, error) {
+func (mon *Monitor) TruncateTo(chain string, num uint64) (bool, error) {
 	err := mon.ReadMonitorHeader()
 	if err != nil {
 		return false, err
 	}
 
-	if apps, cnt, err := mon.ReadAndFilterAppearances(filter.NewEmptyFilter()); err != nil {
+	apps := make([]index.AppearanceRecord, mon.Count())
+	if err := mon.readAppearances(apps); err != nil {
+		mon.Close()
 		return false, err
-	} else if cnt == 0 {
+	} else if len(apps) == 0 {
+		mon.Close()
 		return false, nil
 	} else {
 		var keep []index.AppearanceRecord
 		for _, app := range apps {
 			if app.BlockNumber <= num {
-				keep = append(keep, index.AppearanceRecord{
-					BlockNumber:   app.BlockNumber,
-					TransactionId: app.TransactionIndex,
-				})
+				keep = append(keep, app)
 			}
 		}
 		lastScanned := utils.Min(num, mon.Header.LastScanned)
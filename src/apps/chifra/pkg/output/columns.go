@@ -0,0 +1,45 @@
+package output
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// columnKind returns the kind of column the Parquet and SQL writers use for the given value: one of
+// reflect.Bool, reflect.Int64, reflect.Uint64 or reflect.Float64 for booleans and numbers (or pointers
+// to them, even nil ones) and reflect.String for anything else, including types that format themselves.
+func columnKind(value any) reflect.Kind {
+	t := reflect.TypeOf(value)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Implements(stringerType) || reflect.PointerTo(t).Implements(stringerType) {
+		return reflect.String
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return reflect.Bool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.Int64
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return reflect.Uint64
+	case reflect.Float32, reflect.Float64:
+		return reflect.Float64
+	}
+	return reflect.String
+}
+
+// columnValue dereferences the value. It returns false if the value is missing or a nil pointer.
+func columnValue(value any) (reflect.Value, bool) {
+	v := reflect.ValueOf(value)
+	for v.IsValid() && v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, v.IsValid()
+}
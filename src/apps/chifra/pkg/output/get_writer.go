@@ -13,8 +13,9 @@ import (
 // If there is an error, logger.Fatal is called, because there really is no good way
 // to recover. Plus, output file is disabled in server, so it is safe to exit.
 func (opts *OutputOptions) GetOutputFileWriter() io.Writer {
-	if opts.OutputFn == "" {
-		// If there's no output filename, we return the default writer (standard out).
+	if opts.OutputFn == "" || IsDatabaseDsn(opts.OutputFn) {
+		// If there's no output filename, we return the default writer (standard out). Models
+		// streamed to a database do not pass through the writer.
 		return opts.Writer
 	}
 
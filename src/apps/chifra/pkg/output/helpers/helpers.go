@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/globals"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
 	"github.com/spf13/cobra"
@@ -32,9 +33,9 @@ func PreRunWithJsonWriter(cmdName string, getOptions func() *globals.GlobalOptio
 	return func(cmd *cobra.Command, args []string) {
 		opts := getOptions()
 		var outputWriter io.Writer
-		// Prepare the default writer (stdout or --output file)
+		// Prepare the default writer (stdout or --output file, but not a --output database)
 		outputWriter = os.Stdout
-		if opts.OutputFn != "" {
+		if opts.OutputFn != "" && !output.IsDatabaseDsn(opts.OutputFn) {
 			outputWriter = opts.GetOutputFileWriter()
 		} else if discardWriter != nil {
 			outputWriter = discardWriter
@@ -54,6 +55,11 @@ func PreRunWithJsonWriter(cmdName string, getOptions func() *globals.GlobalOptio
 
 func PostRunWithJsonWriter(getOptions func() *globals.GlobalOptions) func(cmd *cobra.Command, args []string) {
 	return func(cmd *cobra.Command, args []string) {
+		// The command is done with the cache, so we let other processes use it
+		defer func() {
+			_ = cache.CloseStores()
+		}()
+
 		opts := getOptions()
 		w := opts.Writer
 		// Try to cast the global writer to JsonWriter
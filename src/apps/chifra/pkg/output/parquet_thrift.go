@@ -0,0 +1,111 @@
+package output
+
+import "encoding/binary"
+
+// thriftWriter encodes the few Thrift structures Parquet needs (page headers and the file's
+// footer) using Thrift's compact protocol. Structs nest, so the id of the last field written at
+// each level is kept on a stack.
+type thriftWriter struct {
+	buf     []byte
+	lastIds []int16
+}
+
+// Compact protocol type codes
+const (
+	thriftI32    byte = 5
+	thriftI64    byte = 6
+	thriftBinary byte = 8
+	thriftList   byte = 9
+	thriftStruct byte = 12
+)
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{
+		lastIds: []int16{0},
+	}
+}
+
+func (w *thriftWriter) bytes() []byte {
+	return w.buf
+}
+
+func (w *thriftWriter) field(id int16, fieldType byte) {
+	last := &w.lastIds[len(w.lastIds)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|fieldType)
+	} else {
+		w.buf = append(w.buf, fieldType)
+		w.buf = binary.AppendVarint(w.buf, int64(id))
+	}
+	*last = id
+}
+
+func (w *thriftWriter) i32(id int16, value int32) {
+	w.field(id, thriftI32)
+	w.buf = binary.AppendVarint(w.buf, int64(value))
+}
+
+func (w *thriftWriter) i64(id int16, value int64) {
+	w.field(id, thriftI64)
+	w.buf = binary.AppendVarint(w.buf, value)
+}
+
+func (w *thriftWriter) binary(id int16, value string) {
+	w.field(id, thriftBinary)
+	w.appendString(value)
+}
+
+func (w *thriftWriter) appendString(value string) {
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(value)))
+	w.buf = append(w.buf, value...)
+}
+
+// beginStruct opens a struct valued field. It must be closed with endStruct.
+func (w *thriftWriter) beginStruct(id int16) {
+	w.field(id, thriftStruct)
+	w.lastIds = append(w.lastIds, 0)
+}
+
+func (w *thriftWriter) endStruct() {
+	w.stop()
+	w.lastIds = w.lastIds[:len(w.lastIds)-1]
+}
+
+// stop ends the outermost struct
+func (w *thriftWriter) stop() {
+	w.buf = append(w.buf, 0)
+}
+
+// beginList opens a list valued field of n elements. Struct elements are each written between
+// beginElem and endElem.
+func (w *thriftWriter) beginList(id int16, elemType byte, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|elemType)
+	} else {
+		w.buf = append(w.buf, 0xf0|elemType)
+		w.buf = binary.AppendUvarint(w.buf, uint64(n))
+	}
+}
+
+func (w *thriftWriter) beginElem() {
+	w.lastIds = append(w.lastIds, 0)
+}
+
+func (w *thriftWriter) endElem() {
+	w.endStruct()
+}
+
+func (w *thriftWriter) i32List(id int16, values ...int32) {
+	w.beginList(id, thriftI32, len(values))
+	for _, value := range values {
+		w.buf = binary.AppendVarint(w.buf, int64(value))
+	}
+}
+
+func (w *thriftWriter) binaryList(id int16, values ...string) {
+	w.beginList(id, thriftBinary, len(values))
+	for _, value := range values {
+		w.appendString(value)
+	}
+}
@@ -0,0 +1,379 @@
+package output
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// ParquetWriter writes models to a Parquet file. The schema is taken from the first model written:
+// there is one column for each key in its Order, typed after the key's value. Every column is
+// optional, so keys missing from later models are written as nulls and keys not in the first
+// model are dropped (just as they are from the header of csv and txt output). Rows are buffered
+// and written in row groups of parquetRowGroupSize rows. Close must be called to write the footer.
+type ParquetWriter struct {
+	writer    io.Writer
+	offset    int64
+	columns   []*parquetColumn
+	nRows     int64
+	nBuffered int
+	rowGroups []parquetRowGroup
+}
+
+// parquetRowGroupSize is the number of rows buffered before a row group is written
+const parquetRowGroupSize = 65536
+
+var parquetMagic = []byte("PAR1")
+
+// NewParquetWriter returns a ParquetWriter that writes to w
+func NewParquetWriter(w io.Writer) *ParquetWriter {
+	return &ParquetWriter{
+		writer: w,
+	}
+}
+
+// Write adds the model as a row to the file
+func (pw *ParquetWriter) Write(model types.Model) error {
+	if pw.columns == nil {
+		if err := pw.open(model); err != nil {
+			return err
+		}
+	}
+
+	for _, column := range pw.columns {
+		column.add(model.Data[column.name])
+	}
+	pw.nBuffered++
+
+	if pw.nBuffered >= parquetRowGroupSize {
+		return pw.flush()
+	}
+	return nil
+}
+
+// Close writes any buffered rows and the file's footer. A file with no rows has no columns.
+func (pw *ParquetWriter) Close() error {
+	if pw.columns == nil {
+		if err := pw.open(types.Model{}); err != nil {
+			return err
+		}
+	}
+
+	if err := pw.flush(); err != nil {
+		return err
+	}
+
+	footer := pw.fileMetaData()
+	if err := pw.write(footer); err != nil {
+		return err
+	}
+
+	size := make([]byte, 4)
+	binary.LittleEndian.PutUint32(size, uint32(len(footer)))
+	if err := pw.write(size); err != nil {
+		return err
+	}
+	return pw.write(parquetMagic)
+}
+
+func (pw *ParquetWriter) open(model types.Model) error {
+	pw.columns = make([]*parquetColumn, 0, len(model.Order))
+	seen := make(map[string]bool, len(model.Order))
+	for _, name := range model.Order {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		pw.columns = append(pw.columns, newParquetColumn(name, model.Data[name]))
+	}
+	return pw.write(parquetMagic)
+}
+
+func (pw *ParquetWriter) write(data []byte) error {
+	n, err := pw.writer.Write(data)
+	pw.offset += int64(n)
+	return err
+}
+
+// flush writes the buffered rows as a row group with one column chunk (of a single data page) per column
+func (pw *ParquetWriter) flush() error {
+	if pw.nBuffered == 0 {
+		return nil
+	}
+
+	rowGroup := parquetRowGroup{
+		nRows:  int64(pw.nBuffered),
+		chunks: make([]parquetChunk, 0, len(pw.columns)),
+	}
+	for _, column := range pw.columns {
+		page := column.page()
+		chunk := parquetChunk{
+			offset: pw.offset,
+			size:   int64(len(page)),
+		}
+		if err := pw.write(page); err != nil {
+			return err
+		}
+		rowGroup.size += chunk.size
+		rowGroup.chunks = append(rowGroup.chunks, chunk)
+		column.reset()
+	}
+
+	pw.rowGroups = append(pw.rowGroups, rowGroup)
+	pw.nRows += rowGroup.nRows
+	pw.nBuffered = 0
+	return nil
+}
+
+// parquetType is the physical type of a column
+type parquetType int32
+
+const (
+	parquetBoolean   parquetType = 0
+	parquetInt64     parquetType = 2
+	parquetDouble    parquetType = 5
+	parquetByteArray parquetType = 6
+)
+
+// Converted (logical) types of columns
+const (
+	parquetUtf8   int32 = 0
+	parquetUint64 int32 = 14
+)
+
+// Encodings of pages
+const (
+	parquetPlain int32 = 0
+	parquetRle   int32 = 3
+)
+
+type parquetColumn struct {
+	name     string
+	kind     parquetType
+	unsigned bool
+	values   bytes.Buffer
+	levels   []byte
+	bits     byte
+	nBits    int
+}
+
+type parquetRowGroup struct {
+	nRows  int64
+	size   int64
+	chunks []parquetChunk
+}
+
+type parquetChunk struct {
+	offset int64
+	size   int64
+}
+
+// newParquetColumn types the column after the given value. Values that are neither booleans nor
+// numbers are written as strings, formatted as they are for csv and txt output.
+func newParquetColumn(name string, value any) *parquetColumn {
+	column := &parquetColumn{name: name, kind: parquetByteArray}
+	switch columnKind(value) {
+	case reflect.Bool:
+		column.kind = parquetBoolean
+	case reflect.Int64:
+		column.kind = parquetInt64
+	case reflect.Uint64:
+		column.kind = parquetInt64
+		column.unsigned = true
+	case reflect.Float64:
+		column.kind = parquetDouble
+	}
+	return column
+}
+
+// add appends a value to the column, writing a null if the value is missing or cannot be
+// represented in the column's type
+func (c *parquetColumn) add(value any) {
+	v, ok := columnValue(value)
+	if !ok {
+		c.levels = append(c.levels, 0)
+		return
+	}
+
+	var err error
+	switch c.kind {
+	case parquetBoolean:
+		err = c.addBool(v)
+	case parquetInt64:
+		err = c.addInt64(v)
+	case parquetDouble:
+		err = c.addDouble(v)
+	default:
+		str := fmt.Sprint(v.Interface())
+		_ = binary.Write(&c.values, binary.LittleEndian, uint32(len(str)))
+		c.values.WriteString(str)
+	}
+
+	if err != nil {
+		c.levels = append(c.levels, 0)
+		return
+	}
+	c.levels = append(c.levels, 1)
+}
+
+var errParquetType = errors.New("value does not match the column's type")
+
+func (c *parquetColumn) addBool(v reflect.Value) error {
+	if v.Kind() != reflect.Bool {
+		return errParquetType
+	}
+	if v.Bool() {
+		c.bits |= 1 << c.nBits
+	}
+	c.nBits++
+	if c.nBits == 8 {
+		c.values.WriteByte(c.bits)
+		c.bits, c.nBits = 0, 0
+	}
+	return nil
+}
+
+func (c *parquetColumn) addInt64(v reflect.Value) error {
+	var n int64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = int64(v.Uint()) // stored as the same bits and annotated as unsigned
+	case reflect.String:
+		parsed, err := strconv.ParseInt(v.String(), 0, 64)
+		if err != nil {
+			return err
+		}
+		n = parsed
+	default:
+		return errParquetType
+	}
+	return binary.Write(&c.values, binary.LittleEndian, n)
+}
+
+func (c *parquetColumn) addDouble(v reflect.Value) error {
+	var f float64
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f = v.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f = float64(v.Uint())
+	default:
+		return errParquetType
+	}
+	return binary.Write(&c.values, binary.LittleEndian, math.Float64bits(f))
+}
+
+// page returns the column's buffered values as a PLAIN encoded data page, preceded by its header
+func (c *parquetColumn) page() []byte {
+	if c.nBits > 0 {
+		c.values.WriteByte(c.bits)
+		c.bits, c.nBits = 0, 0
+	}
+
+	levels := rleLevels(c.levels)
+	body := make([]byte, 0, 4+len(levels)+c.values.Len())
+	body = binary.LittleEndian.AppendUint32(body, uint32(len(levels)))
+	body = append(body, levels...)
+	body = append(body, c.values.Bytes()...)
+
+	header := newThriftWriter()
+	header.i32(1, 0) // DATA_PAGE
+	header.i32(2, int32(len(body)))
+	header.i32(3, int32(len(body)))
+	header.beginStruct(5)
+	header.i32(1, int32(len(c.levels)))
+	header.i32(2, parquetPlain)
+	header.i32(3, parquetRle)
+	header.i32(4, parquetRle)
+	header.endStruct()
+	header.stop()
+
+	return append(header.bytes(), body...)
+}
+
+func (c *parquetColumn) reset() {
+	c.values.Reset()
+	c.levels = c.levels[:0]
+}
+
+// rleLevels encodes definition levels (each zero or one) in runs using the RLE half of the
+// RLE/bit-packing hybrid encoding with a bit width of one
+func rleLevels(levels []byte) []byte {
+	ret := make([]byte, 0, 16)
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		ret = binary.AppendUvarint(ret, uint64(j-i)<<1)
+		ret = append(ret, levels[i])
+		i = j
+	}
+	return ret
+}
+
+// fileMetaData returns the file's footer
+func (pw *ParquetWriter) fileMetaData() []byte {
+	meta := newThriftWriter()
+	meta.i32(1, 1)
+
+	meta.beginList(2, thriftStruct, len(pw.columns)+1)
+	meta.beginElem()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(pw.columns)))
+	meta.endElem()
+	for _, column := range pw.columns {
+		meta.beginElem()
+		meta.i32(1, int32(column.kind))
+		meta.i32(3, 1) // OPTIONAL
+		meta.binary(4, column.name)
+		if column.kind == parquetByteArray {
+			meta.i32(6, parquetUtf8)
+		} else if column.unsigned {
+			meta.i32(6, parquetUint64)
+		}
+		meta.endElem()
+	}
+
+	meta.i64(3, pw.nRows)
+
+	meta.beginList(4, thriftStruct, len(pw.rowGroups))
+	for _, rowGroup := range pw.rowGroups {
+		meta.beginElem()
+		meta.beginList(1, thriftStruct, len(rowGroup.chunks))
+		for i, chunk := range rowGroup.chunks {
+			column := pw.columns[i]
+			meta.beginElem()
+			meta.i64(2, chunk.offset)
+			meta.beginStruct(3)
+			meta.i32(1, int32(column.kind))
+			meta.i32List(2, parquetPlain, parquetRle)
+			meta.binaryList(3, column.name)
+			meta.i32(4, 0) // UNCOMPRESSED
+			meta.i64(5, rowGroup.nRows)
+			meta.i64(6, chunk.size)
+			meta.i64(7, chunk.size)
+			meta.i64(9, chunk.offset)
+			meta.endStruct()
+			meta.endElem()
+		}
+		meta.i64(2, rowGroup.size)
+		meta.i64(3, rowGroup.nRows)
+		meta.endElem()
+	}
+
+	meta.binary(6, "TrueBlocks chifra")
+	meta.stop()
+	return meta.bytes()
+}
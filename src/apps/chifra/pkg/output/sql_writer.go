@@ -0,0 +1,340 @@
+package output
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+// SqlWriter writes models to a SQLite or Postgres database. Each type of model is written to its
+// own table (`transactions`, `logs`, `statements`, ...) which is created, if needed, from the first
+// model of that type written: one column for each key in its Order, typed after the key's value.
+// Rows are upserted on their key columns (see sqlKeyColumns), so that writing the same data twice
+// updates rather than duplicates it. All rows are written in a single database transaction which is
+// committed by Close or, if the output fails, discarded by Rollback.
+type SqlWriter struct {
+	db      *sql.DB
+	tx      *sql.Tx
+	dialect sqlDialect
+	tables  map[string]*sqlTable
+}
+
+// sqlKeyColumns are the columns that, if present in a model, identify its row. Most rows are
+// identified by their block, transaction and log index, but some models repeat those, so the
+// accounts, assets, and token IDs (a single ERC-1155 TransferBatch log moves many) that distinguish
+// such rows are part of the key as well.
+var sqlKeyColumns = []string{
+	"blockNumber",
+	"transactionIndex",
+	"logIndex",
+	"accountedFor",
+	"assetAddr",
+	"tokenId",
+	"holder",
+	"address",
+}
+
+// sqlIndexColumns are, by table, a column added to number the rows that share the rest of their
+// key. A transaction's traces, for example, have no column that tells them apart, so they are keyed
+// on their position in the transaction (in the order they are written) as well.
+var sqlIndexColumns = map[string]string{
+	"traces": "traceIndex",
+}
+
+type sqlDialect struct {
+	driver      string
+	placeholder func(n int) string
+	types       map[reflect.Kind]string
+}
+
+var sqliteDialect = sqlDialect{
+	driver:      "sqlite",
+	placeholder: func(n int) string { return "?" },
+	types: map[reflect.Kind]string{
+		reflect.Bool:    "BOOLEAN",
+		reflect.Int64:   "INTEGER",
+		reflect.Uint64:  "INTEGER",
+		reflect.Float64: "REAL",
+		reflect.String:  "TEXT",
+	},
+}
+
+var postgresDialect = sqlDialect{
+	driver:      "postgres",
+	placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+	types: map[reflect.Kind]string{
+		reflect.Bool:    "BOOLEAN",
+		reflect.Int64:   "BIGINT",
+		reflect.Uint64:  "NUMERIC(20)",
+		reflect.Float64: "DOUBLE PRECISION",
+		reflect.String:  "TEXT",
+	},
+}
+
+type sqlTable struct {
+	columns []string
+	kinds   []reflect.Kind
+	keys    map[string]bool
+	index   string
+	counts  map[string]uint64
+	insert  *sql.Stmt
+}
+
+// IsDatabaseDsn returns true if the --output value names a database rather than a file. SQLite
+// databases are named `sqlite://path/to/file.db`, Postgres databases by a `postgres://` (or
+// `postgresql://`) connection URL.
+func IsDatabaseDsn(dsn string) bool {
+	_, _, err := parseDatabaseDsn(dsn)
+	return err == nil
+}
+
+func parseDatabaseDsn(dsn string) (sqlDialect, string, error) {
+	if path, ok := strings.CutPrefix(dsn, "sqlite://"); ok && len(path) > 0 {
+		return sqliteDialect, path, nil
+	}
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		return postgresDialect, dsn, nil
+	}
+	return sqlDialect{}, "", fmt.Errorf("%s is not a sqlite:// or postgres:// database", dsn)
+}
+
+// NewSqlWriter opens the database named by dsn and begins the transaction rows are written in
+func NewSqlWriter(dsn string) (*SqlWriter, error) {
+	dialect, source, err := parseDatabaseDsn(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open(dialect.driver, source)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SqlWriter{
+		db:      db,
+		tx:      tx,
+		dialect: dialect,
+		tables:  make(map[string]*sqlTable),
+	}, nil
+}
+
+// Write upserts the model as a row of the given table
+func (sw *SqlWriter) Write(tableName string, model types.Model) error {
+	table, ok := sw.tables[tableName]
+	if !ok {
+		var err error
+		if table, err = sw.open(tableName, model); err != nil {
+			return err
+		}
+		sw.tables[tableName] = table
+	}
+
+	args := make([]any, 0, len(table.columns))
+	group := ""
+	indexAt := -1
+	for i, column := range table.columns {
+		if column == table.index {
+			indexAt = i
+			args = append(args, nil)
+			continue
+		}
+		value := sqlValue(table.kinds[i], model.Data[column])
+		if table.keys[column] {
+			if value == nil {
+				if table.kinds[i] != reflect.String {
+					return fmt.Errorf("row of %s has no %s", tableName, column)
+				}
+				// Text keys (such as the tokenId of a fungible token's statement) may be empty
+				value = ""
+			}
+			group += fmt.Sprint(value) + "."
+		}
+		args = append(args, value)
+	}
+	if indexAt >= 0 {
+		args[indexAt] = int64(table.counts[group])
+		table.counts[group]++
+	}
+
+	_, err := table.insert.Exec(args...)
+	return err
+}
+
+// Close commits the rows written and closes the database
+func (sw *SqlWriter) Close() error {
+	err := sw.tx.Commit()
+	if closeErr := sw.db.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Rollback discards the rows written and closes the database
+func (sw *SqlWriter) Rollback() error {
+	err := sw.tx.Rollback()
+	if closeErr := sw.db.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// open creates the table (unless it already exists) and prepares the statement that upserts its rows
+func (sw *SqlWriter) open(tableName string, model types.Model) (*sqlTable, error) {
+	table := &sqlTable{
+		keys:   make(map[string]bool),
+		counts: make(map[string]uint64),
+	}
+	for _, column := range model.Order {
+		if _, ok := model.Data[column]; !ok || contains(table.columns, column) {
+			continue
+		}
+		table.columns = append(table.columns, column)
+		table.kinds = append(table.kinds, columnKind(model.Data[column]))
+	}
+	if index, ok := sqlIndexColumns[tableName]; ok && !contains(table.columns, index) {
+		table.index = index
+		table.columns = append(table.columns, index)
+		table.kinds = append(table.kinds, reflect.Uint64)
+	}
+
+	keys := make([]string, 0, len(sqlKeyColumns)+1)
+	for _, key := range sqlKeyColumns {
+		if contains(table.columns, key) {
+			table.keys[key] = true
+			keys = append(keys, quoteIdentifier(key))
+		}
+	}
+	if len(table.index) > 0 {
+		table.keys[table.index] = true
+		keys = append(keys, quoteIdentifier(table.index))
+	}
+
+	definitions := make([]string, 0, len(table.columns)+1)
+	names := make([]string, 0, len(table.columns))
+	placeholders := make([]string, 0, len(table.columns))
+	updates := make([]string, 0, len(table.columns))
+	for i, column := range table.columns {
+		name := quoteIdentifier(column)
+		definition := name + " " + sw.dialect.types[table.kinds[i]]
+		if table.keys[column] {
+			definition += " NOT NULL"
+		} else {
+			updates = append(updates, name+" = excluded."+name)
+		}
+		definitions = append(definitions, definition)
+		names = append(names, name)
+		placeholders = append(placeholders, sw.dialect.placeholder(i+1))
+	}
+	if len(keys) > 0 {
+		definitions = append(definitions, "PRIMARY KEY ("+strings.Join(keys, ", ")+")")
+	}
+
+	create := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", quoteIdentifier(tableName), strings.Join(definitions, ", "))
+	if _, err := sw.tx.Exec(create); err != nil {
+		return nil, err
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quoteIdentifier(tableName), strings.Join(names, ", "), strings.Join(placeholders, ", "))
+	if len(keys) > 0 {
+		if len(updates) > 0 {
+			insert += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(keys, ", "), strings.Join(updates, ", "))
+		} else {
+			insert += fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(keys, ", "))
+		}
+	}
+
+	var err error
+	table.insert, err = sw.tx.Prepare(insert)
+	return table, err
+}
+
+// SqlTableName returns the name of the table the given model is written to: its type's name without
+// the `Simple` prefix (or any type parameters), in camel case and pluralized (`SimpleLog` to `logs`)
+func SqlTableName(model any) string {
+	t := reflect.TypeOf(model)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return "models"
+	}
+
+	name, _, _ := strings.Cut(t.Name(), "[")
+	name = strings.TrimPrefix(name, "Simple")
+	if len(name) == 0 {
+		return "models"
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes) + "s"
+}
+
+// sqlValue converts the model value to one the database driver accepts for a column of the given
+// kind. Missing values, and values that cannot be represented in the column, are written as NULL.
+func sqlValue(kind reflect.Kind, value any) any {
+	v, ok := columnValue(value)
+	if !ok {
+		return nil
+	}
+
+	switch kind {
+	case reflect.Bool:
+		if v.Kind() == reflect.Bool {
+			return v.Bool()
+		}
+	case reflect.Int64, reflect.Uint64:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return v.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if v.Uint() > math.MaxInt64 {
+				// Drivers do not accept such values, but the column (a NUMERIC in Postgres) does
+				return strconv.FormatUint(v.Uint(), 10)
+			}
+			return int64(v.Uint())
+		case reflect.String:
+			if n, err := strconv.ParseInt(v.String(), 0, 64); err == nil {
+				return n
+			}
+		}
+	case reflect.Float64:
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return v.Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return float64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return float64(v.Uint())
+		}
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+	return nil
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func contains(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
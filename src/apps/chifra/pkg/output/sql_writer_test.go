@@ -0,0 +1,292 @@
+package output
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func TestIsDatabaseDsn(t *testing.T) {
+	tests := map[string]bool{
+		"sqlite:///tmp/exports.db":             true,
+		"sqlite://exports.db":                  true,
+		"postgres://user@localhost/exports":    true,
+		"postgresql://user@localhost/exports":  true,
+		"sqlite://":                            false,
+		"exports.db":                           false,
+		"exports.csv":                          false,
+		"mysql://user@localhost/exports":       false,
+		"/home/user/postgres://not/a/database": false,
+	}
+	for dsn, expected := range tests {
+		if IsDatabaseDsn(dsn) != expected {
+			t.Error("wrong result for", dsn)
+		}
+	}
+}
+
+func TestSqlTableName(t *testing.T) {
+	tests := []struct {
+		model    any
+		expected string
+	}{
+		{&types.SimpleLog{}, "logs"},
+		{&types.SimpleBlock[string]{}, "blocks"},
+		{&types.SimpleBlockFee{}, "blockFees"},
+		{types.SimpleStatement{}, "statements"},
+		{nil, "models"},
+	}
+	for _, test := range tests {
+		if result := SqlTableName(test.model); result != test.expected {
+			t.Error("wrong table name", result, "expected", test.expected)
+		}
+	}
+}
+
+func TestSqlWriter_Upsert(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exports.db")
+	dsn := "sqlite://" + path
+
+	newModel := func(bn base.Blknum, txId base.Txnum, value string, price *float64) types.Model {
+		return types.Model{
+			Data: map[string]any{
+				"blockNumber":      bn,
+				"transactionIndex": txId,
+				"from":             base.HexToAddress("0x1"),
+				"value":            value,
+				"isError":          false,
+				"spotPrice":        price,
+			},
+			Order: []string{"blockNumber", "transactionIndex", "from", "value", "isError", "spotPrice"},
+		}
+	}
+
+	price := 1.5
+	writeAll := func(models ...types.Model) {
+		sw, err := NewSqlWriter(dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, model := range models {
+			if err := sw.Write("transactions", model); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := sw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeAll(newModel(1, 0, "10", &price), newModel(1, 1, "11", nil))
+	writeAll(newModel(1, 1, "12", &price), newModel(2, 0, "20", nil))
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT "blockNumber", "transactionIndex", "from", "value", "spotPrice" FROM "transactions" ORDER BY 1, 2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	expected := []string{"1.0.10.1.5", "1.1.12.1.5", "2.0.20.<nil>"}
+	n := 0
+	for rows.Next() {
+		var bn, txId int64
+		var from, value string
+		var spotPrice sql.NullFloat64
+		if err := rows.Scan(&bn, &txId, &from, &value, &spotPrice); err != nil {
+			t.Fatal(err)
+		}
+		if from != "0x0000000000000000000000000000000000000001" {
+			t.Error("wrong from", from)
+		}
+		result := fmt.Sprintf("%d.%d.%s.<nil>", bn, txId, value)
+		if spotPrice.Valid {
+			result = fmt.Sprintf("%d.%d.%s.%g", bn, txId, value, spotPrice.Float64)
+		}
+		if n >= len(expected) || result != expected[n] {
+			t.Error("wrong row", n, result)
+		}
+		n++
+	}
+	if n != len(expected) {
+		t.Fatal("wrong number of rows", n)
+	}
+}
+
+func TestSqlWriter_MissingKey(t *testing.T) {
+	sw, err := NewSqlWriter("sqlite://" + filepath.Join(t.TempDir(), "exports.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sw.Close()
+
+	model := types.Model{
+		Data:  map[string]any{"blockNumber": base.Blknum(1), "logIndex": uint64(0)},
+		Order: []string{"blockNumber", "logIndex"},
+	}
+	if err := sw.Write("logs", model); err != nil {
+		t.Fatal(err)
+	}
+	delete(model.Data, "logIndex")
+	if err := sw.Write("logs", model); err == nil {
+		t.Fatal("expected an error for a row without its key")
+	}
+}
+
+func TestSqlWriter_Traces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exports.db")
+
+	newTrace := func(bn base.Blknum, txId base.Txnum, to string) types.Model {
+		return types.Model{
+			Data: map[string]any{
+				"blockNumber":      bn,
+				"transactionIndex": txId,
+				"action::to":       to,
+			},
+			Order: []string{"blockNumber", "transactionIndex", "action::to"},
+		}
+	}
+
+	// Writing the same traces twice must neither lose nor duplicate any of them
+	for i := 0; i < 2; i++ {
+		sw, err := NewSqlWriter("sqlite://" + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, model := range []types.Model{newTrace(1, 0, "0x1"), newTrace(1, 0, "0x2"), newTrace(1, 0, "0x3"), newTrace(1, 1, "0x4")} {
+			if err := sw.Write("traces", model); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := sw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT "transactionIndex", "traceIndex", "action::to" FROM "traces" ORDER BY 1, 2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	expected := []string{"0.0.0x1", "0.1.0x2", "0.2.0x3", "1.0.0x4"}
+	n := 0
+	for rows.Next() {
+		var txId, traceId int64
+		var to string
+		if err := rows.Scan(&txId, &traceId, &to); err != nil {
+			t.Fatal(err)
+		}
+		if result := fmt.Sprintf("%d.%d.%s", txId, traceId, to); n >= len(expected) || result != expected[n] {
+			t.Error("wrong row", n, result)
+		}
+		n++
+	}
+	if n != len(expected) {
+		t.Fatal("wrong number of rows", n)
+	}
+}
+
+func TestSqlWriter_TransferBatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exports.db")
+	sw, err := NewSqlWriter("sqlite://" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A single TransferBatch log moves two token IDs, each with its own statement
+	for _, id := range []int64{7, 9} {
+		s := types.SimpleStatement{
+			AccountedFor:     base.HexToAddress("0xf503017d7baf7fbc0fff7492b751025c6a78179b"),
+			AssetAddr:        base.HexToAddress("0x76be3b62873462d2142405439777e971754e8e77"),
+			BlockNumber:      16000000,
+			TransactionIndex: 12,
+			LogIndex:         40,
+			TokenType:        types.TokenErc1155,
+		}
+		s.TokenId.SetInt64(id)
+		s.AmountIn.SetInt64(id * 10)
+		if err := sw.Write("statements", s.Model("mainnet", "csv", false, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT "tokenId", "amountIn" FROM "statements" ORDER BY 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	expected := []string{"7.70", "9.90"}
+	n := 0
+	for rows.Next() {
+		var id, amount string
+		if err := rows.Scan(&id, &amount); err != nil {
+			t.Fatal(err)
+		}
+		if result := id + "." + amount; n >= len(expected) || result != expected[n] {
+			t.Error("wrong row", n, result)
+		}
+		n++
+	}
+	if n != len(expected) {
+		t.Fatal("wrong number of rows", n)
+	}
+}
+
+func TestSqlWriter_Rollback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exports.db")
+	sw, err := NewSqlWriter("sqlite://" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model := types.Model{
+		Data:  map[string]any{"blockNumber": base.Blknum(1), "logIndex": uint64(0)},
+		Order: []string{"blockNumber", "logIndex"},
+	}
+	if err := sw.Write("logs", model); err != nil {
+		t.Fatal(err)
+	}
+	if err := sw.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Even the table, created in the same transaction, is gone
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'logs'`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatal("expected nothing to be written")
+	}
+}
@@ -117,8 +117,10 @@ func logErrors(errsToReport []string) {
 
 type fetchDataFunc[Raw types.RawData] func(modelChan chan types.Modeler[Raw], errorChan chan error)
 
-// StreamMany outputs models or raw data as they are acquired
-func StreamMany[Raw types.RawData](ctx context.Context, fetchData fetchDataFunc[Raw], options OutputOptions) error {
+// StreamMany outputs models or raw data as they are acquired. Parquet output is columnar, so
+// its rows are buffered and the file is completed only after the last model has arrived. If
+// OutputFn names a database, models are written to it instead (see SqlWriter).
+func StreamMany[Raw types.RawData](ctx context.Context, fetchData fetchDataFunc[Raw], options OutputOptions) (err error) {
 	errsToReport := make([]string, 0)
 
 	modelChan := make(chan types.Modeler[Raw])
@@ -145,6 +147,29 @@ func StreamMany[Raw types.RawData](ctx context.Context, fetchData fetchDataFunc[
 		jw = options.Writer.(*JsonWriter)
 	}
 
+	var pw *ParquetWriter
+	var sw *SqlWriter
+	if IsDatabaseDsn(options.OutputFn) && !options.ShowRaw {
+		if sw, err = NewSqlWriter(options.OutputFn); err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				// Leave the database as it was rather than with part of the output
+				_ = sw.Rollback()
+			} else {
+				err = sw.Close()
+			}
+		}()
+	} else if options.Format == "parquet" && !options.ShowRaw {
+		pw = NewParquetWriter(options.Writer)
+		defer func() {
+			if closeErr := pw.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+	}
+
 	// If user wants custom format, we have to prepare the template
 	customFormat := strings.Contains(options.Format, "{")
 	tmpl, err := template.New("").Parse(options.Format)
@@ -166,7 +191,11 @@ func StreamMany[Raw types.RawData](ctx context.Context, fetchData fetchDataFunc[
 				err = streamRaw(options.Writer, model.Raw())
 			} else {
 				modelValue := model.Model(options.Chain, options.Format, options.Verbose, options.Extra)
-				if customFormat {
+				if sw != nil {
+					err = sw.Write(SqlTableName(model), modelValue)
+				} else if pw != nil {
+					err = pw.Write(modelValue)
+				} else if customFormat {
 					err = StreamWithTemplate(options.Writer, modelValue, tmpl)
 				} else {
 					err = StreamModel(options.Writer, modelValue, OutputOptions{
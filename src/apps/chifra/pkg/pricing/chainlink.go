@@ -0,0 +1,100 @@
+package pricing
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+const latestRoundDataSelector = "0xfeaf968c" // latestRoundData()
+
+// chainlinkFeeds are the USD aggregators we know about without configuration. Other feeds
+// may be added per asset with chainlinkFeed in pricing.toml.
+var chainlinkFeeds = map[string]map[base.Address]base.Address{
+	"mainnet": {
+		base.FAKE_ETH_ADDRESS: base.HexToAddress("0x5f4ec3df9cbd43714fe2740f5e3616155c5b8419"), // ETH / USD
+		wethAddress:           base.HexToAddress("0x5f4ec3df9cbd43714fe2740f5e3616155c5b8419"), // ETH / USD
+		base.HexToAddress("0x2260fac5e5542a773aa44fbcfedf7c193bc2c599"): base.HexToAddress("0xf4030086522a5beea4988f8ca5b36dbc97bee88c"), // WBTC (BTC / USD)
+		base.HexToAddress("0x514910771af9ca656af840dff83e8264ecf986ca"): base.HexToAddress("0x2c1d072e956affc0d435cb7ac38ef18d24d9127c"), // LINK / USD
+	},
+}
+
+// PriceUsdChainlink returns the price of the asset in USD from the answer of the asset's
+// Chainlink aggregator as of the given block number.
+func PriceUsdChainlink(conn *rpc.Connection, testMode bool, statement *types.SimpleStatement) (price float64, source string, err error) {
+	feed, ok := GetSettings(conn.Chain).chainlinkFeed(statement.AssetAddr)
+	if !ok {
+		if feed, ok = chainlinkFeeds[conn.Chain][statement.AssetAddr]; !ok {
+			return 0.0, "not-priced-no-feed", nil
+		}
+	}
+
+	words, err := rawCall(conn, feed, latestRoundDataSelector, statement.BlockNumber)
+	if err != nil || len(words) < 5 {
+		// The feed did not exist at this block (or is not an aggregator)
+		msg := fmt.Sprintf("Chainlink feed %s has no answer at block %d", feed.Hex(), statement.BlockNumber)
+		logger.TestLog(true, msg)
+		return 0.0, "not-priced-no-feed", nil
+	}
+
+	answer := toSigned(words[1])
+	if answer.Sign() <= 0 {
+		return 0.0, "not-priced-no-feed", nil
+	}
+
+	if isStaleRound(words, statement.Timestamp, GetSettings(conn.Chain).ChainlinkMaxAge) {
+		msg := fmt.Sprintf("Chainlink feed %s is stale at block %d (last updated at %d)", feed.Hex(), statement.BlockNumber, words[3].Uint64())
+		logger.TestLog(true, msg)
+		return 0.0, "not-priced-stale-feed", nil
+	}
+
+	decimals, err := getDecimals(conn, feed, statement.BlockNumber)
+	if err != nil {
+		return 0.0, "not-priced", err
+	}
+
+	bigPrice := scaleDown(answer, decimals)
+	price, _ = bigPrice.Float64()
+	source = "chainlink"
+
+	r := priceDebugger{
+		address:     statement.AssetAddr,
+		symbol:      statement.AssetSymbol,
+		blockNumber: statement.BlockNumber,
+		source1:     feed,
+		theCall1:    "latestRoundData()",
+		source2:     base.ZeroAddr,
+		theCall2:    "decimals()",
+		first:       base.ZeroAddr,
+		second:      base.ZeroAddr,
+		int0:        answer,
+		int1:        new(big.Int).SetUint64(decimals),
+		bigPrice:    bigPrice,
+		price:       price,
+		source:      source,
+	}
+	r.report("using Chainlink", testMode)
+
+	return price, source, nil
+}
+
+// isStaleRound returns true if the round described by latestRoundData's result (roundId, answer,
+// startedAt, updatedAt, answeredInRound) was not completed, or was last updated more than maxAge
+// seconds before the given timestamp
+func isStaleRound(words []*big.Int, ts base.Timestamp, maxAge uint64) bool {
+	updatedAt := words[3]
+	if updatedAt.Sign() == 0 || words[4].Cmp(words[0]) < 0 {
+		return true
+	}
+	return ts > base.Timestamp(updatedAt.Int64()) && uint64(ts)-updatedAt.Uint64() > maxAge
+}
+
+// scaleDown returns value / 10^decimals
+func scaleDown(value *big.Int, decimals uint64) *big.Float {
+	divisor := new(big.Int).Exp(big.NewInt(10), new(big.Int).SetUint64(decimals), nil)
+	return new(big.Float).Quo(new(big.Float).SetInt(value), new(big.Float).SetInt(divisor))
+}
@@ -0,0 +1,120 @@
+package pricing
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+)
+
+// defaultPriority is the order in which price sources are tried if pricing.toml does not say
+// otherwise. It matches the behaviour prior to the addition of Chainlink and Uniswap V3.
+var defaultPriority = []string{"uniswap", "maker"}
+
+// defaultChainlinkMaxAge is a day (the longest heartbeat of the common Chainlink feeds) and an hour
+const defaultChainlinkMaxAge = 25 * 60 * 60
+
+// assetSettings customizes pricing for a single asset
+type assetSettings struct {
+	Address       string   `toml:"address"`
+	Priority      []string `toml:"priority"`
+	ChainlinkFeed string   `toml:"chainlinkFeed"`
+}
+
+// Settings are the pricing configuration items for a chain found in the chain's pricing.toml file
+type Settings struct {
+	// Priority is the order in which price sources are tried for assets not listed in Assets
+	Priority []string `toml:"priority"`
+	// TwapWindow, if not zero, is the number of seconds over which Uniswap V3 prices are
+	// averaged. If zero, the pool's spot price is used.
+	TwapWindow uint32 `toml:"twapWindow"`
+	// ChainlinkMaxAge is the number of seconds after which a Chainlink answer is considered stale
+	// and the asset is not priced by Chainlink
+	ChainlinkMaxAge uint64 `toml:"chainlinkMaxAge"`
+
+	assets map[base.Address]assetSettings
+}
+
+var settingsMap = make(map[string]*Settings)
+var settingsMutex sync.Mutex
+
+// GetSettings returns the pricing settings for the chain. The file is read only once.
+func GetSettings(chain string) *Settings {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+
+	if s, ok := settingsMap[chain]; ok {
+		return s
+	}
+
+	configFn := filepath.Join(config.MustGetPathToChainConfig(chain), "pricing.toml")
+	s, err := readSettings(configFn)
+	if err != nil {
+		logger.Warn("Could not load", configFn, "using default pricing:", err)
+	}
+	settingsMap[chain] = s
+	return s
+}
+
+// readSettings reads the settings from the given file. A missing file yields the defaults.
+func readSettings(configFn string) (*Settings, error) {
+	ret := &Settings{
+		Priority:        defaultPriority,
+		ChainlinkMaxAge: defaultChainlinkMaxAge,
+		assets:          make(map[base.Address]assetSettings),
+	}
+	if !file.FileExists(configFn) {
+		return ret, nil
+	}
+
+	type tomlFile struct {
+		Settings Settings
+		Asset    []assetSettings
+	}
+	var t tomlFile
+	if _, err := toml.Decode(file.AsciiFileToString(configFn), &t); err != nil {
+		return ret, err
+	}
+
+	if len(t.Settings.Priority) > 0 {
+		ret.Priority = normalize(t.Settings.Priority)
+	}
+	ret.TwapWindow = t.Settings.TwapWindow
+	if t.Settings.ChainlinkMaxAge > 0 {
+		ret.ChainlinkMaxAge = t.Settings.ChainlinkMaxAge
+	}
+	for _, asset := range t.Asset {
+		asset.Priority = normalize(asset.Priority)
+		ret.assets[base.HexToAddress(asset.Address)] = asset
+	}
+	return ret, nil
+}
+
+// PriorityFor returns the ordered list of price sources to try for the given asset
+func (s *Settings) PriorityFor(asset base.Address) []string {
+	if a, ok := s.assets[asset]; ok && len(a.Priority) > 0 {
+		return a.Priority
+	}
+	return s.Priority
+}
+
+// chainlinkFeed returns the configured Chainlink aggregator for the asset, if any
+func (s *Settings) chainlinkFeed(asset base.Address) (base.Address, bool) {
+	if a, ok := s.assets[asset]; ok && len(a.ChainlinkFeed) > 0 {
+		return base.HexToAddress(a.ChainlinkFeed), true
+	}
+	return base.ZeroAddr, false
+}
+
+func normalize(sources []string) []string {
+	ret := make([]string, 0, len(sources))
+	for _, source := range sources {
+		ret = append(ret, strings.ToLower(strings.TrimSpace(source)))
+	}
+	return ret
+}
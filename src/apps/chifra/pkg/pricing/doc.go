@@ -1,2 +1,3 @@
-// Package pricing calculates US dollar prices from Uniswap pairs or falls back onto Maker
+// Package pricing calculates US dollar prices from Chainlink aggregators, Uniswap V3 pools, Uniswap V2 pairs, or Maker
+// in the order configured per asset in the chain's pricing.toml
 package pricing
@@ -10,7 +10,22 @@ import (
 
 // TODO: Much of this reporting could be removed as it's only used for debugging
 
-// PriceUsd returns the price of the asset in USD
+// priceFunc prices a statement's asset in USD. If the source does not apply to the asset
+// (for example, the block is prior to the source's deployment) it returns a zero price, a
+// reason in place of the source, and no error.
+type priceFunc func(conn *rpc.Connection, testMode bool, statement *types.SimpleStatement) (float64, string, error)
+
+// priceSources are the available price sources keyed by the names used in pricing.toml
+var priceSources = map[string]priceFunc{
+	"chainlink": PriceUsdChainlink,
+	"uniswapv3": PriceUsdUniswapV3,
+	"uniswap":   priceUsdUniswapV2,
+	"maker":     priceUsdMakerEth,
+}
+
+// PriceUsd returns the price of the asset in USD. The price sources are tried in the order
+// configured for the asset in pricing.toml. The first to return a price wins and its name
+// is returned as the source.
 func PriceUsd(conn *rpc.Connection, testMode bool, statement *types.SimpleStatement) (price float64, source string, err error) {
 	if statement.IsStableCoin() {
 		r := priceDebugger{
@@ -21,15 +36,56 @@ func PriceUsd(conn *rpc.Connection, testMode bool, statement *types.SimpleStatem
 		return 1.0, "stable-coin", nil
 	}
 
+	if statement.TokenType.IsNft() {
+		// Individual token IDs have no market price on these sources
+		return 0.0, "not-priced-nft", nil
+	}
+
+	return priceFromSources(conn, testMode, statement, GetSettings(conn.Chain).PriorityFor(statement.AssetAddr))
+}
+
+// priceFromSources tries each named source in turn. If none prices the asset, it returns the
+// reason given by the last source that applied (or the last error encountered).
+func priceFromSources(conn *rpc.Connection, testMode bool, statement *types.SimpleStatement, priority []string) (price float64, source string, err error) {
+	source = "not-priced"
+	for _, name := range priority {
+		priceFn, ok := priceSources[name]
+		if !ok {
+			logger.Warn("Unknown price source", name, "in pricing.toml")
+			continue
+		}
+
+		p, s, e := priceFn(conn, testMode, statement)
+		if e != nil {
+			logger.TestLog(true, fmt.Sprintf("Price source %s failed: %s", name, e))
+			source, err = "not-priced", e
+			continue
+		}
+		if p != 0.0 {
+			return p, s, nil
+		}
+		source, err = s, nil
+	}
+	return 0.0, source, err
+}
+
+// priceUsdUniswapV2 prices an asset with Uniswap V2 provided the block is after its deployment
+func priceUsdUniswapV2(conn *rpc.Connection, testMode bool, statement *types.SimpleStatement) (float64, string, error) {
 	if statement.BlockNumber <= uniswapFactoryV2_deployed {
 		if statement.IsEth() {
-			return PriceUsdMaker(conn, testMode, statement)
-		} else {
-			msg := fmt.Sprintf("Block %d is prior to deployment (%d) of Uniswap V2. No other source for tokens prior to UniSwap", statement.BlockNumber, uniswapFactoryV2_deployed)
-			logger.TestLog(true, msg)
-			return 0.0, "token-not-priced-pre-uni", nil
+			return 0.0, "eth-not-priced-pre-uni", nil
 		}
+		msg := fmt.Sprintf("Block %d is prior to deployment (%d) of Uniswap V2. No other source for tokens prior to UniSwap", statement.BlockNumber, uniswapFactoryV2_deployed)
+		logger.TestLog(true, msg)
+		return 0.0, "token-not-priced-pre-uni", nil
 	}
-
 	return PriceUsdUniswap(conn, testMode, statement)
 }
+
+// priceUsdMakerEth prices ETH (and only ETH) with the Maker medianizer
+func priceUsdMakerEth(conn *rpc.Connection, testMode bool, statement *types.SimpleStatement) (float64, string, error) {
+	if !statement.IsEth() {
+		return 0.0, "token-not-priced-by-maker", nil
+	}
+	return PriceUsdMaker(conn, testMode, statement)
+}
@@ -0,0 +1,155 @@
+package pricing
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func TestPriceFromSources(t *testing.T) {
+	saved := priceSources
+	defer func() { priceSources = saved }()
+
+	fixed := func(price float64, source string, err error) priceFunc {
+		return func(*rpc.Connection, bool, *types.SimpleStatement) (float64, string, error) {
+			return price, source, err
+		}
+	}
+	priceSources = map[string]priceFunc{
+		"missing": fixed(0.0, "not-priced-no-feed", nil),
+		"broken":  fixed(0.0, "not-priced", errors.New("call failed")),
+		"first":   fixed(10.0, "first", nil),
+		"second":  fixed(20.0, "second", nil),
+	}
+
+	tests := []struct {
+		priority []string
+		price    float64
+		source   string
+		wantErr  bool
+	}{
+		{priority: []string{"first", "second"}, price: 10.0, source: "first"},
+		{priority: []string{"second", "first"}, price: 20.0, source: "second"},
+		{priority: []string{"missing", "broken", "second"}, price: 20.0, source: "second"},
+		{priority: []string{"unknown", "first"}, price: 10.0, source: "first"},
+		{priority: []string{"broken", "missing"}, price: 0.0, source: "not-priced-no-feed"},
+		{priority: []string{"missing", "broken"}, price: 0.0, source: "not-priced", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		price, source, err := priceFromSources(nil, false, &types.SimpleStatement{}, tt.priority)
+		if price != tt.price || source != tt.source || (err != nil) != tt.wantErr {
+			t.Error(tt.priority, "unexpected result", price, source, err)
+		}
+	}
+}
+
+func TestReadSettings(t *testing.T) {
+	asset := base.HexToAddress("0x1f9840a85d5af5bf1d1762f925bdaddc4201f984")
+	feed := base.HexToAddress("0x553303d460ee0afb37edff9be42922d8ff63220e")
+
+	configFn := filepath.Join(t.TempDir(), "pricing.toml")
+	contents := `[settings]
+priority = ["Chainlink", "uniswapv3", "uniswap"]
+twapWindow = 1800
+chainlinkMaxAge = 3600
+
+[[asset]]
+address = "` + asset.Hex() + `"
+priority = ["uniswap"]
+chainlinkFeed = "` + feed.Hex() + `"
+`
+	if err := os.WriteFile(configFn, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := readSettings(configFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.TwapWindow != 1800 || s.ChainlinkMaxAge != 3600 {
+		t.Error("wrong settings", s.TwapWindow, s.ChainlinkMaxAge)
+	}
+	if got := s.PriorityFor(base.FAKE_ETH_ADDRESS); len(got) != 3 || got[0] != "chainlink" {
+		t.Error("wrong default priority", got)
+	}
+	if got := s.PriorityFor(asset); len(got) != 1 || got[0] != "uniswap" {
+		t.Error("wrong asset priority", got)
+	}
+	if got, ok := s.chainlinkFeed(asset); !ok || got != feed {
+		t.Error("wrong chainlink feed", got)
+	}
+
+	if s, err = readSettings(filepath.Join(t.TempDir(), "missing.toml")); err != nil || len(s.Priority) != len(defaultPriority) ||
+		s.ChainlinkMaxAge != defaultChainlinkMaxAge {
+		t.Error("expected the defaults for a missing file", s.Priority, s.ChainlinkMaxAge, err)
+	}
+}
+
+func TestChainlinkStaleRound(t *testing.T) {
+	round := func(roundId, updatedAt, answeredInRound int64) []*big.Int {
+		return []*big.Int{big.NewInt(roundId), big.NewInt(200000000000), big.NewInt(updatedAt), big.NewInt(updatedAt), big.NewInt(answeredInRound)}
+	}
+
+	ts := base.Timestamp(1700000000)
+	tests := []struct {
+		name     string
+		words    []*big.Int
+		expected bool
+	}{
+		{"fresh", round(10, 1700000000-600, 10), false},
+		{"at the limit", round(10, 1700000000-3600, 10), false},
+		{"stale", round(10, 1700000000-3601, 10), true},
+		{"never updated", round(10, 0, 10), true},
+		{"answered in an earlier round", round(10, 1700000000-600, 9), true},
+	}
+	for _, tt := range tests {
+		if got := isStaleRound(tt.words, ts, 3600); got != tt.expected {
+			t.Error(tt.name, "expected", tt.expected, "got", got)
+		}
+	}
+}
+
+func TestUniswapV3Math(t *testing.T) {
+	// sqrtPriceX96 of 2^96 is a raw price of exactly one
+	one := new(big.Int).Lsh(big.NewInt(1), 96)
+	if p, _ := sqrtPriceX96ToPrice(one).Float64(); p != 1.0 {
+		t.Error("expected a price of 1, got", p)
+	}
+
+	// A USDC (6 decimals) / WETH (18 decimals) pool with ETH at $2,000
+	raw := big.NewFloat(1.0 / 2000.0 * 1e12)
+	if p, _ := adjustDecimals(raw, 6, 18).Float64(); math.Abs(p-1.0/2000.0) > 1e-12 {
+		t.Error("wrong decimal adjustment", p)
+	}
+
+	if p, _ := tickToPrice(0).Float64(); p != 1.0 {
+		t.Error("expected a price of 1 at tick 0, got", p)
+	}
+
+	// observe returns an offset to each array followed by the arrays themselves
+	encode := func(values ...int64) []*big.Int {
+		words := []*big.Int{big.NewInt(64), big.NewInt(160), big.NewInt(2)}
+		for _, v := range values {
+			w := big.NewInt(v)
+			if v < 0 {
+				w.Add(w, new(big.Int).Lsh(big.NewInt(1), 256))
+			}
+			words = append(words, w)
+		}
+		return append(words, big.NewInt(2), big.NewInt(0), big.NewInt(0))
+	}
+	if tick, err := averageTick(encode(1000, 61000), 60); err != nil || tick != 1000 {
+		t.Error("wrong average tick", tick, err)
+	}
+	if tick, err := averageTick(encode(0, -61), 60); err != nil || tick != -2 {
+		t.Error("expected negative ticks to round down", tick, err)
+	}
+}
@@ -0,0 +1,72 @@
+package pricing
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
+)
+
+// rawCall calls a contract at the given block with pre-encoded data and returns the 32-byte
+// words of the result. We use raw calls for Chainlink and Uniswap V3 so that pricing does not
+// depend on the ABIs of those contracts being available.
+func rawCall(conn *rpc.Connection, to base.Address, data string, bn base.Blknum) ([]*big.Int, error) {
+	ret, err := query.Query[string](conn.Chain, "eth_call", query.Params{
+		map[string]any{
+			"to":   to.Hex(),
+			"data": data,
+		},
+		fmt.Sprintf("0x%x", bn),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hex := strings.TrimPrefix(*ret, "0x")
+	if len(hex) == 0 || len(hex)%64 != 0 {
+		return nil, fmt.Errorf("unexpected result calling %s at block %d", to.Hex(), bn)
+	}
+
+	words := make([]*big.Int, 0, len(hex)/64)
+	for i := 0; i < len(hex); i += 64 {
+		word, ok := new(big.Int).SetString(hex[i:i+64], 16)
+		if !ok {
+			return nil, fmt.Errorf("invalid result calling %s at block %d", to.Hex(), bn)
+		}
+		words = append(words, word)
+	}
+	return words, nil
+}
+
+// toSigned interprets a 32-byte word as a two's complement signed integer
+func toSigned(word *big.Int) *big.Int {
+	ret := new(big.Int).Set(word)
+	if ret.Bit(255) == 1 {
+		ret.Sub(ret, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	return ret
+}
+
+// encodeAddress returns the address left-padded to a 32-byte argument
+func encodeAddress(addr base.Address) string {
+	return fmt.Sprintf("%064s", strings.TrimPrefix(strings.ToLower(addr.Hex()), "0x"))
+}
+
+// encodeUint returns the value as a 32-byte argument
+func encodeUint(value uint64) string {
+	return fmt.Sprintf("%064x", value)
+}
+
+// getDecimals returns the number of decimals of an ERC-20 token
+func getDecimals(conn *rpc.Connection, token base.Address, bn base.Blknum) (uint64, error) {
+	words, err := rawCall(conn, token, decimalsSelector, bn)
+	if err != nil {
+		return 0, err
+	}
+	return words[0].Uint64(), nil
+}
+
+const decimalsSelector = "0x313ce567" // decimals()
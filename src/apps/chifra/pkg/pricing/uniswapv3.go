@@ -0,0 +1,216 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// uniswapV3Deployment is a chain's Uniswap V3 factory (and the block it was deployed at) along
+// with the tokens ETH and other assets are priced against
+type uniswapV3Deployment struct {
+	factory  base.Address
+	deployed base.Blknum
+	weth     base.Address
+	usdc     base.Address
+}
+
+// uniswapV3Deployments are the chains on which we price with Uniswap V3. Other chains are not.
+var uniswapV3Deployments = map[string]uniswapV3Deployment{
+	"mainnet": {
+		factory:  base.HexToAddress("0x1f98431c8ad98523631ae4a59f267346ea31f984"),
+		deployed: 12369621,
+		weth:     wethAddress,
+		usdc:     base.HexToAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"),
+	},
+}
+
+// The fee tiers we search for a pool, in order of preference
+var uniswapV3Fees = []uint64{500, 3000, 10000, 100}
+
+const (
+	getPoolSelector = "0x1698ee82" // getPool(address,address,uint24)
+	slot0Selector   = "0x3850c7bd" // slot0()
+	observeSelector = "0x883bdbfd" // observe(uint32[])
+)
+
+// PriceUsdUniswapV3 returns the price of the given asset in USD as of the given block number
+// using Uniswap V3 pools. ETH is priced against USDC, other tokens are priced against WETH and
+// then converted to USD. Chains without a known Uniswap V3 deployment are not priced. If a TWAP window is configured, prices are time-weighted averages
+// over that many seconds, otherwise the pool's spot price is used.
+func PriceUsdUniswapV3(conn *rpc.Connection, testMode bool, statement *types.SimpleStatement) (price float64, source string, err error) {
+	uni, ok := uniswapV3Deployments[conn.Chain]
+	if !ok {
+		msg := fmt.Sprintf("Uniswap V3 is not known on chain %s.", conn.Chain)
+		logger.TestLog(true, msg)
+		return 0.0, "not-priced-no-uni-v3", nil
+	}
+
+	if statement.BlockNumber <= uni.deployed {
+		msg := fmt.Sprintf("Block %d is prior to deployment (%d) of Uniswap V3.", statement.BlockNumber, uni.deployed)
+		logger.TestLog(true, msg)
+		return 0.0, "not-priced-pre-uni-v3", nil
+	}
+
+	twapWindow := GetSettings(conn.Chain).TwapWindow
+
+	multiplier := float64(1.0)
+	var asset, quote base.Address
+	if statement.IsEth() || statement.AssetAddr == uni.weth {
+		asset, quote = uni.weth, uni.usdc
+	} else {
+		temp := *statement
+		temp.AssetAddr = base.FAKE_ETH_ADDRESS
+		temp.AssetSymbol = "WEI"
+		if multiplier, _, err = PriceUsdUniswapV3(conn, testMode, &temp); err != nil || multiplier == 0.0 {
+			return 0.0, "not-priced", err
+		}
+		asset, quote = statement.AssetAddr, uni.weth
+	}
+
+	pool, err := findPoolV3(conn, uni.factory, asset, quote, statement.BlockNumber)
+	if err != nil {
+		return 0.0, "not-priced", err
+	}
+
+	bigPrice, err := poolPriceV3(conn, pool, asset, quote, statement.BlockNumber, twapWindow)
+	if err != nil {
+		return 0.0, "not-priced", err
+	}
+
+	price, _ = bigPrice.Float64()
+	price *= multiplier
+	source = "uniswapv3"
+
+	theCall2 := "slot0()"
+	if twapWindow > 0 {
+		theCall2 = fmt.Sprintf("observe([%d, 0])", twapWindow)
+	}
+	r := priceDebugger{
+		address:     statement.AssetAddr,
+		symbol:      statement.AssetSymbol,
+		blockNumber: statement.BlockNumber,
+		source1:     uni.factory,
+		theCall1:    fmt.Sprintf("getPool(%s, %s)", asset.Hex(), quote.Hex()),
+		source2:     pool,
+		theCall2:    theCall2,
+		first:       asset,
+		second:      quote,
+		float2:      new(big.Float).SetFloat64(multiplier),
+		bigPrice:    bigPrice,
+		price:       price,
+		source:      source,
+	}
+	r.report("using Uniswap V3", testMode)
+
+	return price, source, nil
+}
+
+// findPoolV3 returns the first pool the factory has for the pair searching the fee tiers in order
+func findPoolV3(conn *rpc.Connection, factory, tokenA, tokenB base.Address, bn base.Blknum) (base.Address, error) {
+	for _, fee := range uniswapV3Fees {
+		data := getPoolSelector + encodeAddress(tokenA) + encodeAddress(tokenB) + encodeUint(fee)
+		words, err := rawCall(conn, factory, data, bn)
+		if err != nil {
+			return base.ZeroAddr, err
+		}
+		if pool := base.HexToAddress(fmt.Sprintf("0x%040x", words[0])); !pool.IsZero() {
+			return pool, nil
+		}
+	}
+	return base.ZeroAddr, fmt.Errorf("no Uniswap V3 pool found for %s and %s", tokenA.Hex(), tokenB.Hex())
+}
+
+// poolPriceV3 returns the price of one asset in units of quote from the given pool
+func poolPriceV3(conn *rpc.Connection, pool, asset, quote base.Address, bn base.Blknum, twapWindow uint32) (*big.Float, error) {
+	var rawPrice *big.Float
+	if twapWindow > 0 {
+		data := observeSelector + encodeUint(32) + encodeUint(2) + encodeUint(uint64(twapWindow)) + encodeUint(0)
+		words, err := rawCall(conn, pool, data, bn)
+		if err != nil {
+			return nil, err
+		}
+		tick, err := averageTick(words, twapWindow)
+		if err != nil {
+			return nil, err
+		}
+		rawPrice = tickToPrice(tick)
+	} else {
+		words, err := rawCall(conn, pool, slot0Selector, bn)
+		if err != nil {
+			return nil, err
+		}
+		rawPrice = sqrtPriceX96ToPrice(words[0])
+	}
+
+	// Pools order their tokens by address and quote the price of token0 in units of token1
+	token0, token1 := asset, quote
+	if token0.Hex() > token1.Hex() {
+		token0, token1 = token1, token0
+	}
+	dec0, err := getDecimals(conn, token0, bn)
+	if err != nil {
+		return nil, err
+	}
+	dec1, err := getDecimals(conn, token1, bn)
+	if err != nil {
+		return nil, err
+	}
+
+	price := adjustDecimals(rawPrice, dec0, dec1)
+	if price.Sign() == 0 {
+		return nil, errors.New("pool has no price")
+	}
+	if asset != token0 {
+		price = new(big.Float).Quo(big.NewFloat(1), price)
+	}
+	return price, nil
+}
+
+// sqrtPriceX96ToPrice converts a pool's sqrtPriceX96 into the raw price of token0 in units of token1
+func sqrtPriceX96ToPrice(sqrtPriceX96 *big.Int) *big.Float {
+	q96 := new(big.Float).SetInt(new(big.Int).Lsh(big.NewInt(1), 96))
+	ratio := new(big.Float).Quo(new(big.Float).SetInt(sqrtPriceX96), q96)
+	return new(big.Float).Mul(ratio, ratio)
+}
+
+// tickToPrice converts a tick into the raw price of token0 in units of token1
+func tickToPrice(tick int64) *big.Float {
+	return big.NewFloat(math.Pow(1.0001, float64(tick)))
+}
+
+// averageTick decodes the result of observe([twapWindow, 0]) and returns the time-weighted
+// average tick over the window
+func averageTick(words []*big.Int, twapWindow uint32) (int64, error) {
+	if len(words) < 2 {
+		return 0, errors.New("invalid observe result")
+	}
+	idx := int(words[0].Uint64() / 32)
+	if len(words) < idx+3 || words[idx].Uint64() != 2 {
+		return 0, errors.New("invalid observe result")
+	}
+	delta := new(big.Int).Sub(toSigned(words[idx+2]), toSigned(words[idx+1]))
+	window := big.NewInt(int64(twapWindow))
+	tick := new(big.Int).Quo(delta, window)
+	if delta.Sign() < 0 && new(big.Int).Rem(delta, window).Sign() != 0 {
+		// round towards negative infinity as Uniswap's OracleLibrary does
+		tick.Sub(tick, big.NewInt(1))
+	}
+	return tick.Int64(), nil
+}
+
+// adjustDecimals converts a raw price of token0 in units of token1 into whole units
+func adjustDecimals(rawPrice *big.Float, dec0, dec1 uint64) *big.Float {
+	if dec0 >= dec1 {
+		mult := new(big.Int).Exp(big.NewInt(10), new(big.Int).SetUint64(dec0-dec1), nil)
+		return new(big.Float).Mul(rawPrice, new(big.Float).SetInt(mult))
+	}
+	div := new(big.Int).Exp(big.NewInt(10), new(big.Int).SetUint64(dec1-dec0), nil)
+	return new(big.Float).Quo(rawPrice, new(big.Float).SetInt(div))
+}
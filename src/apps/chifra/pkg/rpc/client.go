@@ -14,6 +14,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
 	"github.com/ethereum/go-ethereum/ethclient"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
 )
 
 // GetClientVersion returns the version of the client
@@ -93,7 +94,17 @@ func (conn *Connection) getClient() (*ethclient.Client, error) {
 	defer clientMutex.Unlock()
 
 	if perProviderClientMap[provider] == nil {
-		ec, err := ethclient.Dial(provider)
+		var ec *ethclient.Client
+		var err error
+		if query.HasManyProviders(conn.Chain) {
+			// The requests are spread across all of the chain's providers
+			var rc *gethrpc.Client
+			if rc, err = gethrpc.DialHTTPWithClient(provider, query.HttpClient(conn.Chain)); err == nil {
+				ec = ethclient.NewClient(rc)
+			}
+		} else {
+			ec, err = ethclient.Dial(provider)
+		}
 		if err != nil || ec == nil {
 			logger.Error("Missdial("+provider+"):", err)
 			logger.Fatal("")
@@ -6,6 +6,7 @@ import (
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
 )
@@ -55,12 +56,22 @@ func NewReadOnlyConnection(chain string) *Connection {
 func (settings settings) GetRpcConnection() *Connection {
 	forceReadonly := !settings.CacheEnabled || settings.ReadonlyCache
 
+	location, err := cache.LocationFromName(config.GetCacheStore())
+	if err != nil {
+		logger.Warn("Using the file system cache:", err)
+	}
+
+	limit, err := config.GetCacheLimit(settings.Chain)
+	if err != nil {
+		logger.Warn("The cache size is not limited:", err)
+	}
+
 	var store *cache.Store
-	var err error
 	if store, err = cache.NewStore(&cache.StoreOptions{
-		Location: cache.FsCache,
+		Location: location,
 		Chain:    settings.Chain,
 		ReadOnly: forceReadonly,
+		Limit:    limit,
 	}); err != nil {
 		// If there was an error, we won't use the cache
 		logger.Warn("Cannot initialize cache:", err)
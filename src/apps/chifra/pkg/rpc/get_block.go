@@ -141,6 +141,28 @@ func (conn *Connection) GetBlockTimestamp(bn base.Blknum) base.Timestamp {
 	}
 }
 
+// GetBlockHashes returns a block's hash, its parent's hash, and its timestamp. The values are always
+// read from the node (never the cache) because the scraper uses them to detect chain reorganizations.
+func (conn *Connection) GetBlockHashes(bn base.Blknum) (hash base.Hash, parentHash base.Hash, ts base.Timestamp, err error) {
+	rawBlock, err := conn.getBlockRaw(bn, false)
+	if err != nil {
+		return
+	}
+
+	t, err := hexutil.DecodeUint64(rawBlock.Timestamp)
+	if err != nil {
+		return
+	}
+
+	ts = base.Timestamp(t)
+	if ts == 0 {
+		// The node reports no timestamp for block zero, so it's simulated as GetBlockTimestamp does
+		ts = conn.GetBlockTimestamp(bn)
+	}
+
+	return base.HexToHash(rawBlock.Hash), base.HexToHash(rawBlock.ParentHash), ts, nil
+}
+
 // GetBlockHashByHash returns a block's hash if it's a valid block
 func (conn *Connection) GetBlockHashByHash(hash string) (base.Hash, error) {
 	if ec, err := conn.getClient(); err != nil {
@@ -222,21 +244,32 @@ func loadBlock[Tx string | types.SimpleTransaction](conn *Connection, bn uint64,
 		return
 	}
 
+	// Pre-London blocks carry no base fee and pre-Cancun blocks no blob fields, so these parse to zero
+	var baseFeePerGas base.Wei
+	if len(rawBlock.BaseFeePerGas) > 0 {
+		baseFeePerGas.SetString(rawBlock.BaseFeePerGas, 0)
+	}
+	blobGasUsed := utils.MustParseUint(rawBlock.BlobGasUsed)
+	excessBlobGas := utils.MustParseUint(rawBlock.ExcessBlobGas)
+
 	uncles := make([]base.Hash, 0, len(rawBlock.Uncles))
 	for _, uncle := range rawBlock.Uncles {
 		uncles = append(uncles, base.HexToHash(uncle))
 	}
 
 	block = types.SimpleBlock[Tx]{
-		BlockNumber: blockNumber,
-		Timestamp:   base.Timestamp(ts), // note that we turn Ethereum's timestamps into types.Timestamp upon read.
-		Hash:        base.HexToHash(rawBlock.Hash),
-		ParentHash:  base.HexToHash(rawBlock.ParentHash),
-		GasLimit:    gasLimit,
-		GasUsed:     gasUsed,
-		Miner:       base.HexToAddress(rawBlock.Miner),
-		Difficulty:  difficulty,
-		Uncles:      uncles,
+		BlockNumber:   blockNumber,
+		Timestamp:     base.Timestamp(ts), // note that we turn Ethereum's timestamps into types.Timestamp upon read.
+		Hash:          base.HexToHash(rawBlock.Hash),
+		ParentHash:    base.HexToHash(rawBlock.ParentHash),
+		GasLimit:      gasLimit,
+		GasUsed:       gasUsed,
+		BaseFeePerGas: baseFeePerGas,
+		BlobGasUsed:   blobGasUsed,
+		ExcessBlobGas: excessBlobGas,
+		Miner:         base.HexToAddress(rawBlock.Miner),
+		Difficulty:    difficulty,
+		Uncles:        uncles,
 	}
 	return
 }
@@ -259,15 +292,3 @@ func (conn *Connection) getBlockRaw(bn uint64, withTxs bool) (*types.RawBlock, e
 		return block, nil
 	}
 }
-
-func (conn *Connection) getBlockReward(bn uint64) *big.Int {
-	if bn == 0 {
-		return big.NewInt(0)
-	} else if bn < byzantiumBlock {
-		return big.NewInt(5000000000000000000)
-	} else if bn < constantinopleBlock {
-		return big.NewInt(3000000000000000000)
-	} else {
-		return big.NewInt(2000000000000000000)
-	}
-}
@@ -0,0 +1,125 @@
+package rpc
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// GetBlockFee returns a summary of the fees paid in the given block. The summary is built from the
+// block's transactions and their receipts and, once the block is final, is cached.
+func (conn *Connection) GetBlockFee(bn base.Blknum) (types.SimpleBlockFee, error) {
+	if conn.StoreReadable() {
+		fee := types.SimpleBlockFee{BlockNumber: bn}
+		if err := conn.Store.Read(&fee, nil); err == nil {
+			return fee, nil
+		}
+	}
+
+	block, err := conn.GetBlockBodyByNumber(bn)
+	if err != nil {
+		return types.SimpleBlockFee{}, err
+	}
+
+	baseFee := block.BaseFeePerGas.Uint64()
+	fee := types.SimpleBlockFee{
+		BlockNumber:     block.BlockNumber,
+		Timestamp:       block.Timestamp,
+		TransactionsCnt: uint64(len(block.Transactions)),
+		GasUsed:         block.GasUsed,
+		GasLimit:        block.GasLimit,
+		BaseFeePerGas:   baseFee,
+		BlobGasUsed:     block.BlobGasUsed,
+	}
+	fee.Burned.Mul(&block.BaseFeePerGas, new(big.Int).SetUint64(block.GasUsed))
+
+	rewards := make([]gasReward, 0, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		if tx.Receipt == nil {
+			continue
+		}
+		price := tx.Receipt.EffectiveGasPrice
+		if price == 0 {
+			price = tx.GasPrice
+		}
+		reward := gasReward{gasUsed: tx.Receipt.GasUsed}
+		if price > baseFee {
+			reward.reward = price - baseFee
+		}
+		rewards = append(rewards, reward)
+	}
+	percentiles := rewardPercentiles(rewards, 10, 50, 90)
+	fee.PriorityFeeP10, fee.PriorityFeeP50, fee.PriorityFeeP90 = percentiles[0], percentiles[1], percentiles[2]
+
+	if conn.StoreWritable() && conn.EnabledMap["blocks"] && base.IsFinal(conn.LatestBlockTimestamp, fee.Timestamp) {
+		_ = conn.Store.Write(&fee, nil)
+	}
+
+	return fee, nil
+}
+
+// GetBlockFeePeriod returns the cached fees of the named period, if they've been cached
+func (conn *Connection) GetBlockFeePeriod(period string) (types.SimpleBlockFee, bool) {
+	if conn.StoreReadable() {
+		fee := types.BlockFeePeriod{SimpleBlockFee: types.SimpleBlockFee{Period: period}}
+		if err := conn.Store.Read(&fee, nil); err == nil {
+			return fee.SimpleBlockFee, true
+		}
+	}
+	return types.SimpleBlockFee{}, false
+}
+
+// CachesBlockFeePeriods returns true if the fees of whole periods are cached
+func (conn *Connection) CachesBlockFeePeriods() bool {
+	return conn.StoreWritable() && conn.EnabledMap["blocks"]
+}
+
+// WriteBlockFeePeriod caches the fees of a period. The caller must make sure that they cover
+// every block of the period and that the period is final.
+func (conn *Connection) WriteBlockFeePeriod(fee *types.SimpleBlockFee) {
+	if conn.CachesBlockFeePeriods() {
+		_ = conn.Store.Write(&types.BlockFeePeriod{SimpleBlockFee: *fee}, nil)
+	}
+}
+
+// gasReward carries the priority fee per gas paid by a transaction and the gas it used
+type gasReward struct {
+	reward  base.Gas
+	gasUsed base.Gas
+}
+
+// rewardPercentiles returns the priority fee per gas at each of the given percentiles of the gas
+// used by the transactions, the same way eth_feeHistory weighs its rewards.
+func rewardPercentiles(rewards []gasReward, percentiles ...float64) []base.Gas {
+	ret := make([]base.Gas, len(percentiles))
+	if len(rewards) == 0 {
+		return ret
+	}
+
+	sorted := make([]gasReward, len(rewards))
+	copy(sorted, rewards)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].reward < sorted[j].reward
+	})
+
+	total := base.Gas(0)
+	for _, r := range sorted {
+		total += r.gasUsed
+	}
+
+	for i, p := range percentiles {
+		threshold := float64(total) * p / 100
+		cumulative := base.Gas(0)
+		ret[i] = sorted[len(sorted)-1].reward
+		for _, r := range sorted {
+			cumulative += r.gasUsed
+			if float64(cumulative) >= threshold {
+				ret[i] = r.reward
+				break
+			}
+		}
+	}
+	return ret
+}
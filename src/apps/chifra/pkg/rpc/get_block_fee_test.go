@@ -0,0 +1,31 @@
+package rpc
+
+import (
+	"testing"
+)
+
+func TestRewardPercentiles(t *testing.T) {
+	rewards := []gasReward{
+		{reward: 30, gasUsed: 21000},
+		{reward: 1, gasUsed: 100000},
+		{reward: 2, gasUsed: 21000},
+		{reward: 100, gasUsed: 50000},
+	}
+
+	got := rewardPercentiles(rewards, 10, 50, 90)
+	want := []uint64{1, 1, 100}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Error("wrong percentile", i, got[i], want[i])
+		}
+	}
+
+	got = rewardPercentiles(rewards, 70)
+	if got[0] != 30 {
+		t.Error("wrong 70th percentile", got[0])
+	}
+
+	if got := rewardPercentiles(nil, 50); got[0] != 0 {
+		t.Error("expected zero for an empty block", got[0])
+	}
+}
@@ -17,10 +17,7 @@ import (
 
 // GetTracesByBlockNumber returns a slice of traces in the given block
 func (conn *Connection) GetTracesByBlockNumber(bn uint64) ([]types.SimpleTrace, error) {
-	method := "trace_block"
-	params := query.Params{fmt.Sprintf("0x%x", bn)}
-
-	if rawTraces, err := query.QuerySlice[types.RawTrace](conn.Chain, method, params); err != nil {
+	if rawTraces, err := conn.getRawTracesByBlockNumber(bn); err != nil {
 		return []types.SimpleTrace{}, err
 	} else {
 		curApp := types.SimpleAppearance{BlockNumber: uint32(^uint32(0))}
@@ -115,11 +112,8 @@ func (conn *Connection) GetTracesByTransactionHash(txHash string, transaction *t
 		}
 	}
 
-	method := "trace_transaction"
-	params := query.Params{txHash}
-
 	var ret []types.SimpleTrace
-	if rawTraces, err := query.QuerySlice[types.RawTrace](conn.Chain, method, params); err != nil {
+	if rawTraces, err := conn.getRawTracesByTransactionHash(txHash, transaction); err != nil {
 		return ret, fmt.Errorf("transaction at %s returned an error: %w", txHash, ethereum.NotFound)
 
 	} else {
@@ -211,6 +205,10 @@ func (conn *Connection) GetTracesByFilter(filter string) ([]types.SimpleTrace, e
 	var f types.SimpleTraceFilter
 	ff, _ := f.ParseBangString(conn.Chain, filter)
 
+	if conn.getTraceStyle() == gethTracing {
+		return []types.SimpleTrace{}, fmt.Errorf("trace filter %s requires trace_filter, which the node does not support", filter)
+	}
+
 	method := "trace_filter"
 	params := query.Params{ff}
 
@@ -0,0 +1,270 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
+)
+
+// traceStyle is the kind of tracing a chain's node supports
+type traceStyle int
+
+const (
+	// noTracing means the node answers neither style of trace request
+	noTracing traceStyle = iota
+	// parityTracing is trace_block, trace_transaction and trace_filter (Erigon, Nethermind, Reth, ...)
+	parityTracing
+	// gethTracing is debug_traceBlockByNumber and debug_traceTransaction with geth's callTracer
+	gethTracing
+)
+
+var traceStylesMutex sync.Mutex
+var traceStyles = map[string]traceStyle{}
+
+var callTracer = map[string]any{"tracer": "callTracer"}
+
+// getTraceStyle returns the kind of tracing the chain's node supports. Parity-style tracing is
+// preferred where both are available. The answer is remembered unless the node supports neither.
+func (conn *Connection) getTraceStyle() traceStyle {
+	traceStylesMutex.Lock()
+	defer traceStylesMutex.Unlock()
+
+	if style, ok := traceStyles[conn.Chain]; ok {
+		return style
+	}
+
+	style := noTracing
+	if _, err := query.Query[json.RawMessage](conn.Chain, "trace_block", query.Params{"0x1"}); err == nil {
+		style = parityTracing
+	} else if _, err := query.Query[json.RawMessage](conn.Chain, "debug_traceBlockByNumber", query.Params{"0x1", callTracer}); err == nil {
+		style = gethTracing
+	}
+
+	if style != noTracing {
+		traceStyles[conn.Chain] = style
+	}
+	return style
+}
+
+// getRawTracesByBlockNumber returns the block's traces, converting them from geth's call frames if
+// the node does not support Parity-style tracing
+func (conn *Connection) getRawTracesByBlockNumber(bn uint64) ([]types.RawTrace, error) {
+	if conn.getTraceStyle() != gethTracing {
+		return query.QuerySlice[types.RawTrace](conn.Chain, "trace_block", query.Params{fmt.Sprintf("0x%x", bn)})
+	}
+
+	block, err := conn.GetBlockHeaderByNumber(bn)
+	if err != nil {
+		return nil, err
+	}
+
+	params := query.Params{fmt.Sprintf("0x%x", bn), callTracer}
+	results, err := query.Query[[]gethTxTrace](conn.Chain, "debug_traceBlockByNumber", params)
+	if err != nil {
+		return nil, err
+	}
+
+	rawTraces := make([]types.RawTrace, 0, len(*results))
+	for i, result := range *results {
+		if len(result.Error) > 0 {
+			return nil, fmt.Errorf("tracing transaction %d in block %d failed: %s", i, bn, result.Error)
+		}
+		txHash := result.TxHash
+		if len(txHash) == 0 && i < len(block.Transactions) {
+			txHash = block.Transactions[i] // older clients do not report the hash
+		}
+		parent := types.RawTrace{
+			BlockHash:        block.Hash.Hex(),
+			BlockNumber:      bn,
+			TransactionHash:  txHash,
+			TransactionIndex: uint64(i),
+		}
+		rawTraces = appendCallFrame(rawTraces, &parent, &result.Result, []uint64{})
+	}
+
+	return conn.appendRewardTraces(rawTraces, &block)
+}
+
+// getRawTracesByTransactionHash returns the transaction's traces, converting them from geth's call
+// frames if the node does not support Parity-style tracing
+func (conn *Connection) getRawTracesByTransactionHash(txHash string, transaction *types.SimpleTransaction) ([]types.RawTrace, error) {
+	if conn.getTraceStyle() != gethTracing {
+		return query.QuerySlice[types.RawTrace](conn.Chain, "trace_transaction", query.Params{txHash})
+	}
+
+	var parent types.RawTrace
+	if transaction != nil {
+		parent = types.RawTrace{
+			BlockHash:        transaction.BlockHash.Hex(),
+			BlockNumber:      transaction.BlockNumber,
+			TransactionHash:  txHash,
+			TransactionIndex: transaction.TransactionIndex,
+		}
+	} else if rawTx, err := conn.getTransactionRaw(notAHash, base.HexToHash(txHash), notAnInt, notAnInt); err != nil {
+		return nil, err
+	} else {
+		parent = types.RawTrace{
+			BlockHash:        rawTx.BlockHash,
+			BlockNumber:      utils.MustParseUint(rawTx.BlockNumber),
+			TransactionHash:  txHash,
+			TransactionIndex: utils.MustParseUint(rawTx.TransactionIndex),
+		}
+	}
+
+	frame, err := query.Query[gethCallFrame](conn.Chain, "debug_traceTransaction", query.Params{txHash, callTracer})
+	if err != nil {
+		return nil, err
+	}
+
+	return appendCallFrame([]types.RawTrace{}, &parent, frame, []uint64{}), nil
+}
+
+// gethTxTrace is one transaction's result from debug_traceBlockByNumber
+type gethTxTrace struct {
+	TxHash string        `json:"txHash"`
+	Result gethCallFrame `json:"result"`
+	Error  string        `json:"error"`
+}
+
+// gethCallFrame is a call (or contract creation or self-destruct) reported by geth's callTracer
+type gethCallFrame struct {
+	Type    string          `json:"type"`
+	From    string          `json:"from"`
+	To      string          `json:"to"`
+	Value   string          `json:"value"`
+	Gas     string          `json:"gas"`
+	GasUsed string          `json:"gasUsed"`
+	Input   string          `json:"input"`
+	Output  string          `json:"output"`
+	Error   string          `json:"error"`
+	Calls   []gethCallFrame `json:"calls"`
+}
+
+// gethErrors maps geth's error messages to the ones Parity-style nodes report for the same failures
+var gethErrors = map[string]string{
+	"execution reverted": "Reverted",
+	"out of gas":         "Out of gas",
+}
+
+// appendCallFrame converts the frame and (depth first, as Parity-style nodes order them) the calls
+// it makes to traces. The frame's trace address is its path of call indexes from the transaction's
+// top-level call, which has an empty trace address.
+func appendCallFrame(rawTraces []types.RawTrace, parent *types.RawTrace, frame *gethCallFrame, traceAddress []uint64) []types.RawTrace {
+	value := frame.Value
+	if len(value) == 0 {
+		value = "0x0"
+	}
+
+	rawTrace := *parent
+	rawTrace.TraceAddress = traceAddress
+	rawTrace.Subtraces = uint64(len(frame.Calls))
+	if len(frame.Error) > 0 {
+		rawTrace.Error = frame.Error
+		if parityError, ok := gethErrors[frame.Error]; ok {
+			rawTrace.Error = parityError
+		}
+	}
+
+	switch callType := strings.ToLower(frame.Type); callType {
+	case "create", "create2":
+		rawTrace.TraceType = "create"
+		rawTrace.Action = types.RawTraceAction{
+			From:  frame.From,
+			Gas:   frame.Gas,
+			Init:  frame.Input,
+			Value: value,
+		}
+		if len(rawTrace.Error) == 0 {
+			rawTrace.Result = &types.RawTraceResult{
+				Address: frame.To,
+				Code:    frame.Output,
+				GasUsed: frame.GasUsed,
+			}
+		}
+	case "selfdestruct":
+		rawTrace.TraceType = "suicide"
+		rawTrace.Action = types.RawTraceAction{
+			Address:       frame.From,
+			Balance:       value,
+			RefundAddress: frame.To,
+		}
+	default:
+		rawTrace.TraceType = "call"
+		rawTrace.Action = types.RawTraceAction{
+			CallType: callType,
+			From:     frame.From,
+			Gas:      frame.Gas,
+			Input:    frame.Input,
+			To:       frame.To,
+			Value:    value,
+		}
+		if len(rawTrace.Error) == 0 {
+			rawTrace.Result = &types.RawTraceResult{
+				GasUsed: frame.GasUsed,
+				Output:  frame.Output,
+			}
+		}
+	}
+	rawTraces = append(rawTraces, rawTrace)
+
+	for i := range frame.Calls {
+		childAddress := make([]uint64, len(traceAddress), len(traceAddress)+1)
+		copy(childAddress, traceAddress)
+		rawTraces = appendCallFrame(rawTraces, parent, &frame.Calls[i], append(childAddress, uint64(i)))
+	}
+	return rawTraces
+}
+
+// appendRewardTraces adds the block and uncle reward traces Parity-style nodes report at the end of
+// each proof-of-work block, which geth's tracers do not
+func (conn *Connection) appendRewardTraces(rawTraces []types.RawTrace, block *types.SimpleBlock[string]) ([]types.RawTrace, error) {
+	schedule, err := config.GetRewardSchedule(conn.Chain)
+	if err != nil {
+		return nil, err
+	}
+
+	bn := block.BlockNumber
+	blockReward := schedule.BlockReward(bn)
+	if blockReward.Sign() == 0 {
+		return rawTraces, nil
+	}
+
+	uncles, err := conn.GetUncleBodiesByNumber(bn)
+	if err != nil {
+		return nil, err
+	}
+
+	minerReward := blockReward.Add(blockReward, schedule.NephewReward(bn, len(uncles)))
+
+	reward := func(author string, rewardType string, value *big.Int) types.RawTrace {
+		return types.RawTrace{
+			Action: types.RawTraceAction{
+				Author:     author,
+				RewardType: rewardType,
+				Value:      fmt.Sprintf("0x%x", value),
+			},
+			BlockHash:    block.Hash.Hex(),
+			BlockNumber:  bn,
+			TraceAddress: []uint64{},
+			TraceType:    "reward",
+		}
+	}
+
+	rawTraces = append(rawTraces, reward(block.Miner.Hex(), "block", minerReward))
+	for _, uncle := range uncles {
+		rawTraces = append(rawTraces, reward(uncle.Miner.Hex(), "uncle", schedule.UncleReward(bn, uncle.BlockNumber)))
+	}
+	return rawTraces, nil
+}
@@ -0,0 +1,83 @@
+package rpc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func TestAppendCallFrame(t *testing.T) {
+	// A transaction that calls a contract which delegates to a library, creates a contract
+	// (which reverts), makes a static call, and finally self-destructs
+	result := `{
+		"type": "CALL", "from": "0xa1", "to": "0xb1", "value": "0xde0b6b3a7640000", "gas": "0x5208", "gasUsed": "0x5000", "input": "0x12345678", "output": "0x",
+		"calls": [
+			{
+				"type": "DELEGATECALL", "from": "0xb1", "to": "0xc1", "gas": "0x100", "gasUsed": "0x80", "input": "0xabcdef01", "output": "0x01",
+				"calls": [
+					{"type": "CREATE2", "from": "0xb1", "to": "0xd1", "value": "0x0", "gas": "0x50", "gasUsed": "0x50", "input": "0x6080", "error": "execution reverted"}
+				]
+			},
+			{"type": "STATICCALL", "from": "0xb1", "to": "0xe1", "gas": "0x20", "gasUsed": "0x10", "input": "0x", "output": "0x02"},
+			{"type": "SELFDESTRUCT", "from": "0xb1", "to": "0xa1", "value": "0x5", "gas": "0x0", "gasUsed": "0x0", "input": "0x"}
+		]
+	}`
+
+	var frame gethCallFrame
+	if err := json.Unmarshal([]byte(result), &frame); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := types.RawTrace{
+		BlockHash:        "0x0123",
+		BlockNumber:      17000000,
+		TransactionHash:  "0x4567",
+		TransactionIndex: 3,
+	}
+	rawTraces := appendCallFrame([]types.RawTrace{}, &parent, &frame, []uint64{})
+
+	expected := []struct {
+		traceType    string
+		callType     string
+		traceAddress []uint64
+		subtraces    uint64
+		err          string
+		hasResult    bool
+	}{
+		{"call", "call", []uint64{}, 3, "", true},
+		{"call", "delegatecall", []uint64{0}, 1, "", true},
+		{"create", "", []uint64{0, 0}, 0, "Reverted", false},
+		{"call", "staticcall", []uint64{1}, 0, "", true},
+		{"suicide", "", []uint64{2}, 0, "", false},
+	}
+	if len(rawTraces) != len(expected) {
+		t.Fatal("wrong number of traces", len(rawTraces))
+	}
+	for i, rawTrace := range rawTraces {
+		e := expected[i]
+		if rawTrace.TraceType != e.traceType || rawTrace.Action.CallType != e.callType || rawTrace.Subtraces != e.subtraces || rawTrace.Error != e.err {
+			t.Error("wrong trace", i, rawTrace.TraceType, rawTrace.Action.CallType, rawTrace.Subtraces, rawTrace.Error)
+		}
+		if !reflect.DeepEqual(rawTrace.TraceAddress, e.traceAddress) {
+			t.Error("wrong trace address", i, rawTrace.TraceAddress)
+		}
+		if (rawTrace.Result != nil) != e.hasResult {
+			t.Error("wrong result", i, rawTrace.Result)
+		}
+		if rawTrace.BlockNumber != parent.BlockNumber || rawTrace.TransactionIndex != parent.TransactionIndex || rawTrace.TransactionHash != parent.TransactionHash {
+			t.Error("wrong transaction", i)
+		}
+	}
+
+	if rawTraces[0].Action.Value != "0xde0b6b3a7640000" || rawTraces[1].Action.Value != "0x0" {
+		t.Error("wrong values", rawTraces[0].Action.Value, rawTraces[1].Action.Value)
+	}
+	if create := rawTraces[2]; create.Action.Init != "0x6080" || create.Action.From != "0xb1" {
+		t.Error("wrong create action", create.Action)
+	}
+	if suicide := rawTraces[4]; suicide.Action.Address != "0xb1" || suicide.Action.RefundAddress != "0xa1" || suicide.Action.Balance != "0x5" {
+		t.Error("wrong suicide action", suicide.Action)
+	}
+}
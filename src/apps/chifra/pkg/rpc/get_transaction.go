@@ -7,6 +7,7 @@ import (
 	"math/big"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/prefunds"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
@@ -50,7 +51,11 @@ func (conn *Connection) GetTransactionByAppearance(appearance *types.RawAppearan
 	bn := uint64(appearance.BlockNumber)
 	txid := uint64(appearance.TransactionIndex)
 
-	if conn.StoreReadable() {
+	// A block's withdrawals are paid to many addresses, but the pseudo-transaction carries only those
+	// paid to the appearance's address, so it is not cached (the block's withdrawals are)
+	cacheable := txid != types.Withdrawal
+
+	if cacheable && conn.StoreReadable() {
 		tx = &types.SimpleTransaction{
 			BlockNumber:      bn,
 			TransactionIndex: txid,
@@ -82,12 +87,16 @@ func (conn *Connection) GetTransactionByAppearance(appearance *types.RawAppearan
 		if tx, err = conn.GetTransactionRewardByTypeAndApp(UNCLE_REWARD, appearance); err != nil {
 			return nil, err
 		}
+	} else if txid == 99995 {
+		if tx, err = conn.GetTransactionWithdrawalByApp(appearance); err != nil {
+			return nil, err
+		}
 	}
 
 	blockTs := conn.GetBlockTimestamp(bn)
 	if tx != nil {
 		tx.Timestamp = blockTs
-		if conn.StoreWritable() && conn.EnabledMap["transactions"] && base.IsFinal(conn.LatestBlockTimestamp, blockTs) {
+		if cacheable && conn.StoreWritable() && conn.EnabledMap["transactions"] && base.IsFinal(conn.LatestBlockTimestamp, blockTs) {
 			_ = conn.Store.Write(tx, nil)
 		}
 		return tx, nil
@@ -249,13 +258,6 @@ const (
 //     return 0;
 // }
 
-const (
-	byzantiumBlock      = uint64(4370000)
-	constantinopleBlock = uint64(7280000)
-)
-
-// TODO: This is not cross-chain correct
-
 func (conn *Connection) GetTransactionRewardByTypeAndApp(rt RewardType, appearance *types.RawAppearance) (*types.SimpleTransaction, error) {
 	if block, err := conn.GetBlockBodyByNumber(uint64(appearance.BlockNumber)); err != nil {
 		return nil, err
@@ -263,6 +265,11 @@ func (conn *Connection) GetTransactionRewardByTypeAndApp(rt RewardType, appearan
 		if uncles, err := conn.GetUncleBodiesByNumber(uint64(appearance.BlockNumber)); err != nil {
 			return nil, err
 		} else {
+			schedule, err := config.GetRewardSchedule(conn.Chain)
+			if err != nil {
+				return nil, err
+			}
+
 			var blockReward = big.NewInt(0)
 			var nephewReward = big.NewInt(0)
 			var feeReward = big.NewInt(0)
@@ -270,16 +277,12 @@ func (conn *Connection) GetTransactionRewardByTypeAndApp(rt RewardType, appearan
 
 			sender := base.HexToAddress(appearance.Address)
 			bn := uint64(appearance.BlockNumber)
-			blockReward = conn.getBlockReward(bn)
+			blockReward = schedule.BlockReward(bn)
 			switch rt {
 			case BLOCK_REWARD:
 				if block.Miner.Hex() == appearance.Address {
 					sender = base.BlockRewardSender
-					nUncles := len(uncles)
-					if nUncles > 0 {
-						nephewReward = new(big.Int).Mul(blockReward, big.NewInt(int64(nUncles)))
-						nephewReward.Div(nephewReward, big.NewInt(32))
-					}
+					nephewReward = schedule.NephewReward(bn, len(uncles))
 					for _, tx := range block.Transactions {
 						gp := big.NewInt(int64(tx.GasPrice))
 						gu := big.NewInt(int64(tx.Receipt.GasUsed))
@@ -292,11 +295,8 @@ func (conn *Connection) GetTransactionRewardByTypeAndApp(rt RewardType, appearan
 				for _, uncle := range uncles {
 					if uncle.Miner.Hex() == appearance.Address {
 						sender = base.UncleRewardSender
-						if bn < uncle.BlockNumber+6 {
-							diff := (uncle.BlockNumber + 8 - bn) // positive since +6 < bn
-							uncleReward = new(big.Int).Mul(blockReward, big.NewInt(int64(diff)))
-							uncleReward.Div(uncleReward, big.NewInt(8))
-						}
+						// A miner may have mined more than one of the block's uncles
+						uncleReward.Add(uncleReward, schedule.UncleReward(bn, uncle.BlockNumber))
 					}
 				}
 				if block.Miner.Hex() == appearance.Address {
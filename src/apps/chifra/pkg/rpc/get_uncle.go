@@ -19,7 +19,7 @@ func (conn *Connection) GetUncleBodiesByNumber(bn uint64) ([]types.SimpleBlock[t
 	if count, err := conn.GetUnclesCountInBlock(bn); err != nil {
 		return nil, err
 	} else if count > 0 {
-		ret := make([]types.SimpleBlock[types.SimpleTransaction], count)
+		ret := make([]types.SimpleBlock[types.SimpleTransaction], 0, count)
 		for i := uint64(0); i < count; i++ {
 			method := "eth_getUncleByBlockNumberAndIndex"
 			params := query.Params{
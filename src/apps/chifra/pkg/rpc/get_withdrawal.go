@@ -0,0 +1,91 @@
+package rpc
+
+import (
+	"math/big"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
+)
+
+// GetWithdrawalsByNumber returns the beacon chain withdrawals processed in the given block. Blocks
+// produced before the Shanghai hard fork carry no withdrawals.
+func (conn *Connection) GetWithdrawalsByNumber(bn base.Blknum) ([]types.SimpleWithdrawal, error) {
+	if conn.StoreReadable() {
+		withdrawalGroup := &types.SimpleWithdrawalGroup{
+			BlockNumber:      bn,
+			TransactionIndex: utils.NOPOS,
+		}
+		if err := conn.Store.Read(withdrawalGroup, nil); err == nil {
+			return withdrawalGroup.Withdrawals, nil
+		}
+	}
+
+	rawBlock, err := conn.getBlockRaw(bn, false)
+	if err != nil {
+		return []types.SimpleWithdrawal{}, err
+	}
+
+	withdrawals := make([]types.SimpleWithdrawal, 0, len(rawBlock.Withdrawals))
+	for _, raw := range rawBlock.Withdrawals {
+		raw := raw
+		withdrawal := types.SimpleWithdrawal{
+			Address:        base.HexToAddress(raw.Address),
+			Index:          utils.MustParseUint(raw.Index),
+			ValidatorIndex: utils.MustParseUint(raw.ValidatorIndex),
+		}
+		withdrawal.Amount.SetString(raw.Amount, 0)
+		withdrawal.SetRaw(&raw)
+		withdrawals = append(withdrawals, withdrawal)
+	}
+
+	ts := base.Timestamp(utils.MustParseInt(rawBlock.Timestamp))
+	if conn.StoreWritable() && conn.EnabledMap["blocks"] && base.IsFinal(conn.LatestBlockTimestamp, ts) {
+		withdrawalGroup := &types.SimpleWithdrawalGroup{
+			Withdrawals:      withdrawals,
+			BlockNumber:      bn,
+			TransactionIndex: utils.NOPOS,
+		}
+		if err = conn.Store.Write(withdrawalGroup, nil); err != nil {
+			logger.Warn("Failed to write withdrawals to cache", err)
+		}
+	}
+
+	return withdrawals, nil
+}
+
+// GetTransactionWithdrawalByApp returns a pseudo-transaction carrying the sum of all withdrawals
+// paid to the appearance's address in the appearance's block.
+func (conn *Connection) GetTransactionWithdrawalByApp(appearance *types.RawAppearance) (*types.SimpleTransaction, error) {
+	bn := uint64(appearance.BlockNumber)
+	if block, err := conn.GetBlockHeaderByNumber(bn); err != nil {
+		return nil, err
+	} else if withdrawals, err := conn.GetWithdrawalsByNumber(bn); err != nil {
+		return nil, err
+	} else {
+		return newWithdrawalTransaction(&block, base.HexToAddress(appearance.Address), withdrawals), nil
+	}
+}
+
+// newWithdrawalTransaction sums the withdrawals paid to the recipient in the block. Withdrawal
+// amounts are denominated in gwei, so the transaction's value is converted to wei.
+func newWithdrawalTransaction(block *types.SimpleBlock[string], recipient base.Address, withdrawals []types.SimpleWithdrawal) *types.SimpleTransaction {
+	total := new(big.Int)
+	for _, withdrawal := range withdrawals {
+		if withdrawal.Address == recipient {
+			total.Add(total, &withdrawal.Amount)
+		}
+	}
+	total.Mul(total, big.NewInt(1000000000))
+
+	return &types.SimpleTransaction{
+		BlockNumber:      block.BlockNumber,
+		BlockHash:        block.Hash,
+		TransactionIndex: types.Withdrawal,
+		Timestamp:        block.Timestamp,
+		From:             base.WithdrawalSender,
+		To:               recipient,
+		Value:            *total,
+	}
+}
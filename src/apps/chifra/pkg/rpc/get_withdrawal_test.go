@@ -0,0 +1,45 @@
+package rpc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func TestNewWithdrawalTransaction(t *testing.T) {
+	staker := base.HexToAddress("0xf97e180c050e5ab072211ad2c213eb5aee4df134")
+	other := base.HexToAddress("0x8306300ffd616049fee7e8a8a6b3b0e56ab08ea4")
+	withdrawal := func(addr base.Address, gwei int64) types.SimpleWithdrawal {
+		w := types.SimpleWithdrawal{Address: addr}
+		w.Amount.SetInt64(gwei)
+		return w
+	}
+
+	block := &types.SimpleBlock[string]{
+		BlockNumber: 17034870,
+		Hash:        base.HexToHash("0xe22c56f211f03baadcc91e4eb9a24344e6848c5e4de4a27a8e2c0dc8e2bb7d14"),
+		Timestamp:   1681338479,
+	}
+	withdrawals := []types.SimpleWithdrawal{
+		withdrawal(staker, 4000000),
+		withdrawal(other, 32000000000),
+		withdrawal(staker, 250),
+	}
+
+	tx := newWithdrawalTransaction(block, staker, withdrawals)
+	if tx.TransactionIndex != types.Withdrawal {
+		t.Error("wrong transaction index", tx.TransactionIndex)
+	}
+	if tx.From != base.WithdrawalSender || tx.To != staker {
+		t.Error("wrong sender or recipient", tx.From, tx.To)
+	}
+	if tx.BlockNumber != block.BlockNumber || tx.Timestamp != block.Timestamp {
+		t.Error("wrong block", tx.BlockNumber, tx.Timestamp)
+	}
+	want, _ := new(big.Int).SetString("4000250000000000", 10)
+	if tx.Value.Cmp(want) != 0 {
+		t.Error("wrong value", tx.Value.String(), want.String())
+	}
+}
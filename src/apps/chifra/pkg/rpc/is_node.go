@@ -143,13 +143,12 @@ func (conn *Connection) IsNodeArchive() bool {
 //
 // // #endif
 
-// TODO: BOGUS This needs to be implemented in a cross-chain, cross-client manner
-
-// IsNodeTracing returns true if the node is an archive node. Note currently always returns true.
+// IsNodeTracing returns true if the node supports either Parity-style (trace_*) or geth-style
+// (debug_trace* with the callTracer) tracing
 func (conn *Connection) IsNodeTracing(testMode bool) bool {
 	// TODO: We can test this with a unit test
 	if testMode && conn.Chain == "non-tracing" {
 		return false
 	}
-	return true
+	return conn.getTraceStyle() != noTracing
 }
@@ -0,0 +1,478 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
+)
+
+var (
+	// healthInterval is how often the providers of a chain with more than one provider are checked
+	healthInterval = 30 * time.Second
+	// healthTimeout is the longest we wait for a provider to answer a health check
+	healthTimeout = 5 * time.Second
+	// maxLag is how many blocks a provider may fall behind the others before it's considered unhealthy
+	maxLag = uint64(16)
+)
+
+// capability is what a request requires of the provider that serves it
+type capability int
+
+const (
+	needsNothing capability = iota
+	needsArchive
+	needsTracing
+	needsDebugTracing
+)
+
+// archiveMethods maps methods that read historical state to the position of their block parameter
+var archiveMethods = map[string]int{
+	"eth_getBalance":          1,
+	"eth_getCode":             1,
+	"eth_getTransactionCount": 1,
+	"eth_call":                1,
+	"eth_getStorageAt":        2,
+	"eth_getProof":            2,
+}
+
+// requires returns the capability a request needs. Trace requests need a node that supports
+// that style of tracing (Parity's trace_* or geth's debug_trace* methods). State queries at an
+// explicit block need an archive node (recent blocks would do, but we can't tell).
+func requires(method string, params Params) capability {
+	if strings.HasPrefix(method, "trace_") {
+		return needsTracing
+	}
+	if strings.HasPrefix(method, "debug_trace") {
+		return needsDebugTracing
+	}
+	if pos, ok := archiveMethods[method]; ok && pos < len(params) {
+		switch block := params[pos].(type) {
+		case string:
+			switch block {
+			case "latest", "pending", "safe", "finalized":
+				return needsNothing
+			}
+		case nil:
+			return needsNothing
+		}
+		return needsArchive
+	}
+	return needsNothing
+}
+
+// provider is one of a chain's RPC providers along with what the health checks know about it
+type provider struct {
+	t            *transport
+	healthy      bool
+	probed       bool
+	tracing      bool
+	debugTracing bool
+	archive      bool
+	head         uint64
+	latency      time.Duration
+}
+
+// pool sends a chain's requests to one of its providers. So that a command sees consistent chain
+// state, its requests stick to a single (pinned) provider: with round-robin routing, commands are
+// pinned to the providers in turn; with latency routing, to the fastest. A request that can't be
+// delivered fails over to the next healthy provider, to which later requests then stick. Trace and
+// archive requests only go to providers that support them. A chain with a single provider is
+// served by that provider directly.
+type pool struct {
+	chain     string
+	routing   string
+	providers []*provider
+	mutex     sync.Mutex
+	pinned    int
+	served    bool
+	checked   time.Time
+	checking  bool
+	// probed is closed once the first health check is done
+	probed chan struct{}
+}
+
+var poolsMutex sync.Mutex
+var pools = map[string]*pool{}
+
+// getPool returns the (shared) pool for the chain's configured providers
+func getPool(chain string) *pool {
+	poolsMutex.Lock()
+	defer poolsMutex.Unlock()
+
+	if p, ok := pools[chain]; ok {
+		return p
+	}
+
+	p := newPool(chain, config.GetRpcProviders(chain), config.GetRpcRouting(chain))
+	pools[chain] = p
+	return p
+}
+
+func newPool(chain string, providers []string, routing string) *pool {
+	p := &pool{
+		chain:     chain,
+		routing:   routing,
+		providers: make([]*provider, 0, len(providers)),
+		probed:    make(chan struct{}),
+	}
+	for _, url := range providers {
+		var t *transport
+		if len(providers) > 1 {
+			// The pool's transports are its own, because another provider is a better bet
+			// than waiting out a long backoff
+			t = newChainTransport(chain, url)
+			t.retries = 1
+		} else {
+			t = getTransport(chain, url)
+		}
+		p.providers = append(p.providers, &provider{t: t, healthy: true})
+	}
+	if len(p.providers) > 0 {
+		// Concurrent commands (separate processes) are spread across the providers
+		p.pinned = os.Getpid() % len(p.providers)
+	}
+	return p
+}
+
+// call sends a single request to the best provider for it
+func (p *pool) call(payload rpcPayload) ([]byte, error) {
+	if len(p.providers) == 1 {
+		return p.providers[0].t.call(payload)
+	}
+	return p.send(requires(payload.Method, payload.Params), func(t *transport) ([]byte, error) {
+		return t.call(payload)
+	})
+}
+
+// post sends an already marshalled request (a single request or a batch) to the best provider for it
+func (p *pool) post(marshalled []byte) ([]byte, error) {
+	if len(p.providers) == 1 {
+		return p.providers[0].t.post(marshalled)
+	}
+	return p.send(requiresMarshalled(marshalled), func(t *transport) ([]byte, error) {
+		return t.post(marshalled)
+	})
+}
+
+// send tries each candidate provider in turn until one of them delivers the request. If the
+// pinned provider failed to deliver an ordinary request, the one that did is pinned instead.
+func (p *pool) send(need capability, fn func(t *transport) ([]byte, error)) ([]byte, error) {
+	var lastErr error
+	for i, pr := range p.candidates(need) {
+		start := time.Now()
+		data, err := fn(pr.t)
+		if err == nil {
+			p.succeeded(pr, time.Since(start), need == needsNothing && i > 0)
+			return data, nil
+		}
+		lastErr = err
+		p.failed(pr, err)
+	}
+	return nil, lastErr
+}
+
+// candidates returns the providers able to serve a request in the order they should be tried,
+// starting with the pinned provider. Healthy providers come first. If no provider is known to have
+// the capability, all are tried. Only requests that need a capability wait for the first health
+// check (which finds out what the providers support); the others don't wait.
+func (p *pool) candidates(need capability) []*provider {
+	p.checkHealth()
+	if need != needsNothing {
+		<-p.probed
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.served = true
+	ordered := make([]*provider, len(p.providers))
+	for i := range p.providers {
+		ordered[i] = p.providers[(p.pinned+i)%len(p.providers)]
+	}
+
+	capable := func(pr *provider) bool {
+		switch need {
+		case needsTracing:
+			return pr.tracing
+		case needsDebugTracing:
+			return pr.debugTracing
+		case needsArchive:
+			return pr.archive
+		}
+		return true
+	}
+
+	anyCapable := false
+	for _, pr := range ordered {
+		anyCapable = anyCapable || capable(pr)
+	}
+
+	healthy := make([]*provider, 0, len(ordered))
+	unhealthy := make([]*provider, 0, len(ordered))
+	for _, pr := range ordered {
+		if anyCapable && !capable(pr) {
+			continue
+		}
+		if pr.healthy {
+			healthy = append(healthy, pr)
+		} else {
+			unhealthy = append(unhealthy, pr)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (p *pool) succeeded(pr *provider, elapsed time.Duration, pin bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	pr.healthy = true
+	pr.latency = (pr.latency*4 + elapsed) / 5
+	if pin {
+		p.pin(pr)
+	}
+}
+
+// pin makes ordinary requests go to the given provider first. The mutex must be held.
+func (p *pool) pin(pr *provider) {
+	for i := range p.providers {
+		if p.providers[i] == pr {
+			p.pinned = i
+		}
+	}
+}
+
+// repin pins a healthy provider if the pinned one is not: the fastest, with latency routing, or
+// otherwise the next. With latency routing, a pool that has not served any request yet is pinned
+// to the fastest provider even if the pinned one is healthy. The mutex must be held.
+func (p *pool) repin() {
+	if len(p.providers) == 0 || p.providers[p.pinned].healthy && (p.routing != "latency" || p.served) {
+		return
+	}
+
+	ordered := make([]*provider, len(p.providers))
+	for i := range p.providers {
+		ordered[i] = p.providers[(p.pinned+i)%len(p.providers)]
+	}
+	if p.routing == "latency" {
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return ordered[i].latency < ordered[j].latency
+		})
+	}
+	for _, pr := range ordered {
+		if pr.healthy {
+			p.pin(pr)
+			return
+		}
+	}
+}
+
+func (p *pool) failed(pr *provider, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if pr.healthy {
+		logger.Warn(fmt.Sprintf("RPC provider %s failed (%s), failing over", pr.t.provider, err))
+	}
+	pr.healthy = false
+}
+
+// checkHealth checks every provider in the background the first time it's called and again
+// every healthInterval
+func (p *pool) checkHealth() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.checking && time.Since(p.checked) > healthInterval {
+		p.checking = true
+		go p.checkAll()
+	}
+}
+
+// checkAll checks each provider's latest block and response time (and, the first time, whether it
+// supports tracing and historical state). Providers that don't answer or that have fallen more
+// than maxLag blocks behind the others are marked unhealthy.
+func (p *pool) checkAll() {
+	var wg sync.WaitGroup
+	for _, pr := range p.providers {
+		wg.Add(1)
+		go func(pr *provider) {
+			defer wg.Done()
+			p.check(pr)
+		}(pr)
+	}
+	wg.Wait()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	best := uint64(0)
+	for _, pr := range p.providers {
+		if pr.head > best {
+			best = pr.head
+		}
+	}
+	for _, pr := range p.providers {
+		healthy := pr.head > 0 && pr.head+maxLag >= best
+		if pr.healthy && !healthy {
+			logger.Warn(fmt.Sprintf("RPC provider %s is unhealthy (latest block %d of %d)", pr.t.provider, pr.head, best))
+		}
+		pr.healthy = healthy
+	}
+	p.repin()
+
+	if p.checked.IsZero() {
+		close(p.probed)
+	}
+	p.checked = time.Now()
+	p.checking = false
+}
+
+// check queries a single provider. Its head is left at zero if it does not answer.
+func (p *pool) check(pr *provider) {
+	var head uint64
+	start := time.Now()
+	result, err := probe(pr.t, "eth_blockNumber", Params{})
+	elapsed := time.Since(start)
+	if err == nil {
+		var hex string
+		if json.Unmarshal(result, &hex) == nil {
+			head = utils.MustParseUint(hex)
+		}
+	}
+
+	probed, tracing, debugTracing, archive := false, false, false, false
+	p.mutex.Lock()
+	probed, tracing, debugTracing, archive = pr.probed, pr.tracing, pr.debugTracing, pr.archive
+	p.mutex.Unlock()
+
+	if head > 0 && !probed {
+		_, err = probe(pr.t, "trace_block", Params{"0x1"})
+		tracing = err == nil
+		_, err = probe(pr.t, "debug_traceBlockByNumber", Params{"0x1", map[string]any{"tracer": "callTracer"}})
+		debugTracing = err == nil
+		_, err = probe(pr.t, "eth_getBalance", Params{base.ZeroAddr.Hex(), "0x1"})
+		archive = err == nil
+		probed = true
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	pr.head = head
+	pr.probed, pr.tracing, pr.debugTracing, pr.archive = probed, tracing, debugTracing, archive
+	if head > 0 {
+		if pr.latency == 0 {
+			pr.latency = elapsed
+		} else {
+			pr.latency = (pr.latency*4 + elapsed) / 5
+		}
+	}
+}
+
+// probe sends a single request to the provider without retrying it and returns its result
+func probe(t *transport, method string, params Params) (json.RawMessage, error) {
+	marshalled, err := json.Marshal(rpcPayload{
+		Jsonrpc: "2.0",
+		Method:  method,
+		Params:  params,
+		ID:      int(atomic.AddUint32(&rpcCounter, 1)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+	defer cancel()
+	data, _, err := t.postOnce(ctx, marshalled)
+	if err != nil {
+		return nil, err
+	}
+
+	var response rpcResponse[json.RawMessage]
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("%d: %s", response.Error.Code, response.Error.Message)
+	}
+	if len(response.Result) == 0 {
+		return nil, errors.New("empty result")
+	}
+	return response.Result, nil
+}
+
+// requiresMarshalled returns the capability needed by a marshalled request or batch of requests
+func requiresMarshalled(marshalled []byte) capability {
+	var payloads []rpcPayload
+	if err := json.Unmarshal(marshalled, &payloads); err != nil {
+		var payload rpcPayload
+		if json.Unmarshal(marshalled, &payload) != nil {
+			return needsNothing
+		}
+		payloads = []rpcPayload{payload}
+	}
+
+	need := needsNothing
+	for _, payload := range payloads {
+		if r := requires(payload.Method, payload.Params); r > need {
+			need = r
+		}
+	}
+	return need
+}
+
+// HttpClient returns an http.Client that sends requests to the chain's providers (with retries,
+// rate limiting, and failover) regardless of the URL they are addressed to. It's used to route
+// requests made by go-ethereum's clients through the chain's providers.
+func HttpClient(chain string) *http.Client {
+	return &http.Client{Transport: &poolRoundTripper{pool: getPool(chain)}}
+}
+
+// HasManyProviders returns true if the chain has more than one RPC provider
+func HasManyProviders(chain string) bool {
+	return len(getPool(chain).providers) > 1
+}
+
+type poolRoundTripper struct {
+	pool *pool
+}
+
+func (rt *poolRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil {
+		return nil, errors.New("empty request")
+	}
+	marshalled, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := rt.pool.post(marshalled)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,154 @@
+package query
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeNode answers health checks and counts the other requests it serves
+func fakeNode(head string, tracing, archive bool, served *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload rpcPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		notSupported := `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"not supported"}}`
+		switch payload.Method {
+		case "eth_blockNumber":
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"` + head + `"}`))
+			return
+		case "trace_block", "debug_traceBlockByNumber":
+			if !tracing {
+				_, _ = w.Write([]byte(notSupported))
+			} else {
+				_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":[]}`))
+			}
+			return
+		case "eth_getBalance":
+			if payload.Params[1] == "0x1" {
+				if !archive {
+					_, _ = w.Write([]byte(notSupported))
+				} else {
+					_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x0"}`))
+				}
+				return
+			}
+		}
+		atomic.AddInt32(served, 1)
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+}
+
+func TestPoolRouting(t *testing.T) {
+	var plainServed, tracingServed int32
+	plain := fakeNode("0x100", false, false, &plainServed)
+	defer plain.Close()
+	tracing := fakeNode("0x100", true, true, &tracingServed)
+	defer tracing.Close()
+
+	p := newPool("test-routing", []string{plain.URL, tracing.URL}, "round-robin")
+	p.pinned = 0
+
+	var response rpcResponse[string]
+	for i := 0; i < 4; i++ {
+		if err := fromRpc(p, &Payload{Method: "eth_chainId"}, &response); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if plainServed != 4 || tracingServed != 0 {
+		t.Error("expected requests to stick to the pinned provider, got", plainServed, tracingServed)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := fromRpc(p, &Payload{Method: "trace_transaction", Params: Params{"0x12"}}, &response); err != nil {
+			t.Fatal(err)
+		}
+		if err := fromRpc(p, &Payload{Method: "eth_getBalance", Params: Params{"0x12", "0x5"}}, &response); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if plainServed != 4 || tracingServed != 4 {
+		t.Error("expected trace and archive requests to go to the tracing node, got", plainServed, tracingServed)
+	}
+}
+
+func TestPoolFailover(t *testing.T) {
+	defer func(base time.Duration) { retryBase = base }(retryBase)
+	retryBase = time.Millisecond
+
+	var goodServed, otherServed, laggingServed int32
+	good := fakeNode("0x100", false, false, &goodServed)
+	defer good.Close()
+	other := fakeNode("0x100", false, false, &otherServed)
+	defer other.Close()
+	lagging := fakeNode("0x10", false, false, &laggingServed)
+	defer lagging.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	downUrl := down.URL
+	down.Close()
+
+	// Before the first health check is done, requests fail over to the next provider, which
+	// is then pinned
+	p := newPool("test-failover", []string{downUrl, good.URL, other.URL}, "round-robin")
+	p.pinned = 0
+	p.checking = true // keeps the health check from running
+
+	var response rpcResponse[string]
+	for i := 0; i < 3; i++ {
+		if err := fromRpc(p, &Payload{Method: "eth_chainId"}, &response); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if goodServed != 3 || otherServed != 0 {
+		t.Error("expected requests to stick to the provider that delivered them, got", goodServed, otherServed)
+	}
+
+	// Once checked, unhealthy providers are not used
+	p = newPool("test-failover", []string{downUrl, lagging.URL, good.URL}, "round-robin")
+	p.pinned = 1
+	p.checkAll()
+	for i := 0; i < 3; i++ {
+		if err := fromRpc(p, &Payload{Method: "eth_chainId"}, &response); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if goodServed != 6 || laggingServed != 0 {
+		t.Error("expected only the healthy provider to be used, got", goodServed, laggingServed)
+	}
+}
+
+func TestPoolTransports(t *testing.T) {
+	shared := getTransport("test-transports", "http://localhost:1")
+	p := newPool("test-transports", []string{"http://localhost:1", "http://localhost:2"}, "round-robin")
+	if p.providers[0].t == shared || p.providers[0].t.retries != 1 {
+		t.Error("expected the pool to have its own transports")
+	}
+	if shared.retries != maxRetries {
+		t.Error("expected the shared transport to keep its retries, got", shared.retries)
+	}
+}
+
+func TestRequires(t *testing.T) {
+	tests := []struct {
+		method string
+		params Params
+		want   capability
+	}{
+		{"eth_blockNumber", Params{}, needsNothing},
+		{"trace_filter", Params{}, needsTracing},
+		{"debug_traceTransaction", Params{"0x12"}, needsDebugTracing},
+		{"eth_getBalance", Params{"0x12", "latest"}, needsNothing},
+		{"eth_getBalance", Params{"0x12", "0x10"}, needsArchive},
+		{"eth_getStorageAt", Params{"0x12", "0x0", "0x10"}, needsArchive},
+		{"eth_call", Params{map[string]any{}}, needsNothing},
+	}
+	for _, test := range tests {
+		if got := requires(test.method, test.params); got != test.want {
+			t.Error("wrong capability for", test.method, test.params, got)
+		}
+	}
+}
@@ -1,15 +1,11 @@
 package query
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"runtime"
 	"sync/atomic"
-
-	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 )
 
 // Params are used during calls to the RPC.
@@ -50,8 +46,7 @@ func Query[T any](chain string, method string, params Params) (*T, error) {
 		Params: params,
 	}
 
-	provider, _ := config.GetRpcProvider(chain)
-	err := FromRpc(provider, &payload, &response)
+	err := fromRpc(getPool(chain), &payload, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -64,15 +59,18 @@ func Query[T any](chain string, method string, params Params) (*T, error) {
 
 var rpcCounter uint32
 
-// fromRpc Returns all traces for a given block.
+// FromRpc sends a single request to the given provider (without rate limiting or batching).
 func FromRpc(rpcProvider string, payload *Payload, ret interface{}) error {
-	type rpcPayload struct {
-		Jsonrpc string `json:"jsonrpc"`
-		Method  string `json:"method"`
-		Params  `json:"params"`
-		ID      int `json:"id"`
-	}
+	return fromRpc(getTransport("", rpcProvider), payload, ret)
+}
 
+// sender delivers requests to an RPC provider (a transport) or to one of a chain's providers (a pool)
+type sender interface {
+	call(payload rpcPayload) ([]byte, error)
+	post(marshalled []byte) ([]byte, error)
+}
+
+func fromRpc(t sender, payload *Payload, ret interface{}) error {
 	payloadToSend := rpcPayload{
 		Jsonrpc: "2.0",
 		Method:  payload.Method,
@@ -80,28 +78,12 @@ func FromRpc(rpcProvider string, payload *Payload, ret interface{}) error {
 		ID:      int(atomic.AddUint32(&rpcCounter, 1)),
 	}
 
-	plBytes, err := json.Marshal(payloadToSend)
-	if err != nil {
-		return err
-	}
-
-	return sendRpcRequest(rpcProvider, plBytes, ret)
-}
-
-func sendRpcRequest(rpcProvider string, marshalled []byte, result any) error {
-	body := bytes.NewReader(marshalled)
-	resp, err := http.Post(rpcProvider, "application/json", body)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	theBytes, err := io.ReadAll(resp.Body)
+	theBytes, err := t.call(payloadToSend)
 	if err != nil {
 		return err
 	}
 
-	return json.Unmarshal(theBytes, result)
+	return json.Unmarshal(theBytes, ret)
 }
 
 // QuerySlice returns a slice of results for given method and params.
@@ -113,8 +95,7 @@ func QuerySlice[T any](chain string, method string, params Params) ([]T, error)
 		Params: params,
 	}
 
-	provider, _ := config.GetRpcProvider(chain)
-	err := FromRpc(provider, &payload, &response)
+	err := fromRpc(getPool(chain), &payload, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -141,8 +122,7 @@ func QueryBatch[T any](chain string, batchPayload []BatchPayload) (map[string]*T
 		payloads = append(payloads, *config.Payload)
 	}
 
-	provider, _ := config.GetRpcProvider(chain)
-	err := fromRpcBatch(provider, payloads, &response)
+	err := fromRpcBatch(getPool(chain), payloads, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -153,14 +133,7 @@ func QueryBatch[T any](chain string, batchPayload []BatchPayload) (map[string]*T
 	return results, err
 }
 
-func fromRpcBatch(rpcProvider string, payloads []Payload, ret interface{}) error {
-	type rpcPayload struct {
-		Jsonrpc string `json:"jsonrpc"`
-		Method  string `json:"method"`
-		Params  `json:"params"`
-		ID      int `json:"id"`
-	}
-
+func fromRpcBatch(t sender, payloads []Payload, ret interface{}) error {
 	payloadToSend := make([]rpcPayload, 0, len(payloads))
 
 	for _, payload := range payloads {
@@ -177,5 +150,10 @@ func fromRpcBatch(rpcProvider string, payloads []Payload, ret interface{}) error
 		return err
 	}
 
-	return sendRpcRequest(rpcProvider, plBytes, ret)
+	theBytes, err := t.post(plBytes)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(theBytes, ret)
 }
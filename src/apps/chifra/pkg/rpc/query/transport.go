@@ -0,0 +1,356 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"golang.org/x/time/rate"
+)
+
+var (
+	// maxRetries is the number of times a failed request is retried before giving up
+	maxRetries = 6
+	// retryBase is the delay before the first retry. It doubles with each retry up to retryMax.
+	retryBase = 250 * time.Millisecond
+	retryMax  = 15 * time.Second
+	// requestTimeout is the longest we wait for the provider to respond to a single request
+	requestTimeout = 90 * time.Second
+	// batchWindow is how long the first of a group of concurrent calls waits for others to join it
+	batchWindow = 2 * time.Millisecond
+)
+
+// rpcPayload is the wire format of a single JSON-RPC request
+type rpcPayload struct {
+	Jsonrpc string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  `json:"params"`
+	ID      int `json:"id"`
+}
+
+// transport sends requests to a single RPC provider. It retries requests that fail with a
+// timeout, a dropped connection, a 429, or a 5xx (with exponential backoff), limits the number
+// of requests per second if configured to, and (if configured to) combines concurrent calls
+// into batch requests.
+type transport struct {
+	provider  string
+	client    *http.Client
+	limiter   *rate.Limiter
+	retries   int
+	maxBatch  int
+	queue     chan *pendingCall
+	startOnce sync.Once
+}
+
+// pendingCall is a single call waiting to be sent as part of a batch
+type pendingCall struct {
+	payload rpcPayload
+	done    chan callResult
+}
+
+type callResult struct {
+	data []byte
+	err  error
+}
+
+var transportsMutex sync.Mutex
+var transports = map[string]*transport{}
+
+// getTransport returns the (shared) transport for the provider configured with the chain's
+// settings. If chain is empty, the provider is neither rate limited nor batched.
+func getTransport(chain, provider string) *transport {
+	transportsMutex.Lock()
+	defer transportsMutex.Unlock()
+
+	key := chain + "|" + provider
+	if t, ok := transports[key]; ok {
+		return t
+	}
+
+	t := newChainTransport(chain, provider)
+	transports[key] = t
+	return t
+}
+
+// newChainTransport returns a new transport for the provider configured with the chain's settings.
+// If chain is empty, the provider is neither rate limited nor batched.
+func newChainTransport(chain, provider string) *transport {
+	rps, batch := 0.0, 1
+	if len(chain) > 0 {
+		rps, batch = config.GetRpcRate(chain), config.GetRpcBatch(chain)
+	}
+	return newTransport(provider, rps, batch)
+}
+
+func newTransport(provider string, rps float64, batch int) *transport {
+	t := &transport{
+		provider: provider,
+		client:   &http.Client{Timeout: requestTimeout},
+		retries:  maxRetries,
+		maxBatch: batch,
+	}
+	if rps > 0 {
+		burst := int(rps)
+		if burst < 1 {
+			burst = 1
+		}
+		t.limiter = rate.NewLimiter(rate.Limit(rps), burst)
+	}
+	if t.maxBatch > 1 {
+		t.queue = make(chan *pendingCall, t.maxBatch*4)
+	}
+	return t
+}
+
+// call sends a single request, possibly as part of a batch, and returns the raw response
+func (t *transport) call(payload rpcPayload) ([]byte, error) {
+	if t.maxBatch <= 1 {
+		marshalled, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		return t.post(marshalled)
+	}
+
+	t.startOnce.Do(func() {
+		go t.dispatch()
+	})
+
+	pending := &pendingCall{payload: payload, done: make(chan callResult, 1)}
+	t.queue <- pending
+	result := <-pending.done
+	return result.data, result.err
+}
+
+// dispatch collects concurrent calls into batches and sends them
+func (t *transport) dispatch() {
+	for first := range t.queue {
+		calls := []*pendingCall{first}
+		timer := time.NewTimer(batchWindow)
+	collecting:
+		for len(calls) < t.maxBatch {
+			select {
+			case pending := <-t.queue:
+				calls = append(calls, pending)
+			case <-timer.C:
+				break collecting
+			}
+		}
+		timer.Stop()
+		go t.sendBatch(calls)
+	}
+}
+
+// sendBatch sends the calls as a single request and hands each call its own response
+func (t *transport) sendBatch(calls []*pendingCall) {
+	if len(calls) == 1 {
+		marshalled, err := json.Marshal(calls[0].payload)
+		if err == nil {
+			var data []byte
+			data, err = t.post(marshalled)
+			calls[0].done <- callResult{data: data, err: err}
+			return
+		}
+		calls[0].done <- callResult{err: err}
+		return
+	}
+
+	payloads := make([]rpcPayload, 0, len(calls))
+	for _, pending := range calls {
+		payloads = append(payloads, pending.payload)
+	}
+
+	responses, err := func() (map[int][]byte, error) {
+		marshalled, err := json.Marshal(payloads)
+		if err != nil {
+			return nil, err
+		}
+		data, err := t.post(marshalled)
+		if err != nil {
+			return nil, err
+		}
+		return splitBatch(data)
+	}()
+
+	for _, pending := range calls {
+		if err != nil {
+			pending.done <- callResult{err: err}
+		} else if data, ok := responses[pending.payload.ID]; !ok {
+			pending.done <- callResult{err: fmt.Errorf("no response for request %d in batch", pending.payload.ID)}
+		} else {
+			pending.done <- callResult{data: data}
+		}
+	}
+}
+
+// splitBatch returns the individual responses of a batch response keyed by request id
+func splitBatch(data []byte) (map[int][]byte, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		// Some providers answer a batch with a single error object
+		var single rpcResponse[json.RawMessage]
+		if json.Unmarshal(data, &single) == nil && single.Error != nil {
+			return nil, fmt.Errorf("%d: %s", single.Error.Code, single.Error.Message)
+		}
+		return nil, err
+	}
+
+	ret := make(map[int][]byte, len(items))
+	for _, item := range items {
+		var id struct {
+			ID int `json:"id"`
+		}
+		if err := json.Unmarshal(item, &id); err != nil {
+			return nil, err
+		}
+		ret[id.ID] = item
+	}
+	return ret, nil
+}
+
+// post sends the request to the provider, retrying it if it fails for a reason that is likely
+// to be temporary
+func (t *transport) post(marshalled []byte) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		if t.limiter != nil {
+			_ = t.limiter.Wait(context.Background())
+		}
+
+		data, retryAfter, err := t.postOnce(context.Background(), marshalled)
+		if err == nil {
+			return data, nil
+		}
+		if !isRetryable(err) || attempt >= t.retries {
+			return nil, err
+		}
+
+		delay := backoff(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		logger.Warn(fmt.Sprintf("RPC request to %s failed (%s), retrying in %s", t.provider, err, delay))
+		time.Sleep(delay)
+	}
+}
+
+// retryableError marks a response that should be retried
+type retryableError struct {
+	msg string
+}
+
+func (e *retryableError) Error() string {
+	return e.msg
+}
+
+// postOnce sends the request a single time. It returns the provider's Retry-After, if any.
+func (t *transport) postOnce(ctx context.Context, marshalled []byte) ([]byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.provider, bytes.NewReader(marshalled))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, &retryableError{msg: err.Error()}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After")), &retryableError{msg: resp.Status}
+	}
+
+	// Some providers send JSON-RPC errors (reverts, for example) with a 5xx status. Those are
+	// answers, not failures, so we only retry if the body is not a JSON-RPC response.
+	if resp.StatusCode >= 500 && !isRpcResponse(data) {
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After")), &retryableError{msg: resp.Status}
+	}
+
+	if isRateLimited(data) {
+		return nil, 0, &retryableError{msg: "rate limited"}
+	}
+
+	return data, 0, nil
+}
+
+// isRetryable returns true if the error is likely to be temporary
+func isRetryable(err error) bool {
+	var re *retryableError
+	if errors.As(err, &re) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isRpcResponse returns true if the body is a JSON-RPC response (or a batch of them)
+func isRpcResponse(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || !json.Valid(trimmed) {
+		return false
+	}
+	if trimmed[0] == '[' {
+		return true
+	}
+	var response struct {
+		Jsonrpc string `json:"jsonrpc"`
+	}
+	return json.Unmarshal(trimmed, &response) == nil && len(response.Jsonrpc) > 0
+}
+
+// isRateLimited returns true if the body is a JSON-RPC error reporting that the request was
+// rate limited. (Some providers report rate limits this way with a 200 status.)
+func isRateLimited(data []byte) bool {
+	var response rpcResponse[json.RawMessage]
+	if json.Unmarshal(data, &response) != nil || response.Error == nil {
+		return false
+	}
+	if response.Error.Code == http.StatusTooManyRequests {
+		return true
+	}
+	return response.Error.Code == -32005 && strings.Contains(strings.ToLower(response.Error.Message), "rate")
+}
+
+// backoff returns the delay before the given retry with up to 25% jitter
+func backoff(attempt int) time.Duration {
+	delay := retryBase << attempt
+	if delay > retryMax || delay <= 0 {
+		delay = retryMax
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay)/4+1))
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds (dates are ignored)
+func parseRetryAfter(value string) time.Duration {
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		delay := time.Duration(secs) * time.Second
+		if delay > retryMax {
+			return retryMax
+		}
+		return delay
+	}
+	return 0
+}
@@ -0,0 +1,113 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTransportRetry(t *testing.T) {
+	defer func(base time.Duration) { retryBase = base }(retryBase)
+	retryBase = time.Millisecond
+
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&hits, 1) {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("<html>bad gateway</html>"))
+		case 3:
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32005,"message":"request rate exceeded"}}`))
+		default:
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+		}
+	}))
+	defer server.Close()
+
+	var response rpcResponse[string]
+	if err := FromRpc(server.URL, &Payload{Method: "eth_blockNumber"}, &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Result != "0x10" || hits != 4 {
+		t.Error("expected success after three retries, got", response.Result, hits)
+	}
+}
+
+func TestTransportNoRetry(t *testing.T) {
+	defer func(base time.Duration) { retryBase = base }(retryBase)
+	retryBase = time.Millisecond
+
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted"}}`))
+	}))
+	defer server.Close()
+
+	var response rpcResponse[string]
+	if err := FromRpc(server.URL, &Payload{Method: "eth_call"}, &response); err != nil {
+		t.Fatal(err)
+	}
+	if hits != 1 || response.Error == nil || response.Error.Message != "execution reverted" {
+		t.Error("expected the JSON-RPC error to be returned without retrying", hits, response.Error)
+	}
+}
+
+func TestTransportBatching(t *testing.T) {
+	defer func(window time.Duration) { batchWindow = window }(batchWindow)
+	batchWindow = 50 * time.Millisecond
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		var payloads []rpcPayload
+		if err := json.NewDecoder(r.Body).Decode(&payloads); err != nil {
+			t.Error("expected a batch request", err)
+			return
+		}
+		responses := make([]map[string]any, 0, len(payloads))
+		for i := len(payloads) - 1; i >= 0; i-- { // out of order on purpose
+			responses = append(responses, map[string]any{
+				"jsonrpc": "2.0",
+				"id":      payloads[i].ID,
+				"result":  payloads[i].Params[0],
+			})
+		}
+		_ = json.NewEncoder(w).Encode(responses)
+	}))
+	defer server.Close()
+
+	tr := newTransport(server.URL, 0, 5)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var response rpcResponse[string]
+			if err := fromRpc(tr, &Payload{Method: "echo", Params: Params{fmt.Sprintf("value-%d", i)}}, &response); err != nil {
+				t.Error(err)
+			} else if response.Result != fmt.Sprintf("value-%d", i) {
+				t.Error("wrong result for call", i, response.Result)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if requests != 1 {
+		t.Error("expected the concurrent calls to be sent as one batch, got", requests, "requests")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if parseRetryAfter("2") != 2*time.Second || parseRetryAfter("") != 0 || parseRetryAfter("3600") != retryMax {
+		t.Error("wrong Retry-After parsing")
+	}
+}
@@ -0,0 +1,21 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
+)
+
+// GetStorageAt returns the contents of a storage slot of an address at the given block
+func (conn *Connection) GetStorageAt(address base.Address, slot base.Hash, blockNumber base.Blknum) (base.Hash, error) {
+	value, err := query.Query[string](conn.Chain, "eth_getStorageAt", query.Params{
+		address,
+		slot.Hex(),
+		fmt.Sprintf("0x%x", blockNumber),
+	})
+	if err != nil {
+		return base.Hash{}, err
+	}
+	return base.HexToHash(*value), nil
+}
@@ -2,6 +2,7 @@ package rpc
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
 	"strconv"
 
@@ -25,6 +26,8 @@ const tokenStateDecimals tokenStateSelector = "0x313ce567"
 const tokenStateSymbol tokenStateSelector = "0x95d89b41"
 const tokenStateName tokenStateSelector = "0x06fdde03"
 const tokenStateBalanceOf tokenStateSelector = "0x70a08231"
+const tokenStateOwnerOf tokenStateSelector = "0x6352211e"     // ownerOf(uint256) -- ERC-721
+const tokenStateBalanceOfId tokenStateSelector = "0x00fdd58e" // balanceOf(address,uint256) -- ERC-1155
 
 // GetTokenState returns token state for given block. `blockNumber` can be "latest" or "" for the latest block or
 // decimal number or hex number with 0x prefix.
@@ -170,3 +173,51 @@ func (conn *Connection) GetTokenBalanceAt(token, holder base.Address, blockNumbe
 
 	return base.HexToWei(*output["balance"]), nil
 }
+
+// GetNftBalanceAt returns the balance of a single ERC-721 or ERC-1155 token ID held by the holder at the
+// given block. For ERC-721 tokens the balance is one if ownerOf the token ID is the holder and zero
+// otherwise (including if the token had not been minted or has been burned). `blockNumber` can be
+// "latest" or "" for the latest block or decimal number or hex number with 0x prefix.
+func (conn *Connection) GetNftBalanceAt(token, holder base.Address, tokenId *big.Int, tokenType types.TokenType, blockNumber string) (balance *big.Int, err error) {
+	paddedId := fmt.Sprintf("%064x", tokenId)
+
+	data := tokenStateOwnerOf + paddedId
+	if tokenType.IsErc1155() {
+		data = tokenStateBalanceOfId + holder.Pad32() + paddedId
+	}
+
+	output, err := query.QueryBatch[string](
+		conn.Chain,
+		[]query.BatchPayload{{
+			Key: "balance",
+			Payload: &query.Payload{
+				Method: "eth_call",
+				Params: query.Params{
+					map[string]any{
+						"to":   token.Hex(),
+						"data": data,
+					},
+					blockNumber,
+				},
+			},
+		}},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// A reverted call (for example, ownerOf a token that does not exist) leaves the result empty
+	if output["balance"] == nil || len(*output["balance"]) <= 2 {
+		return big.NewInt(0), nil
+	}
+
+	if tokenType.IsErc1155() {
+		return base.HexToWei(*output["balance"]), nil
+	}
+
+	if base.HexToAddress(*output["balance"]) == holder {
+		return big.NewInt(1), nil
+	}
+	return big.NewInt(0), nil
+}
@@ -0,0 +1,2 @@
+// Package storage reads contract storage slots and decodes them using a Solidity storage layout
+package storage
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Layout is a Solidity storage layout as produced by `solc --storage-layout` (or found in the
+// `storageLayout` field of solc's standard JSON output and most build artifacts)
+type Layout struct {
+	Storage []Variable          `json:"storage"`
+	Types   map[string]TypeInfo `json:"types"`
+}
+
+// Variable is a state variable (or a struct member) along with where it is stored
+type Variable struct {
+	Label  string `json:"label"`
+	Offset uint64 `json:"offset"`
+	Slot   string `json:"slot"`
+	Type   string `json:"type"`
+}
+
+// TypeInfo describes one of the types found in a storage layout. Encoding is one of `inplace`,
+// `mapping`, `dynamic_array`, or `bytes`.
+type TypeInfo struct {
+	Encoding      string     `json:"encoding"`
+	Label         string     `json:"label"`
+	NumberOfBytes string     `json:"numberOfBytes"`
+	Key           string     `json:"key,omitempty"`
+	Value         string     `json:"value,omitempty"`
+	Base          string     `json:"base,omitempty"`
+	Members       []Variable `json:"members,omitempty"`
+}
+
+// LoadLayout reads a storage layout from a JSON file
+func LoadLayout(path string) (*Layout, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseLayout(contents)
+}
+
+// ParseLayout parses a storage layout given either on its own or as the `storageLayout` field
+// of a larger object
+func ParseLayout(contents []byte) (*Layout, error) {
+	var wrapped struct {
+		Layout
+		StorageLayout *Layout `json:"storageLayout"`
+	}
+	if err := json.Unmarshal(contents, &wrapped); err != nil {
+		return nil, fmt.Errorf("invalid storage layout: %w", err)
+	}
+
+	layout := &wrapped.Layout
+	if wrapped.StorageLayout != nil {
+		layout = wrapped.StorageLayout
+	}
+	if len(layout.Storage) == 0 || len(layout.Types) == 0 {
+		return nil, fmt.Errorf("the storage layout has no variables")
+	}
+	return layout, nil
+}
+
+// variable returns the top-level state variable with the given name
+func (l *Layout) variable(name string) (Variable, bool) {
+	for _, v := range l.Storage {
+		if v.Label == name {
+			return v, true
+		}
+	}
+	return Variable{}, false
+}
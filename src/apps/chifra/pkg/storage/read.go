@@ -0,0 +1,427 @@
+package storage
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/decode"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Reader returns the contents of a storage slot
+type Reader func(slot base.Hash) (base.Hash, error)
+
+// Item is a single value read from storage. Name, Type, and Decoded are empty for raw slots.
+type Item struct {
+	Name    string
+	Type    string
+	Slot    base.Hash
+	Offset  uint64
+	Value   base.Hash
+	Decoded string
+}
+
+// location is where a (possibly packed) value of a given type starts in storage
+type location struct {
+	name   string
+	slot   *big.Int
+	offset uint64
+	typeId string
+}
+
+// maxSlot is one more than the largest storage slot
+var maxSlot = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// Read reads the storage identified by the query. A query that is a number (decimal or hex) is
+// read as a raw slot. Otherwise, the query names a variable in the layout, optionally followed by
+// mapping keys or array indices in brackets and struct members after dots (for example
+// `balances[0xf503...179b]` or `config.fee`). Structs and fixed-size arrays are expanded into
+// their members. Dynamic arrays are reported by their length.
+func Read(layout *Layout, query string, read Reader) ([]Item, error) {
+	query = strings.TrimSpace(query)
+	if slot, ok := new(big.Int).SetString(query, 0); ok {
+		if slot.Sign() < 0 || slot.Cmp(maxSlot) >= 0 {
+			return nil, fmt.Errorf("invalid storage slot %s", query)
+		}
+		value, err := read(toHash(slot))
+		if err != nil {
+			return nil, err
+		}
+		return []Item{{Slot: toHash(slot), Value: value}}, nil
+	}
+
+	if layout == nil {
+		return nil, fmt.Errorf("a storage layout is required to read %s", query)
+	}
+
+	loc, err := layout.resolve(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Packed values share slots, so we only read each slot once
+	words := map[base.Hash]base.Hash{}
+	cached := func(slot base.Hash) (base.Hash, error) {
+		if value, ok := words[slot]; ok {
+			return value, nil
+		}
+		value, err := read(slot)
+		if err == nil {
+			words[slot] = value
+		}
+		return value, err
+	}
+
+	return layout.decode(loc, cached)
+}
+
+// resolve finds the location of the variable, mapping entry, array element, or struct member
+// named by the query
+func (l *Layout) resolve(query string) (location, error) {
+	name, accessors, err := parsePath(query)
+	if err != nil {
+		return location{}, err
+	}
+
+	v, ok := l.variable(name)
+	if !ok {
+		return location{}, fmt.Errorf("variable %s not found in the storage layout", name)
+	}
+	slot, ok := new(big.Int).SetString(v.Slot, 10)
+	if !ok {
+		return location{}, fmt.Errorf("invalid slot %s for variable %s", v.Slot, name)
+	}
+
+	loc := location{name: name, slot: slot, offset: v.Offset, typeId: v.Type}
+	for _, accessor := range accessors {
+		t, ok := l.Types[loc.typeId]
+		if !ok {
+			return location{}, fmt.Errorf("type %s not found in the storage layout", loc.typeId)
+		}
+
+		if member, isMember := strings.CutPrefix(accessor, "."); isMember {
+			found := false
+			for _, m := range t.Members {
+				if m.Label == member {
+					memberSlot, ok := new(big.Int).SetString(m.Slot, 10)
+					if !ok {
+						return location{}, fmt.Errorf("invalid slot %s for member %s", m.Slot, member)
+					}
+					loc = location{
+						name:   loc.name + accessor,
+						slot:   new(big.Int).Add(loc.slot, memberSlot),
+						offset: m.Offset,
+						typeId: m.Type,
+					}
+					found = true
+					break
+				}
+			}
+			if !found {
+				return location{}, fmt.Errorf("%s has no member %s", loc.name, member)
+			}
+			continue
+		}
+
+		key := accessor[1 : len(accessor)-1]
+		switch {
+		case t.Encoding == "mapping":
+			encoded, err := encodeKey(l.Types[t.Key].Label, key)
+			if err != nil {
+				return location{}, fmt.Errorf("invalid key for %s: %w", loc.name, err)
+			}
+			loc = location{
+				name:   loc.name + accessor,
+				slot:   new(big.Int).SetBytes(crypto.Keccak256(encoded, toHash(loc.slot).Bytes())),
+				typeId: t.Value,
+			}
+
+		case t.Base != "":
+			index, err := strconv.ParseUint(key, 0, 64)
+			if err != nil {
+				return location{}, fmt.Errorf("invalid index %s for %s", key, loc.name)
+			}
+			start := loc.slot
+			if t.Encoding == "dynamic_array" {
+				start = new(big.Int).SetBytes(crypto.Keccak256(toHash(loc.slot).Bytes()))
+			} else if length := staticLength(t.Label); index >= length {
+				return location{}, fmt.Errorf("index %d is out of range for %s", index, loc.name)
+			}
+			loc = l.element(loc.name+accessor, start, t.Base, index)
+
+		default:
+			return location{}, fmt.Errorf("%s is not a mapping or an array", loc.name)
+		}
+	}
+
+	return loc, nil
+}
+
+// element returns the location of an array element. Elements smaller than a slot are packed
+// into slots, larger elements take up whole slots.
+func (l *Layout) element(name string, start *big.Int, baseType string, index uint64) location {
+	size := l.size(baseType)
+	loc := location{name: name, typeId: baseType}
+	if size == 0 {
+		size = 32
+	}
+	if size >= 32 {
+		perItem := new(big.Int).SetUint64((size + 31) / 32)
+		loc.slot = new(big.Int).Add(start, perItem.Mul(perItem, new(big.Int).SetUint64(index)))
+		return loc
+	}
+	perSlot := 32 / size
+	loc.slot = new(big.Int).Add(start, new(big.Int).SetUint64(index/perSlot))
+	loc.offset = (index % perSlot) * size
+	return loc
+}
+
+// decode reads and decodes the value at the location
+func (l *Layout) decode(loc location, read Reader) ([]Item, error) {
+	t, ok := l.Types[loc.typeId]
+	if !ok {
+		return nil, fmt.Errorf("type %s not found in the storage layout", loc.typeId)
+	}
+
+	slot := toHash(loc.slot)
+	switch t.Encoding {
+	case "mapping":
+		return nil, fmt.Errorf("the mapping %s requires a key (for example %s[key])", loc.name, loc.name)
+
+	case "dynamic_array":
+		word, err := read(slot)
+		if err != nil {
+			return nil, err
+		}
+		length := new(big.Int).SetBytes(word.Bytes())
+		return []Item{{Name: loc.name, Type: t.Label, Slot: slot, Value: word, Decoded: length.Text(10)}}, nil
+
+	case "bytes":
+		word, err := read(slot)
+		if err != nil {
+			return nil, err
+		}
+		data, err := readBytes(slot, word, read)
+		if err != nil {
+			return nil, err
+		}
+		decoded := "0x" + hex.EncodeToString(data)
+		if t.Label == "string" {
+			decoded = decode.SanitizeString(string(data))
+		}
+		return []Item{{Name: loc.name, Type: t.Label, Slot: slot, Value: word, Decoded: decoded}}, nil
+	}
+
+	if len(t.Members) > 0 {
+		items := []Item{}
+		for _, m := range t.Members {
+			memberSlot, ok := new(big.Int).SetString(m.Slot, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid slot %s for member %s", m.Slot, m.Label)
+			}
+			member, err := l.decode(location{
+				name:   loc.name + "." + m.Label,
+				slot:   new(big.Int).Add(loc.slot, memberSlot),
+				offset: m.Offset,
+				typeId: m.Type,
+			}, read)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, member...)
+		}
+		return items, nil
+	}
+
+	if t.Base != "" {
+		items := []Item{}
+		for index := uint64(0); index < staticLength(t.Label); index++ {
+			element, err := l.decode(l.element(fmt.Sprintf("%s[%d]", loc.name, index), loc.slot, t.Base, index), read)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, element...)
+		}
+		return items, nil
+	}
+
+	word, err := read(slot)
+	if err != nil {
+		return nil, err
+	}
+	size := l.size(loc.typeId)
+	if size == 0 || loc.offset+size > 32 {
+		return nil, fmt.Errorf("invalid size or offset for %s", loc.name)
+	}
+	value := word.Bytes()[32-loc.offset-size : 32-loc.offset]
+	return []Item{{
+		Name:    loc.name,
+		Type:    t.Label,
+		Slot:    slot,
+		Offset:  loc.offset,
+		Value:   word,
+		Decoded: decodeValue(t.Label, value),
+	}}, nil
+}
+
+// readBytes reads a `bytes` or `string` value. Values shorter than 32 bytes are stored in the
+// slot itself along with twice their length. Longer values store twice their length plus one
+// in the slot and their data in the slots starting at the slot's hash.
+func readBytes(slot, word base.Hash, read Reader) ([]byte, error) {
+	b := word.Bytes()
+	if b[31]&1 == 0 {
+		length := int(b[31]) / 2
+		if length > 31 {
+			return nil, fmt.Errorf("invalid short bytes at slot %s", slot.Hex())
+		}
+		return b[:length], nil
+	}
+
+	length := new(big.Int).SetBytes(b)
+	length.Rsh(length, 1)
+	if !length.IsUint64() || length.Uint64() > 1<<20 {
+		return nil, fmt.Errorf("bytes at slot %s are too long to read", slot.Hex())
+	}
+
+	n := length.Uint64()
+	data := make([]byte, 0, n+31)
+	start := new(big.Int).SetBytes(crypto.Keccak256(slot.Bytes()))
+	for i := uint64(0); uint64(len(data)) < n; i++ {
+		chunk, err := read(toHash(new(big.Int).Add(start, new(big.Int).SetUint64(i))))
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk.Bytes()...)
+	}
+	return data[:n], nil
+}
+
+// size returns the number of bytes a type takes up in storage
+func (l *Layout) size(typeId string) uint64 {
+	size, _ := strconv.ParseUint(l.Types[typeId].NumberOfBytes, 10, 64)
+	return size
+}
+
+// decodeValue decodes a value stored in place given its type's label
+func decodeValue(label string, value []byte) string {
+	switch {
+	case label == "bool":
+		return fmt.Sprint(len(value) > 0 && value[len(value)-1] != 0)
+	case label == "address" || label == "address payable" ||
+		strings.HasPrefix(label, "contract ") || strings.HasPrefix(label, "interface "):
+		addr := base.BytesToAddress(value)
+		return addr.Hex()
+	case strings.HasPrefix(label, "uint") || strings.HasPrefix(label, "enum "):
+		return new(big.Int).SetBytes(value).Text(10)
+	case strings.HasPrefix(label, "int"):
+		v := new(big.Int).SetBytes(value)
+		if len(value) > 0 && value[0]&0x80 != 0 {
+			v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(len(value)*8)))
+		}
+		return v.Text(10)
+	}
+	return "0x" + hex.EncodeToString(value)
+}
+
+// encodeKey encodes a mapping key the way Solidity does before hashing it with the slot.
+// Value types are padded to 32 bytes, strings and bytes are used as they are.
+func encodeKey(label, key string) ([]byte, error) {
+	key = strings.TrimSpace(key)
+	switch {
+	case label == "string":
+		return []byte(strings.Trim(key, "\"'")), nil
+	case label == "bytes":
+		return hex.DecodeString(strings.TrimPrefix(key, "0x"))
+	case strings.HasPrefix(label, "bytes"):
+		b, err := hex.DecodeString(strings.TrimPrefix(key, "0x"))
+		if err != nil || len(b) > 32 {
+			return nil, fmt.Errorf("invalid %s %s", label, key)
+		}
+		return append(b, make([]byte, 32-len(b))...), nil
+	case label == "address" || label == "address payable" ||
+		strings.HasPrefix(label, "contract ") || strings.HasPrefix(label, "interface "):
+		if !base.IsValidAddress(key) {
+			return nil, fmt.Errorf("invalid address %s", key)
+		}
+		addr := base.HexToAddress(key)
+		return toHash(new(big.Int).SetBytes(addr.Bytes())).Bytes(), nil
+	case label == "bool":
+		switch key {
+		case "true":
+			return toHash(big.NewInt(1)).Bytes(), nil
+		case "false":
+			return toHash(big.NewInt(0)).Bytes(), nil
+		}
+		return nil, fmt.Errorf("invalid bool %s", key)
+	}
+
+	v, ok := new(big.Int).SetString(key, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s %s", label, key)
+	}
+	if v.Sign() < 0 {
+		// two's complement
+		v.Add(v, maxSlot)
+	}
+	if v.Sign() < 0 || v.Cmp(maxSlot) >= 0 {
+		return nil, fmt.Errorf("%s is out of range", key)
+	}
+	return toHash(v).Bytes(), nil
+}
+
+// parsePath splits a query such as `a[0x12].b[3]` into the variable's name and its accessors
+// (`[0x12]`, `.b`, and `[3]`)
+func parsePath(query string) (name string, accessors []string, err error) {
+	end := strings.IndexAny(query, ".[")
+	if end < 0 {
+		end = len(query)
+	}
+	name = query[:end]
+	if len(name) == 0 {
+		return "", nil, fmt.Errorf("invalid storage query %s", query)
+	}
+
+	for rest := query[end:]; len(rest) > 0; {
+		switch rest[0] {
+		case '[':
+			closing := strings.Index(rest, "]")
+			if closing < 2 {
+				return "", nil, fmt.Errorf("invalid storage query %s", query)
+			}
+			accessors = append(accessors, rest[:closing+1])
+			rest = rest[closing+1:]
+		case '.':
+			next := strings.IndexAny(rest[1:], ".[")
+			if next < 0 {
+				next = len(rest) - 1
+			}
+			if next == 0 {
+				return "", nil, fmt.Errorf("invalid storage query %s", query)
+			}
+			accessors = append(accessors, rest[:next+1])
+			rest = rest[next+1:]
+		default:
+			return "", nil, fmt.Errorf("invalid storage query %s", query)
+		}
+	}
+	return name, accessors, nil
+}
+
+// staticLength returns the length of a fixed-size array type given its label (e.g. `uint8[3]`)
+func staticLength(label string) uint64 {
+	open := strings.LastIndex(label, "[")
+	if open < 0 || !strings.HasSuffix(label, "]") {
+		return 0
+	}
+	length, _ := strconv.ParseUint(label[open+1:len(label)-1], 10, 64)
+	return length
+}
+
+// toHash returns the slot as a hash. Slot arithmetic wraps around as it does in the EVM.
+func toHash(v *big.Int) base.Hash {
+	return base.BytesToHash(new(big.Int).Mod(v, maxSlot).FillBytes(make([]byte, 32)))
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testLayout = `{"storageLayout":{"storage":[
+	{"label":"owner","offset":0,"slot":"0","type":"t_address"},
+	{"label":"paused","offset":20,"slot":"0","type":"t_bool"},
+	{"label":"delta","offset":21,"slot":"0","type":"t_int8"},
+	{"label":"balances","offset":0,"slot":"1","type":"t_mapping(t_address,t_uint256)"},
+	{"label":"name","offset":0,"slot":"2","type":"t_string_storage"},
+	{"label":"config","offset":0,"slot":"3","type":"t_struct(Config)_storage"},
+	{"label":"list","offset":0,"slot":"5","type":"t_array(t_uint16)dyn_storage"},
+	{"label":"fixed","offset":0,"slot":"6","type":"t_array(t_uint8)3_storage"}
+],"types":{
+	"t_address":{"encoding":"inplace","label":"address","numberOfBytes":"20"},
+	"t_bool":{"encoding":"inplace","label":"bool","numberOfBytes":"1"},
+	"t_int8":{"encoding":"inplace","label":"int8","numberOfBytes":"1"},
+	"t_uint8":{"encoding":"inplace","label":"uint8","numberOfBytes":"1"},
+	"t_uint16":{"encoding":"inplace","label":"uint16","numberOfBytes":"2"},
+	"t_uint64":{"encoding":"inplace","label":"uint64","numberOfBytes":"8"},
+	"t_uint256":{"encoding":"inplace","label":"uint256","numberOfBytes":"32"},
+	"t_string_storage":{"encoding":"bytes","label":"string","numberOfBytes":"32"},
+	"t_mapping(t_address,t_uint256)":{"encoding":"mapping","key":"t_address","label":"mapping(address => uint256)","numberOfBytes":"32","value":"t_uint256"},
+	"t_struct(Config)_storage":{"encoding":"inplace","label":"struct Token.Config","numberOfBytes":"64","members":[
+		{"label":"fee","offset":0,"slot":"0","type":"t_uint64"},
+		{"label":"cap","offset":0,"slot":"1","type":"t_uint256"}
+	]},
+	"t_array(t_uint16)dyn_storage":{"base":"t_uint16","encoding":"dynamic_array","label":"uint16[]","numberOfBytes":"32"},
+	"t_array(t_uint8)3_storage":{"base":"t_uint8","encoding":"inplace","label":"uint8[3]","numberOfBytes":"32"}
+}}}`
+
+func slotAt(n int64) base.Hash {
+	return toHash(big.NewInt(n))
+}
+
+func TestRead(t *testing.T) {
+	layout, err := ParseLayout([]byte(testLayout))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	holder := base.HexToAddress("0xf503017d7baf7fbc0fff7492b751025c6a78179b")
+	balanceSlot := base.BytesToHash(crypto.Keccak256(toHash(new(big.Int).SetBytes(holder.Bytes())).Bytes(), slotAt(1).Bytes()))
+	listStart := new(big.Int).SetBytes(crypto.Keccak256(slotAt(5).Bytes()))
+	longName := strings.Repeat("TrueBlocks", 4)
+	nameStart := new(big.Int).SetBytes(crypto.Keccak256(slotAt(2).Bytes()))
+	nameNext := new(big.Int).Add(nameStart, big.NewInt(1))
+	listNext := new(big.Int).Add(listStart, big.NewInt(1))
+
+	storage := map[base.Hash]base.Hash{
+		// delta (-2), paused (true), owner
+		slotAt(0):         base.HexToHash("0x00000000000000000000fe01f503017d7baf7fbc0fff7492b751025c6a78179b"),
+		balanceSlot:       slotAt(1000),
+		slotAt(2):         slotAt(int64(len(longName)*2 + 1)),
+		toHash(nameStart): base.BytesToHash([]byte(longName[:32])),
+		toHash(nameNext):  base.HexToHash("0x" + base.Bytes2Hex([]byte(longName[32:])) + strings.Repeat("0", 48)),
+		slotAt(3):         slotAt(25),
+		slotAt(4):         slotAt(5000),
+		slotAt(5):         slotAt(17),
+		// list[18] is the third uint16 in the list's second slot
+		toHash(listNext): base.HexToHash("0x0000000000000000000000000000000000000000000000000000000700000000"),
+		slotAt(6):        base.HexToHash("0x0000000000000000000000000000000000000000000000000000000000030201"),
+	}
+	reads := 0
+	read := func(slot base.Hash) (base.Hash, error) {
+		reads++
+		return storage[slot], nil
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"owner", []string{"owner=0xf503017d7baf7fbc0fff7492b751025c6a78179b"}},
+		{"paused", []string{"paused=true"}},
+		{"delta", []string{"delta=-2"}},
+		{"balances[0xf503017d7baf7fbc0fff7492b751025c6a78179b]", []string{"balances[0xf503017d7baf7fbc0fff7492b751025c6a78179b]=1000"}},
+		{"name", []string{"name=" + longName}},
+		{"config", []string{"config.fee=25", "config.cap=5000"}},
+		{"config.cap", []string{"config.cap=5000"}},
+		{"list", []string{"list=17"}},
+		{"list[18]", []string{"list[18]=7"}},
+		{"fixed", []string{"fixed[0]=1", "fixed[1]=2", "fixed[2]=3"}},
+	}
+	for _, test := range tests {
+		items, err := Read(layout, test.query, read)
+		if err != nil {
+			t.Error(test.query, err)
+			continue
+		}
+		got := []string{}
+		for _, item := range items {
+			got = append(got, item.Name+"="+item.Decoded)
+		}
+		if strings.Join(got, " ") != strings.Join(test.want, " ") {
+			t.Error("wrong result for", test.query, got)
+		}
+	}
+
+	reads = 0
+	if items, err := Read(layout, "fixed", read); err != nil || len(items) != 3 || reads != 1 {
+		t.Error("expected packed values to be read from a single slot", reads, err)
+	}
+
+	if items, err := Read(nil, "0x3", read); err != nil || len(items) != 1 || items[0].Value != slotAt(25) {
+		t.Error("wrong raw slot", items, err)
+	}
+
+	for _, query := range []string{"balances", "missing", "fixed[3]", "owner[1]", "config.missing", "list["} {
+		if _, err := Read(layout, query, read); err == nil {
+			t.Error("expected an error for", query)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package tslib
 
 import (
-	"encoding/binary"
 	"os"
 	"path/filepath"
 
@@ -9,7 +8,15 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
 )
 
+// Append appends the records to the timestamps file. If one of the records needs a 64-bit block
+// number, the file is upgraded first (see Upgrade).
 func Append(chain string, tsArray []TimestampRecord) error {
+	if needs64(tsArray) {
+		if _, err := Upgrade(chain); err != nil {
+			return err
+		}
+	}
+
 	tsFn := config.PathToIndex(chain) + "ts.bin"
 	tmpPath := filepath.Join(config.PathToCache(chain), "tmp")
 	if backupFn, err := file.MakeBackup(tmpPath, tsFn); err == nil {
@@ -29,7 +36,7 @@ func Append(chain string, tsArray []TimestampRecord) error {
 			fp.Close()
 		}()
 
-		err = binary.Write(fp, binary.LittleEndian, tsArray)
+		err = WriteRecords(fp, recordWidth(tsFn), tsArray)
 		if err != nil {
 			return err
 		}
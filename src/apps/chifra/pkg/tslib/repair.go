@@ -1,13 +1,11 @@
 package tslib
 
 import (
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"unsafe"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
@@ -44,14 +42,17 @@ func Repair(chain string, bn uint64) error {
 				// writeMutex.Unlock()
 			}()
 
-			recordSize := int64(unsafe.Sizeof(uint32(0))) * 2
-			pos := (recordSize * int64(bn))
-			_, _ = fp.Seek(pos, io.SeekStart)
+			recordSize := recordWidth(tsFn)
+			pos := recordSize * bn
+			if recordSize == RecordWidth64 {
+				pos += recordSize // the header
+			}
+			_, _ = fp.Seek(int64(pos), io.SeekStart)
 
 			conn := rpc.TempConnection(chain)
 			block, _ := conn.GetBlockHeaderByNumber(bn)
-			record := TimestampRecord{Bn: uint32(block.BlockNumber), Ts: uint32(block.Timestamp)}
-			err = binary.Write(fp, binary.LittleEndian, &record)
+			record := TimestampRecord{Bn: uint64(block.BlockNumber), Ts: uint32(block.Timestamp)}
+			err = WriteRecords(fp, recordSize, []TimestampRecord{record})
 			if err != nil {
 				return err
 			}
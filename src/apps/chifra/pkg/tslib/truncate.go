@@ -1,8 +1,6 @@
 package tslib
 
 import (
-	"encoding/binary"
-	"io"
 	"os"
 	"path/filepath"
 
@@ -31,6 +29,7 @@ func Truncate(chain string, maxBn uint64) error {
 	truncated := perChainTimestamps[chain].memory[0:maxBn]
 
 	tsFn := filepath.Join(config.PathToIndex(chain), "ts.bin")
+	width := recordWidth(tsFn)
 	tmpPath := filepath.Join(config.PathToCache(chain), "tmp")
 	if backupFn, err := file.MakeBackup(tmpPath, tsFn); err == nil {
 		defer func() {
@@ -41,13 +40,17 @@ func Truncate(chain string, maxBn uint64) error {
 				_ = os.Remove(backupFn) // seems redundant, but may not be on some operating systems
 			}
 		}()
-		if fp, err := os.OpenFile(tsFn, os.O_WRONLY|os.O_CREATE, 0644); err == nil {
+		if fp, err := os.OpenFile(tsFn, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644); err == nil {
 			defer func() {
 				fp.Close()
 			}()
 
-			_, _ = fp.Seek(0, io.SeekStart)
-			err = binary.Write(fp, binary.LittleEndian, truncated)
+			if width == RecordWidth64 {
+				if err = WriteHeader(fp); err != nil {
+					return err
+				}
+			}
+			err = WriteRecords(fp, width, truncated)
 			if err != nil {
 				return err
 			}
@@ -4,18 +4,44 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"sort"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
 )
 
+// TimestampRecord is the timestamp of a block. The timestamps file (ts.bin) holds one record for
+// each block starting at block zero. Older files have no header and store timestampRecord32s. Files
+// with 64-bit block numbers start with a tsHeader (as wide as a record) and store this structure as is.
 type TimestampRecord struct {
-	Bn uint32 `json:"bn"`
+	Bn uint64 `json:"bn"`
 	Ts uint32 `json:"ts"`
 }
 
+// timestampRecord32 is a TimestampRecord as stored in timestamps files with 32-bit block numbers
+type timestampRecord32 struct {
+	Bn uint32
+	Ts uint32
+}
+
+// tsHeader is the header of a timestamps file with 64-bit block numbers. Its magic number (a block
+// number in older files) tells the two apart since the first record of an older file is block zero.
+type tsHeader struct {
+	Magic  uint32
+	Unused uint64
+}
+
+const (
+	// RecordWidth - size of a TimestampRecord (32-bit block numbers)
+	RecordWidth = 8
+	// RecordWidth64 - size of a TimestampRecord (and the header) with 64-bit block numbers
+	RecordWidth64 = 12
+)
+
 type TimestampDatabase struct {
 	loaded bool
 	count  uint64
@@ -37,9 +63,14 @@ func NTimestamps(chain string) (uint64, error) {
 		return 0, err
 	}
 
+	size, width := uint64(fileStat.Size()), recordWidth(tsPath)
+	if width == RecordWidth64 && size >= width {
+		size -= width // the header
+	}
+
 	perChainTimestamps[chain] = TimestampDatabase{
 		loaded: perChainTimestamps[chain].loaded,
-		count:  uint64(fileStat.Size()) / 8,
+		count:  size / width,
 		memory: perChainTimestamps[chain].memory,
 	}
 	return perChainTimestamps[chain].count, nil
@@ -63,8 +94,13 @@ func loadTimestamps(chain string) error {
 	}
 	defer tsFile.Close()
 
+	width := recordWidth(tsPath)
+	if width == RecordWidth64 {
+		_, _ = tsFile.Seek(int64(width), io.SeekStart)
+	}
+
 	memory := make([]TimestampRecord, cnt)
-	err = binary.Read(tsFile, binary.LittleEndian, memory)
+	err = readRecords(tsFile, width, memory)
 	if err != nil {
 		return err
 	}
@@ -97,7 +133,7 @@ func FromTs(chain string, ts base.Timestamp) (*TimestampRecord, error) {
 	if ts > base.Timestamp(perChainTimestamps[chain].memory[cnt-1].Ts) {
 		last := perChainTimestamps[chain].memory[cnt-1]
 		secs := ts - base.Timestamp(last.Ts)
-		blks := uint32(float64(secs) / 13.3)
+		blks := uint64(float64(secs) / 13.3)
 		last.Bn = last.Bn + blks
 		last.Ts = uint32(ts)
 		return &last, ErrInTheFuture
@@ -148,3 +184,70 @@ func FromBn(chain string, bn uint64) (*TimestampRecord, error) {
 
 	return &perChainTimestamps[chain].memory[bn], nil
 }
+
+// GetRecordWidth returns the width of the records in the chain's timestamps file, RecordWidth64 if
+// it stores 64-bit block numbers and RecordWidth otherwise (including if there is no file yet)
+func GetRecordWidth(chain string) uint64 {
+	return recordWidth(config.PathToIndex(chain) + "ts.bin")
+}
+
+func recordWidth(tsPath string) uint64 {
+	var magic uint32
+	if fp, err := os.Open(tsPath); err == nil {
+		_ = binary.Read(fp, binary.LittleEndian, &magic)
+		fp.Close()
+	}
+	if magic == file.MagicNumber {
+		return RecordWidth64
+	}
+	return RecordWidth
+}
+
+// readRecords reads len(records) records of the given width (RecordWidth or RecordWidth64)
+func readRecords(r io.Reader, width uint64, records []TimestampRecord) error {
+	if width == RecordWidth64 {
+		return binary.Read(r, binary.LittleEndian, records)
+	}
+
+	records32 := make([]timestampRecord32, len(records))
+	if err := binary.Read(r, binary.LittleEndian, records32); err != nil {
+		return err
+	}
+	for i, rec := range records32 {
+		records[i] = TimestampRecord{Bn: uint64(rec.Bn), Ts: rec.Ts}
+	}
+	return nil
+}
+
+// WriteRecords writes the records with the given width (RecordWidth or RecordWidth64). If the width
+// is RecordWidth64 and the writer is at the start of the file, the caller writes the header first
+// (see WriteHeader).
+func WriteRecords(w io.Writer, width uint64, records []TimestampRecord) error {
+	if width == RecordWidth64 {
+		return binary.Write(w, binary.LittleEndian, records)
+	}
+
+	records32 := make([]timestampRecord32, len(records))
+	for i, rec := range records {
+		if rec.Bn > math.MaxUint32 {
+			return fmt.Errorf("block number %d does not fit in a 32-bit timestamp record", rec.Bn)
+		}
+		records32[i] = timestampRecord32{Bn: uint32(rec.Bn), Ts: rec.Ts}
+	}
+	return binary.Write(w, binary.LittleEndian, records32)
+}
+
+// WriteHeader writes the header of a timestamps file with 64-bit block numbers
+func WriteHeader(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, tsHeader{Magic: file.MagicNumber})
+}
+
+// needs64 returns true if any of the records' block numbers do not fit in 32 bits
+func needs64(records []TimestampRecord) bool {
+	for _, rec := range records {
+		if rec.Bn > math.MaxUint32 {
+			return true
+		}
+	}
+	return false
+}
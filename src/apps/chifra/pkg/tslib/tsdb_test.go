@@ -0,0 +1,72 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package tslib
+
+import (
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+)
+
+func TestRecordWidths(t *testing.T) {
+	records := []TimestampRecord{
+		{Bn: 0, Ts: 1438269973},
+		{Bn: 1, Ts: 1438269988},
+		{Bn: 2, Ts: 1438270017},
+	}
+
+	for _, width := range []uint64{RecordWidth, RecordWidth64} {
+		tsPath := filepath.Join(t.TempDir(), "ts.bin")
+		fp, err := os.Create(tsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if width == RecordWidth64 {
+			if err = WriteHeader(fp); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = WriteRecords(fp, width, records); err != nil {
+			t.Fatal(err)
+		}
+		fp.Close()
+
+		if got := recordWidth(tsPath); got != width {
+			t.Error("wrong record width", got, "expected", width)
+		}
+		header := uint64(0)
+		if width == RecordWidth64 {
+			header = width
+		}
+		if size := uint64(file.FileSize(tsPath)); size != header+width*uint64(len(records)) {
+			t.Error("wrong file size", size)
+		}
+
+		fp, _ = os.Open(tsPath)
+		_, _ = fp.Seek(int64(header), io.SeekStart)
+		got := make([]TimestampRecord, len(records))
+		if err = readRecords(fp, width, got); err != nil {
+			t.Fatal(err)
+		}
+		fp.Close()
+		if !reflect.DeepEqual(got, records) {
+			t.Error("records do not round trip", width, got)
+		}
+	}
+
+	// A 32-bit file can't hold a 64-bit block number
+	big := []TimestampRecord{{Bn: math.MaxUint32 + 1, Ts: 1}}
+	if err := WriteRecords(io.Discard, RecordWidth, big); err == nil {
+		t.Error("expected an error writing a 64-bit block number to a 32-bit file")
+	}
+	if !needs64(big) || needs64(records) {
+		t.Error("needs64 is wrong")
+	}
+}
@@ -0,0 +1,60 @@
+package tslib
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+)
+
+// Upgrade rewrites the chain's timestamps file with 64-bit block numbers. It returns false if the
+// file already has them. A timestamps file that does not yet exist is created (empty) with 64-bit
+// block numbers.
+func Upgrade(chain string) (bool, error) {
+	tsFn := filepath.Join(config.PathToIndex(chain), "ts.bin")
+	if recordWidth(tsFn) == RecordWidth64 {
+		return false, nil
+	}
+
+	records := []TimestampRecord{}
+	if file.FileSize(tsFn) > 0 {
+		if err := loadTimestamps(chain); err != nil {
+			return false, err
+		}
+		records = perChainTimestamps[chain].memory
+	}
+
+	tmpPath := filepath.Join(config.PathToCache(chain), "tmp")
+	if backupFn, err := file.MakeBackup(tmpPath, tsFn); err == nil {
+		defer func() {
+			ClearCache(chain)
+			if file.FileExists(backupFn) {
+				// If the backup file exists, something failed, so we replace the original file.
+				_ = os.Rename(backupFn, tsFn)
+				_ = os.Remove(backupFn) // seems redundant, but may not be on some operating systems
+			}
+		}()
+
+		if fp, err := os.OpenFile(tsFn, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644); err == nil {
+			defer func() {
+				fp.Close()
+			}()
+
+			if err = WriteHeader(fp); err != nil {
+				return false, err
+			}
+			if err = WriteRecords(fp, RecordWidth64, records); err != nil {
+				return false, err
+			}
+			_ = fp.Sync()
+
+			os.Remove(backupFn)
+			return true, nil
+		} else {
+			return false, err
+		}
+	} else {
+		return false, err
+	}
+}
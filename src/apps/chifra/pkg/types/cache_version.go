@@ -0,0 +1,15 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package types
+
+import "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/version"
+
+// cacheVersion110 is the first cache version whose items carry the fields added in 1.1.0: the
+// EIP-4844 (blob) fields of blocks and transactions, and the withdrawals, token IDs, and token
+// types of statements. Items written by earlier versions are read without them.
+var cacheVersion110 = func() uint64 {
+	ver, _ := version.NewVersion("GHC-TrueBlocks//1.1.0-release")
+	return ver.Uint64()
+}()
@@ -25,8 +25,10 @@ import (
 type RawBlock struct {
 	Author           string   `json:"author"`
 	BaseFeePerGas    string   `json:"baseFeePerGas"`
+	BlobGasUsed      string   `json:"blobGasUsed"`
 	BlockNumber      string   `json:"number"`
 	Difficulty       string   `json:"difficulty"`
+	ExcessBlobGas    string   `json:"excessBlobGas"`
 	ExtraData        string   `json:"extraData"`
 	GasLimit         string   `json:"gasLimit"`
 	GasUsed          string   `json:"gasUsed"`
@@ -46,13 +48,16 @@ type RawBlock struct {
 	TransactionsRoot string   `json:"transactionsRoot"`
 	Uncles           []string `json:"uncles"`
 	// EXISTING_CODE
+	Withdrawals []RawWithdrawal `json:"withdrawals"`
 	// EXISTING_CODE
 }
 
 type SimpleBlock[Tx string | SimpleTransaction] struct {
 	BaseFeePerGas base.Wei       `json:"baseFeePerGas"`
+	BlobGasUsed   base.Gas       `json:"blobGasUsed,omitempty"`
 	BlockNumber   base.Blknum    `json:"blockNumber"`
 	Difficulty    uint64         `json:"difficulty"`
+	ExcessBlobGas base.Gas       `json:"excessBlobGas,omitempty"`
 	GasLimit      base.Gas       `json:"gasLimit"`
 	GasUsed       base.Gas       `json:"gasUsed"`
 	Hash          base.Hash      `json:"hash"`
@@ -138,6 +143,12 @@ func (s *SimpleBlock[Tx]) Model(chain, format string, verbose bool, extraOptions
 	}
 
 	if format == "json" {
+		if s.BlobGasUsed > 0 {
+			model["blobGasUsed"] = s.BlobGasUsed
+		}
+		if s.ExcessBlobGas > 0 {
+			model["excessBlobGas"] = s.ExcessBlobGas
+		}
 		if extraOptions["list"] == true {
 			model["transactionsCnt"] = len(s.Transactions)
 			model["unclesCnt"] = len(s.Uncles)
@@ -163,6 +174,11 @@ func (s *SimpleBlock[Tx]) Model(chain, format string, verbose bool, extraOptions
 			order = append(order, "uncles")
 		}
 	} else {
+		if verbose {
+			model["blobGasUsed"] = s.BlobGasUsed
+			model["excessBlobGas"] = s.ExcessBlobGas
+			order = append(order, "blobGasUsed", "excessBlobGas")
+		}
 		model["transactionsCnt"] = len(s.Transactions)
 		order = append(order, "transactionsCnt")
 		if extraOptions["list"] == true {
@@ -211,6 +227,11 @@ func (s *SimpleBlock[Tx]) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// BlobGasUsed
+	if err = cache.WriteValue(writer, s.BlobGasUsed); err != nil {
+		return err
+	}
+
 	// BlockNumber
 	if err = cache.WriteValue(writer, s.BlockNumber); err != nil {
 		return err
@@ -221,6 +242,11 @@ func (s *SimpleBlock[Tx]) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// ExcessBlobGas
+	if err = cache.WriteValue(writer, s.ExcessBlobGas); err != nil {
+		return err
+	}
+
 	// GasLimit
 	if err = cache.WriteValue(writer, s.GasLimit); err != nil {
 		return err
@@ -280,6 +306,13 @@ func (s *SimpleBlock[string]) UnmarshalCache(version uint64, reader io.Reader) (
 		return err
 	}
 
+	// BlobGasUsed
+	if version >= cacheVersion110 {
+		if err = cache.ReadValue(reader, &s.BlobGasUsed, version); err != nil {
+			return err
+		}
+	}
+
 	// BlockNumber
 	if err = cache.ReadValue(reader, &s.BlockNumber, version); err != nil {
 		return err
@@ -290,6 +323,13 @@ func (s *SimpleBlock[string]) UnmarshalCache(version uint64, reader io.Reader) (
 		return err
 	}
 
+	// ExcessBlobGas
+	if version >= cacheVersion110 {
+		if err = cache.ReadValue(reader, &s.ExcessBlobGas, version); err != nil {
+			return err
+		}
+	}
+
 	// GasLimit
 	if err = cache.ReadValue(reader, &s.GasLimit, version); err != nil {
 		return err
@@ -348,8 +388,10 @@ func (s *SimpleBlock[Tx]) FinishUnmarshal() {
 // Dup duplicates all fields but Transactions into target
 func (s *SimpleBlock[string]) Dup(target *SimpleBlock[SimpleTransaction]) {
 	target.BaseFeePerGas = s.BaseFeePerGas
+	target.BlobGasUsed = s.BlobGasUsed
 	target.BlockNumber = s.BlockNumber
 	target.Difficulty = s.Difficulty
+	target.ExcessBlobGas = s.ExcessBlobGas
 	target.GasLimit = s.GasLimit
 	target.GasUsed = s.GasUsed
 	target.Hash = s.Hash
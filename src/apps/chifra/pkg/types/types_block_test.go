@@ -1,6 +1,7 @@
 package types
 
 import (
+	"bytes"
 	"math/big"
 	"reflect"
 	"testing"
@@ -45,3 +46,77 @@ func TestBlockCache(t *testing.T) {
 		t.Fatalf("value mismatch: got %+v want %+v\n", readBack, expected)
 	}
 }
+
+func TestBlockCacheBlobs(t *testing.T) {
+	expected := &SimpleBlock[string]{
+		BlockNumber:   19426587,
+		BaseFeePerGas: *(big.NewInt(28000000000)),
+		BlobGasUsed:   393216,
+		ExcessBlobGas: 262144,
+		GasLimit:      uint64(30000000),
+		GasUsed:       uint64(13120000),
+		Hash:          base.HexToHash("0xf8e2f40d98fe5862bc947c8c83d34799c50fb344d7445d020a8a946d891b62ee"),
+		Miner:         base.HexToAddress("0x95222290dd7278aa3ddd389cc1e1d165cc4bafe5"),
+		ParentHash:    base.HexToHash("0x0e39a5c0c5a9a3f0f2e5b7b2b7c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2"),
+		Timestamp:     1710338135,
+		Transactions:  []string{},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := expected.MarshalCache(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	readBack := &SimpleBlock[string]{}
+	if err := readBack.UnmarshalCache(cacheVersion110, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, readBack) {
+		t.Fatalf("value mismatch: got %+v want %+v\n", readBack, expected)
+	}
+}
+
+// TestBlockCacheBeforeBlobs reads a block written before the blob fields were added to the cache
+func TestBlockCacheBeforeBlobs(t *testing.T) {
+	expected := &SimpleBlock[string]{
+		BlockNumber:   4000001,
+		BaseFeePerGas: *(big.NewInt(0)),
+		Difficulty:    uint64(1097113993909745),
+		GasLimit:      uint64(6712392),
+		GasUsed:       uint64(337966),
+		Hash:          base.HexToHash("0x79990fd526c4751139a7a3afc7420cde1a1141b1920d2afd411858ecb4926a39"),
+		Miner:         base.HexToAddress("0xea674fdde714fd979de3edf0f56aa9716b898ec8"),
+		ParentHash:    base.HexToHash("0xb8a3f7f5cfc1748f91a684f20fe89031202cbadcd15078c49b85ec2a57f43853"),
+		Timestamp:     1499633571,
+		Transactions:  []string{},
+	}
+
+	buf := new(bytes.Buffer)
+	for _, value := range []any{
+		&expected.BaseFeePerGas,
+		expected.BlockNumber,
+		expected.Difficulty,
+		expected.GasLimit,
+		expected.GasUsed,
+		&expected.Hash,
+		expected.Miner,
+		&expected.ParentHash,
+		expected.Timestamp,
+		expected.Transactions,
+		expected.Uncles,
+	} {
+		if err := cache.WriteValue(buf, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	readBack := &SimpleBlock[string]{}
+	if err := readBack.UnmarshalCache(cacheVersion110-1, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, readBack) {
+		t.Fatalf("value mismatch: got %+v want %+v\n", readBack, expected)
+	}
+}
@@ -0,0 +1,354 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were generated with makeClass --run. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package types
+
+// EXISTING_CODE
+import (
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
+)
+
+// EXISTING_CODE
+
+type RawBlockFee struct {
+	BaseFeePerGas   string `json:"baseFeePerGas"`
+	BlobGasUsed     string `json:"blobGasUsed"`
+	BlockNumber     string `json:"blockNumber"`
+	BlocksCnt       string `json:"blocksCnt"`
+	Burned          string `json:"burned"`
+	GasLimit        string `json:"gasLimit"`
+	GasUsed         string `json:"gasUsed"`
+	LastBlock       string `json:"lastBlock"`
+	Period          string `json:"period"`
+	PriorityFeeP10  string `json:"priorityFeeP10"`
+	PriorityFeeP50  string `json:"priorityFeeP50"`
+	PriorityFeeP90  string `json:"priorityFeeP90"`
+	Timestamp       string `json:"timestamp"`
+	TransactionsCnt string `json:"transactionsCnt"`
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+type SimpleBlockFee struct {
+	BaseFeePerGas   base.Gas       `json:"baseFeePerGas"`
+	BlobGasUsed     base.Gas       `json:"blobGasUsed,omitempty"`
+	BlockNumber     base.Blknum    `json:"blockNumber"`
+	BlocksCnt       uint64         `json:"blocksCnt,omitempty"`
+	Burned          base.Wei       `json:"burned"`
+	GasLimit        base.Gas       `json:"gasLimit"`
+	GasUsed         base.Gas       `json:"gasUsed"`
+	LastBlock       base.Blknum    `json:"lastBlock,omitempty"`
+	Period          string         `json:"period,omitempty"`
+	PriorityFeeP10  base.Gas       `json:"priorityFeeP10"`
+	PriorityFeeP50  base.Gas       `json:"priorityFeeP50"`
+	PriorityFeeP90  base.Gas       `json:"priorityFeeP90"`
+	Timestamp       base.Timestamp `json:"timestamp"`
+	TransactionsCnt uint64         `json:"transactionsCnt"`
+	raw             *RawBlockFee   `json:"-"`
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+func (s *SimpleBlockFee) Raw() *RawBlockFee {
+	return s.raw
+}
+
+func (s *SimpleBlockFee) SetRaw(raw *RawBlockFee) {
+	s.raw = raw
+}
+
+func (s *SimpleBlockFee) Model(chain, format string, verbose bool, extraOptions map[string]any) Model {
+	var model = map[string]interface{}{}
+	var order = []string{}
+
+	// EXISTING_CODE
+	model = map[string]interface{}{
+		"blockNumber": s.BlockNumber,
+	}
+	order = []string{"blockNumber"}
+
+	if len(s.Period) > 0 {
+		model["lastBlock"] = s.LastBlock
+		model["period"] = s.Period
+		model["blocksCnt"] = s.BlocksCnt
+		order = append(order, "lastBlock", "period", "blocksCnt")
+	}
+
+	if verbose {
+		model["timestamp"] = s.Timestamp
+		model["date"] = s.Date()
+		order = append(order, "timestamp", "date")
+	}
+
+	model["transactionsCnt"] = s.TransactionsCnt
+	model["gasUsed"] = s.GasUsed
+	model["gasLimit"] = s.GasLimit
+	model["utilization"] = s.Utilization()
+	model["baseFeePerGas"] = s.BaseFeePerGas
+	model["priorityFeeP10"] = s.PriorityFeeP10
+	model["priorityFeeP50"] = s.PriorityFeeP50
+	model["priorityFeeP90"] = s.PriorityFeeP90
+	model["burned"] = s.Burned.String()
+	order = append(order, []string{
+		"transactionsCnt",
+		"gasUsed",
+		"gasLimit",
+		"utilization",
+		"baseFeePerGas",
+		"priorityFeeP10",
+		"priorityFeeP50",
+		"priorityFeeP90",
+		"burned",
+	}...)
+
+	if format != "json" || s.BlobGasUsed > 0 {
+		model["blobGasUsed"] = s.BlobGasUsed
+		order = append(order, "blobGasUsed")
+	}
+	// EXISTING_CODE
+
+	return Model{
+		Data:  model,
+		Order: order,
+	}
+}
+
+func (s *SimpleBlockFee) Date() string {
+	return utils.FormattedDate(s.Timestamp)
+}
+
+// --> cacheable by block
+func (s *SimpleBlockFee) CacheName() string {
+	return "BlockFee"
+}
+
+func (s *SimpleBlockFee) CacheId() string {
+	return fmt.Sprintf("%09d", s.BlockNumber)
+}
+
+func (s *SimpleBlockFee) CacheLocation() (directory string, extension string) {
+	paddedId := s.CacheId()
+	parts := make([]string, 3)
+	parts[0] = paddedId[:2]
+	parts[1] = paddedId[2:4]
+	parts[2] = paddedId[4:6]
+
+	subFolder := strings.ToLower(s.CacheName()) + "s"
+	directory = filepath.Join(subFolder, filepath.Join(parts...))
+	extension = "bin"
+
+	return
+}
+
+func (s *SimpleBlockFee) MarshalCache(writer io.Writer) (err error) {
+	// BaseFeePerGas
+	if err = cache.WriteValue(writer, s.BaseFeePerGas); err != nil {
+		return err
+	}
+
+	// BlobGasUsed
+	if err = cache.WriteValue(writer, s.BlobGasUsed); err != nil {
+		return err
+	}
+
+	// BlockNumber
+	if err = cache.WriteValue(writer, s.BlockNumber); err != nil {
+		return err
+	}
+
+	// Burned
+	if err = cache.WriteValue(writer, &s.Burned); err != nil {
+		return err
+	}
+
+	// GasLimit
+	if err = cache.WriteValue(writer, s.GasLimit); err != nil {
+		return err
+	}
+
+	// GasUsed
+	if err = cache.WriteValue(writer, s.GasUsed); err != nil {
+		return err
+	}
+
+	// PriorityFeeP10
+	if err = cache.WriteValue(writer, s.PriorityFeeP10); err != nil {
+		return err
+	}
+
+	// PriorityFeeP50
+	if err = cache.WriteValue(writer, s.PriorityFeeP50); err != nil {
+		return err
+	}
+
+	// PriorityFeeP90
+	if err = cache.WriteValue(writer, s.PriorityFeeP90); err != nil {
+		return err
+	}
+
+	// Timestamp
+	if err = cache.WriteValue(writer, s.Timestamp); err != nil {
+		return err
+	}
+
+	// TransactionsCnt
+	if err = cache.WriteValue(writer, s.TransactionsCnt); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *SimpleBlockFee) UnmarshalCache(version uint64, reader io.Reader) (err error) {
+	// BaseFeePerGas
+	if err = cache.ReadValue(reader, &s.BaseFeePerGas, version); err != nil {
+		return err
+	}
+
+	// BlobGasUsed
+	if err = cache.ReadValue(reader, &s.BlobGasUsed, version); err != nil {
+		return err
+	}
+
+	// BlockNumber
+	if err = cache.ReadValue(reader, &s.BlockNumber, version); err != nil {
+		return err
+	}
+
+	// Burned
+	if err = cache.ReadValue(reader, &s.Burned, version); err != nil {
+		return err
+	}
+
+	// GasLimit
+	if err = cache.ReadValue(reader, &s.GasLimit, version); err != nil {
+		return err
+	}
+
+	// GasUsed
+	if err = cache.ReadValue(reader, &s.GasUsed, version); err != nil {
+		return err
+	}
+
+	// PriorityFeeP10
+	if err = cache.ReadValue(reader, &s.PriorityFeeP10, version); err != nil {
+		return err
+	}
+
+	// PriorityFeeP50
+	if err = cache.ReadValue(reader, &s.PriorityFeeP50, version); err != nil {
+		return err
+	}
+
+	// PriorityFeeP90
+	if err = cache.ReadValue(reader, &s.PriorityFeeP90, version); err != nil {
+		return err
+	}
+
+	// Timestamp
+	if err = cache.ReadValue(reader, &s.Timestamp, version); err != nil {
+		return err
+	}
+
+	// TransactionsCnt
+	if err = cache.ReadValue(reader, &s.TransactionsCnt, version); err != nil {
+		return err
+	}
+
+	s.FinishUnmarshal()
+
+	return nil
+}
+
+func (s *SimpleBlockFee) FinishUnmarshal() {
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+// EXISTING_CODE
+// Utilization returns the gas used as a percentage of the gas limit rounded to two decimal places
+func (s *SimpleBlockFee) Utilization() float64 {
+	if s.GasLimit == 0 {
+		return 0
+	}
+	return math.Round(float64(s.GasUsed)/float64(s.GasLimit)*10000) / 100
+}
+
+// BlockFeePeriod is the cache item for the fees of a whole period (see `chifra blocks --fees
+// --period`). It's keyed by the period's name, which also tells which kind of period it is.
+type BlockFeePeriod struct {
+	SimpleBlockFee
+}
+
+func (s *BlockFeePeriod) CacheName() string {
+	return "BlockFeePeriod"
+}
+
+func (s *BlockFeePeriod) CacheId() string {
+	return s.Period
+}
+
+func (s *BlockFeePeriod) CacheLocation() (directory string, extension string) {
+	return filepath.Join("blockfees", "periods"), "bin"
+}
+
+func (s *BlockFeePeriod) MarshalCache(writer io.Writer) (err error) {
+	if err = s.SimpleBlockFee.MarshalCache(writer); err != nil {
+		return err
+	}
+
+	// BlocksCnt
+	if err = cache.WriteValue(writer, s.BlocksCnt); err != nil {
+		return err
+	}
+
+	// LastBlock
+	if err = cache.WriteValue(writer, s.LastBlock); err != nil {
+		return err
+	}
+
+	// Period
+	if err = cache.WriteValue(writer, s.Period); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *BlockFeePeriod) UnmarshalCache(version uint64, reader io.Reader) (err error) {
+	if err = s.SimpleBlockFee.UnmarshalCache(version, reader); err != nil {
+		return err
+	}
+
+	// BlocksCnt
+	if err = cache.ReadValue(reader, &s.BlocksCnt, version); err != nil {
+		return err
+	}
+
+	// LastBlock
+	if err = cache.ReadValue(reader, &s.LastBlock, version); err != nil {
+		return err
+	}
+
+	// Period
+	if err = cache.ReadValue(reader, &s.Period, version); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EXISTING_CODE
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestBlockFeePeriodCache(t *testing.T) {
+	expected := &BlockFeePeriod{
+		SimpleBlockFee: SimpleBlockFee{
+			BaseFeePerGas:   12000000000,
+			BlockNumber:     18908895,
+			BlocksCnt:       7163,
+			Burned:          *big.NewInt(1234567890),
+			GasLimit:        214890000000,
+			GasUsed:         107445000000,
+			LastBlock:       18916057,
+			Period:          "2024-01-01",
+			PriorityFeeP10:  100000000,
+			PriorityFeeP50:  1000000000,
+			PriorityFeeP90:  3000000000,
+			Timestamp:       1704067211,
+			TransactionsCnt: 1080000,
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := expected.MarshalCache(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	readBack := &BlockFeePeriod{}
+	if err := readBack.UnmarshalCache(cacheVersion110, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, readBack) {
+		t.Fatalf("value mismatch: got %+v want %+v\n", readBack, expected)
+	}
+
+	if dir, _ := readBack.CacheLocation(); dir != "blockfees/periods" || readBack.CacheId() != "2024-01-01" {
+		t.Error("wrong cache location", dir, readBack.CacheId())
+	}
+}
@@ -96,6 +96,11 @@ func (s *SimpleFunction) Model(chain, format string, verbose bool, extraOptions
 		"signature",
 	}
 
+	if s.Message != "" {
+		model["message"] = s.Message
+		order = append(order, "message")
+	}
+
 	if format == "json" {
 		getParameterModels := func(params []SimpleParameter) []map[string]any {
 			result := make([]map[string]any, len(params))
@@ -0,0 +1,157 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were generated with makeClass --run. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package types
+
+// EXISTING_CODE
+import (
+	"math/big"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
+)
+
+// EXISTING_CODE
+
+type RawGain struct {
+	AccountedFor   string `json:"accountedFor"`
+	AssetAddr      string `json:"assetAddr"`
+	AssetSymbol    string `json:"assetSymbol"`
+	BlockNumber    string `json:"blockNumber"`
+	CostBasis      string `json:"costBasis"`
+	Decimals       string `json:"decimals"`
+	EndBal         string `json:"endBal"`
+	HeldCostBasis  string `json:"heldCostBasis"`
+	LongTermGain   string `json:"longTermGain"`
+	MarketValue    string `json:"marketValue"`
+	Method         string `json:"method"`
+	Period         string `json:"period"`
+	Proceeds       string `json:"proceeds"`
+	QuantityIn     string `json:"quantityIn"`
+	QuantityOut    string `json:"quantityOut"`
+	RealizedGain   string `json:"realizedGain"`
+	ShortTermGain  string `json:"shortTermGain"`
+	SpotPrice      string `json:"spotPrice"`
+	Timestamp      string `json:"timestamp"`
+	TokenId        string `json:"tokenId"`
+	TokenType      string `json:"tokenType"`
+	UnpricedBal    string `json:"unpricedBal"`
+	UnpricedOut    string `json:"unpricedOut"`
+	UnrealizedGain string `json:"unrealizedGain"`
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+type SimpleGain struct {
+	AccountedFor   base.Address   `json:"accountedFor"`
+	AssetAddr      base.Address   `json:"assetAddr"`
+	AssetSymbol    string         `json:"assetSymbol"`
+	BlockNumber    base.Blknum    `json:"blockNumber"`
+	CostBasis      float64        `json:"costBasis"`
+	Decimals       uint64         `json:"decimals"`
+	EndBal         big.Int        `json:"endBal"`
+	HeldCostBasis  float64        `json:"heldCostBasis"`
+	LongTermGain   float64        `json:"longTermGain"`
+	MarketValue    float64        `json:"marketValue"`
+	Method         string         `json:"method"`
+	Period         string         `json:"period"`
+	Proceeds       float64        `json:"proceeds"`
+	QuantityIn     big.Int        `json:"quantityIn"`
+	QuantityOut    big.Int        `json:"quantityOut"`
+	RealizedGain   float64        `json:"realizedGain"`
+	ShortTermGain  float64        `json:"shortTermGain"`
+	SpotPrice      float64        `json:"spotPrice"`
+	Timestamp      base.Timestamp `json:"timestamp"`
+	TokenId        big.Int        `json:"tokenId,omitempty"`
+	TokenType      TokenType      `json:"tokenType,omitempty"`
+	UnpricedBal    big.Int        `json:"unpricedBal"`
+	UnpricedOut    big.Int        `json:"unpricedOut"`
+	UnrealizedGain float64        `json:"unrealizedGain"`
+	raw            *RawGain       `json:"-"`
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+func (s *SimpleGain) Raw() *RawGain {
+	return s.raw
+}
+
+func (s *SimpleGain) SetRaw(raw *RawGain) {
+	s.raw = raw
+}
+
+func (s *SimpleGain) Model(chain, format string, verbose bool, extraOptions map[string]any) Model {
+	var model = map[string]interface{}{}
+	var order = []string{}
+
+	// EXISTING_CODE
+	model = map[string]interface{}{
+		"period":         s.Period,
+		"blockNumber":    s.BlockNumber,
+		"timestamp":      s.Timestamp,
+		"date":           s.Date(),
+		"accountedFor":   s.AccountedFor,
+		"assetAddr":      s.AssetAddr,
+		"assetSymbol":    s.AssetSymbol,
+		"decimals":       s.Decimals,
+		"method":         s.Method,
+		"quantityIn":     formattedUnits(&s.QuantityIn, s.Decimals),
+		"quantityOut":    formattedUnits(&s.QuantityOut, s.Decimals),
+		"endBal":         formattedUnits(&s.EndBal, s.Decimals),
+		"proceeds":       s.Proceeds,
+		"costBasis":      s.CostBasis,
+		"realizedGain":   s.RealizedGain,
+		"shortTermGain":  s.ShortTermGain,
+		"longTermGain":   s.LongTermGain,
+		"unpricedOut":    formattedUnits(&s.UnpricedOut, s.Decimals),
+		"spotPrice":      s.SpotPrice,
+		"heldCostBasis":  s.HeldCostBasis,
+		"marketValue":    s.MarketValue,
+		"unrealizedGain": s.UnrealizedGain,
+		"unpricedBal":    formattedUnits(&s.UnpricedBal, s.Decimals),
+	}
+
+	order = []string{
+		"period", "blockNumber", "timestamp", "date", "accountedFor", "assetAddr", "assetSymbol",
+		"decimals", "method", "quantityIn", "quantityOut", "endBal", "proceeds", "costBasis",
+		"realizedGain", "shortTermGain", "longTermGain", "unpricedOut", "spotPrice", "heldCostBasis",
+		"marketValue", "unrealizedGain", "unpricedBal",
+	}
+
+	if format == "json" && s.TokenType.IsNft() {
+		model["tokenType"] = s.TokenType.String()
+		model["tokenId"] = s.TokenId.String()
+		order = append(order, "tokenType", "tokenId")
+	}
+	// EXISTING_CODE
+
+	return Model{
+		Data:  model,
+		Order: order,
+	}
+}
+
+func (s *SimpleGain) Date() string {
+	return utils.FormattedDate(s.Timestamp)
+}
+
+// EXISTING_CODE
+//
+
+// formattedUnits returns the amount expressed in whole units of an asset with the given decimals
+func formattedUnits(amount *big.Int, decimals uint64) string {
+	if decimals == 0 {
+		return amount.Text(10)
+	}
+	divisor := new(big.Int).Exp(big.NewInt(10), new(big.Int).SetUint64(decimals), nil)
+	f := new(big.Float).SetPrec(236).SetInt(amount)
+	f.Quo(f, new(big.Float).SetPrec(236).SetInt(divisor))
+	return f.Text('f', -1)
+}
+
+// EXISTING_CODE
@@ -16,7 +16,7 @@ type SimpleMonitor struct {
 	Address     string      `json:"address"`
 	NRecords    int         `json:"nRecords"`
 	FileSize    int64       `json:"fileSize"`
-	LastScanned uint32      `json:"lastScanned"`
+	LastScanned uint64      `json:"lastScanned"`
 	Deleted     bool        `json:"deleted"`
 	raw         *RawMonitor `json:"-"`
 	// EXISTING_CODE
@@ -56,8 +56,11 @@ type RawStatement struct {
 	Sender              string `json:"sender"`
 	SpotPrice           string `json:"spotPrice"`
 	Timestamp           string `json:"timestamp"`
+	TokenId             string `json:"tokenId"`
+	TokenType           string `json:"tokenType"`
 	TransactionHash     string `json:"transactionHash"`
 	TransactionIndex    string `json:"transactionIndex"`
+	WithdrawalIn        string `json:"withdrawalIn"`
 	// EXISTING_CODE
 	// EXISTING_CODE
 }
@@ -94,8 +97,11 @@ type SimpleStatement struct {
 	Sender              base.Address   `json:"sender"`
 	SpotPrice           float64        `json:"spotPrice"`
 	Timestamp           base.Timestamp `json:"timestamp"`
+	TokenId             big.Int        `json:"tokenId,omitempty"`
+	TokenType           TokenType      `json:"tokenType,omitempty"`
 	TransactionHash     base.Hash      `json:"transactionHash"`
 	TransactionIndex    base.Blknum    `json:"transactionIndex"`
+	WithdrawalIn        big.Int        `json:"withdrawalIn,omitempty"`
 	raw                 *RawStatement  `json:"-"`
 	// EXISTING_CODE
 	// EXISTING_CODE
@@ -146,6 +152,7 @@ func (s *SimpleStatement) Model(chain, format string, verbose bool, extraOptions
 		"minerUncleRewardIn":  utils.FormattedValue(s.MinerUncleRewardIn, asEther, decimals),
 		"correctingIn":        utils.FormattedValue(s.CorrectingIn, asEther, decimals),
 		"prefundIn":           utils.FormattedValue(s.PrefundIn, asEther, decimals),
+		"withdrawalIn":        utils.FormattedValue(s.WithdrawalIn, asEther, decimals),
 		"totalOut":            utils.FormattedValue(*s.TotalOut(), asEther, decimals),
 		"amountOut":           utils.FormattedValue(s.AmountOut, asEther, decimals),
 		"internalOut":         utils.FormattedValue(s.InternalOut, asEther, decimals),
@@ -165,10 +172,22 @@ func (s *SimpleStatement) Model(chain, format string, verbose bool, extraOptions
 		"assetAddr", "assetSymbol", "decimals", "spotPrice", "priceSource", "accountedFor",
 		"sender", "recipient", "begBal", "amountNet", "endBal", "reconciliationType", "reconciled",
 		"totalIn", "amountIn", "internalIn", "selfDestructIn", "minerBaseRewardIn", "minerNephewRewardIn",
-		"minerTxFeeIn", "minerUncleRewardIn", "prefundIn", "totalOut", "amountOut", "internalOut",
+		"minerTxFeeIn", "minerUncleRewardIn", "prefundIn", "withdrawalIn", "totalOut", "amountOut", "internalOut",
 		"selfDestructOut", "gasOut", "totalOutLessGas", "prevAppBlk", "prevBal", "begBalDiff",
 		"endBalDiff", "endBalCalc", "correctingReason",
 	}
+
+	if s.TokenType.IsNft() {
+		// Statements for non-fungible tokens are kept per token ID
+		model["tokenType"] = s.TokenType.String()
+		model["tokenId"] = s.TokenId.String()
+		order = append(order, "tokenType", "tokenId")
+	} else if format != "json" {
+		// Every row of delimited output has the same columns, so they're empty for fungible tokens
+		model["tokenType"] = ""
+		model["tokenId"] = ""
+		order = append(order, "tokenType", "tokenId")
+	}
 	// EXISTING_CODE
 
 	return Model{
@@ -375,6 +394,16 @@ func (s *SimpleStatement) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// TokenId
+	if err = cache.WriteValue(writer, &s.TokenId); err != nil {
+		return err
+	}
+
+	// TokenType
+	if err = cache.WriteValue(writer, uint64(s.TokenType)); err != nil {
+		return err
+	}
+
 	// TransactionHash
 	if err = cache.WriteValue(writer, &s.TransactionHash); err != nil {
 		return err
@@ -385,6 +414,11 @@ func (s *SimpleStatement) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// WithdrawalIn
+	if err = cache.WriteValue(writer, &s.WithdrawalIn); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -544,6 +578,20 @@ func (s *SimpleStatement) UnmarshalCache(version uint64, reader io.Reader) (err
 		return err
 	}
 
+	if version >= cacheVersion110 {
+		// TokenId
+		if err = cache.ReadValue(reader, &s.TokenId, version); err != nil {
+			return err
+		}
+
+		// TokenType
+		var tokenType uint64
+		if err = cache.ReadValue(reader, &tokenType, version); err != nil {
+			return err
+		}
+		s.TokenType = TokenType(tokenType)
+	}
+
 	// TransactionHash
 	if err = cache.ReadValue(reader, &s.TransactionHash, version); err != nil {
 		return err
@@ -554,6 +602,13 @@ func (s *SimpleStatement) UnmarshalCache(version uint64, reader io.Reader) (err
 		return err
 	}
 
+	// WithdrawalIn
+	if version >= cacheVersion110 {
+		if err = cache.ReadValue(reader, &s.WithdrawalIn, version); err != nil {
+			return err
+		}
+	}
+
 	s.FinishUnmarshal()
 
 	return nil
@@ -578,6 +633,7 @@ func (s *SimpleStatement) TotalIn() *big.Int {
 		s.MinerUncleRewardIn,
 		s.CorrectingIn,
 		s.PrefundIn,
+		s.WithdrawalIn,
 	}
 
 	sum := big.NewInt(0)
@@ -656,6 +712,7 @@ func (s *SimpleStatement) ClearInternal() {
 	s.CorrectingIn.SetUint64(0)
 	s.PrefundIn.SetUint64(0)
 	s.SelfDestructIn.SetUint64(0)
+	s.WithdrawalIn.SetUint64(0)
 
 	// s.AmountOut.SetUint64(0)
 	// s.GasOut.SetUint64(0)
@@ -809,6 +866,7 @@ func (r *SimpleStatement) Report(ctx Ledgerer, msg string) {
 	logger.TestLog(true, "   minerUncleRewardIn: ", r.MinerUncleRewardIn.Text(10))
 	logger.TestLog(true, "   correctingIn:       ", r.CorrectingIn.Text(10))
 	logger.TestLog(true, "   prefundIn:          ", r.PrefundIn.Text(10))
+	logger.TestLog(true, "   withdrawalIn:       ", r.WithdrawalIn.Text(10))
 	logger.TestLog(true, "   selfDestructIn:     ", r.SelfDestructIn.Text(10))
 	logger.TestLog(true, "   totalIn:            ", r.TotalIn().Text(10))
 	logger.TestLog(true, "   amountOut:          ", r.AmountOut.Text(10))
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/version"
+)
+
+func TestStatementCache(t *testing.T) {
+	expected := &SimpleStatement{
+		AccountedFor:     base.HexToAddress("0xf503017d7baf7fbc0fff7492b751025c6a78179b"),
+		AssetAddr:        base.HexToAddress("0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d"),
+		AssetSymbol:      "BAYC",
+		BlockNumber:      12299047,
+		EndBal:           *big.NewInt(1),
+		AmountIn:         *big.NewInt(1),
+		Timestamp:        1619564420,
+		TokenId:          *big.NewInt(8817),
+		TokenType:        TokenErc721,
+		TransactionHash:  base.HexToHash("0xdbd1a0e81d8a6b9bc2e6e2f8df9d0d9a9ed3fbef3e2c0b40a2fcde6e50e2c2a1"),
+		TransactionIndex: 104,
+		WithdrawalIn:     *big.NewInt(0),
+	}
+
+	buf := new(bytes.Buffer)
+	if err := expected.MarshalCache(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	readBack := &SimpleStatement{}
+	if err := readBack.UnmarshalCache(cacheVersion110, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, readBack) {
+		t.Fatalf("value mismatch: got %+v want %+v\n", readBack, expected)
+	}
+}
+
+// TestStatementCacheVersion100 reads a statement written by version 1.0.0, before token IDs, token
+// types, and withdrawals were added to the cache
+func TestStatementCacheVersion100(t *testing.T) {
+	expected := &SimpleStatement{
+		AccountedFor:       base.HexToAddress("0xf503017d7baf7fbc0fff7492b751025c6a78179b"),
+		AmountIn:           *big.NewInt(1000000000000000000),
+		AssetAddr:          base.FAKE_ETH_ADDRESS,
+		AssetSymbol:        "WEI",
+		BegBal:             *big.NewInt(0),
+		BlockNumber:        8856476,
+		Decimals:           18,
+		EndBal:             *big.NewInt(1000000000000000000),
+		PriceSource:        "uniswap",
+		Recipient:          base.HexToAddress("0xf503017d7baf7fbc0fff7492b751025c6a78179b"),
+		ReconciliationType: "regular",
+		Sender:             base.HexToAddress("0x001d14804b399c6ef80e64576f657660804fec0b"),
+		SpotPrice:          181.5,
+		Timestamp:          1572639538,
+		TransactionHash:    base.HexToHash("0xa5c2dc5fa5d4c4e6dd3e5e83bc3cba9f9c2b98ad20ae24d21e8e17ad22c3a5de"),
+		TransactionIndex:   87,
+	}
+
+	s := expected
+	buf := new(bytes.Buffer)
+	for _, value := range []any{
+		s.AccountedFor, &s.AmountIn, &s.AmountOut, s.AssetAddr, s.AssetSymbol, &s.BegBal, s.BlockNumber,
+		&s.CorrectingIn, &s.CorrectingOut, s.CorrectingReason, s.Decimals, &s.EndBal, &s.GasOut,
+		&s.InternalIn, &s.InternalOut, s.LogIndex, &s.MinerBaseRewardIn, &s.MinerNephewRewardIn,
+		&s.MinerTxFeeIn, &s.MinerUncleRewardIn, &s.PrefundIn, s.PrevAppBlk, &s.PrevBal, s.PriceSource,
+		s.Recipient, s.ReconciliationType, &s.SelfDestructIn, &s.SelfDestructOut, s.Sender, s.SpotPrice,
+		s.Timestamp, &s.TransactionHash, s.TransactionIndex,
+	} {
+		if err := cache.WriteValue(buf, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ver, err := version.NewVersion("GHC-TrueBlocks//1.0.0-release")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readBack := &SimpleStatement{}
+	if err := readBack.UnmarshalCache(ver.Uint64(), buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, readBack) {
+		t.Fatalf("value mismatch: got %+v want %+v\n", readBack, expected)
+	}
+	if buf.Len() != 0 {
+		t.Fatal("expected to read the whole statement, but", buf.Len(), "bytes remain")
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were generated with makeClass --run. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package types
+
+// EXISTING_CODE
+import (
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
+)
+
+// EXISTING_CODE
+
+type RawStorageSlot struct {
+	Address     string `json:"address"`
+	BlockNumber string `json:"blockNumber"`
+	Decoded     string `json:"decoded"`
+	Offset      string `json:"offset"`
+	Slot        string `json:"slot"`
+	Timestamp   string `json:"timestamp"`
+	Type        string `json:"type"`
+	Value       string `json:"value"`
+	Variable    string `json:"variable"`
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+type SimpleStorageSlot struct {
+	Address     base.Address    `json:"address"`
+	BlockNumber base.Blknum     `json:"blockNumber"`
+	Decoded     string          `json:"decoded"`
+	Offset      uint64          `json:"offset"`
+	Slot        base.Hash       `json:"slot"`
+	Timestamp   base.Timestamp  `json:"timestamp"`
+	Type        string          `json:"type"`
+	Value       base.Hash       `json:"value"`
+	Variable    string          `json:"variable"`
+	raw         *RawStorageSlot `json:"-"`
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+func (s *SimpleStorageSlot) Raw() *RawStorageSlot {
+	return s.raw
+}
+
+func (s *SimpleStorageSlot) SetRaw(raw *RawStorageSlot) {
+	s.raw = raw
+}
+
+func (s *SimpleStorageSlot) Model(chain, format string, verbose bool, extraOptions map[string]any) Model {
+	var model = map[string]interface{}{}
+	var order = []string{}
+
+	// EXISTING_CODE
+	model = map[string]interface{}{
+		"blockNumber": s.BlockNumber,
+		"address":     s.Address,
+		"slot":        s.Slot,
+		"value":       s.Value,
+	}
+	order = []string{"blockNumber", "address"}
+	if verbose {
+		model["timestamp"] = s.Timestamp
+		model["date"] = s.Date()
+		order = append(order, "timestamp", "date")
+	}
+	order = append(order, "slot", "value")
+
+	if extraOptions["layout"] == true {
+		model["variable"] = s.Variable
+		model["type"] = s.Type
+		model["offset"] = s.Offset
+		model["decoded"] = s.Decoded
+		order = append(order, "variable", "type", "offset", "decoded")
+	}
+	// EXISTING_CODE
+
+	return Model{
+		Data:  model,
+		Order: order,
+	}
+}
+
+func (s *SimpleStorageSlot) Date() string {
+	return utils.FormattedDate(s.Timestamp)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
@@ -170,6 +170,7 @@ type TokenType int
 const (
 	TokenErc20 TokenType = iota
 	TokenErc721
+	TokenErc1155
 )
 
 func (t TokenType) IsErc20() bool {
@@ -180,4 +181,24 @@ func (t TokenType) IsErc721() bool {
 	return t == TokenErc721
 }
 
+func (t TokenType) IsErc1155() bool {
+	return t == TokenErc1155
+}
+
+// IsNft returns true if the token type tracks individual token IDs
+func (t TokenType) IsNft() bool {
+	return t == TokenErc721 || t == TokenErc1155
+}
+
+func (t TokenType) String() string {
+	switch t {
+	case TokenErc721:
+		return "erc721"
+	case TokenErc1155:
+		return "erc1155"
+	default:
+		return "erc20"
+	}
+}
+
 // EXISTING_CODE
@@ -49,6 +49,7 @@ func NewReward(block, nephew, txFee, uncle *big.Int) (Rewards, big.Int) {
 
 type RawTransaction struct {
 	AccessList           []StorageSlot `json:"accessList"`
+	BlobVersionedHashes  []string      `json:"blobVersionedHashes"`
 	BlockHash            string        `json:"blockHash"`
 	BlockNumber          string        `json:"blockNumber"`
 	ChainId              string        `json:"chainId"`
@@ -57,6 +58,7 @@ type RawTransaction struct {
 	GasPrice             string        `json:"gasPrice"`
 	Hash                 string        `json:"hash"`
 	Input                string        `json:"input"`
+	MaxFeePerBlobGas     string        `json:"maxFeePerBlobGas"`
 	MaxFeePerGas         string        `json:"maxFeePerGas"`
 	MaxPriorityFeePerGas string        `json:"maxPriorityFeePerGas"`
 	Nonce                string        `json:"nonce"`
@@ -70,6 +72,7 @@ type RawTransaction struct {
 
 type SimpleTransaction struct {
 	ArticulatedTx        *SimpleFunction `json:"articulatedTx"`
+	BlobVersionedHashes  []base.Hash     `json:"blobVersionedHashes,omitempty"`
 	BlockHash            base.Hash       `json:"blockHash"`
 	BlockNumber          base.Blknum     `json:"blockNumber"`
 	CompressedTx         string          `json:"compressedTx"`
@@ -81,6 +84,7 @@ type SimpleTransaction struct {
 	Hash                 base.Hash       `json:"hash"`
 	Input                string          `json:"input"`
 	IsError              bool            `json:"isError"`
+	MaxFeePerBlobGas     base.Gas        `json:"maxFeePerBlobGas,omitempty"`
 	MaxFeePerGas         base.Gas        `json:"maxFeePerGas"`
 	MaxPriorityFeePerGas base.Gas        `json:"maxPriorityFeePerGas"`
 	Nonce                uint64          `json:"nonce"`
@@ -193,6 +197,12 @@ func (s *SimpleTransaction) Model(chain, format string, verbose bool, extraOptio
 		if s.MaxPriorityFeePerGas > 0 {
 			model["maxPriorityFeePerGas"] = s.MaxPriorityFeePerGas
 		}
+		if s.MaxFeePerBlobGas > 0 {
+			model["maxFeePerBlobGas"] = s.MaxFeePerBlobGas
+		}
+		if len(s.BlobVersionedHashes) > 0 {
+			model["blobVersionedHashes"] = s.BlobVersionedHashes
+		}
 		if len(s.Input) > 2 {
 			model["input"] = s.Input
 		}
@@ -271,6 +281,11 @@ func (s *SimpleTransaction) Model(chain, format string, verbose bool, extraOptio
 		ethGasPrice := utils.FormattedValue(*big.NewInt(0).SetUint64(s.GasPrice), true, 18)
 		model["ethGasPrice"] = ethGasPrice
 		model["isError"] = s.IsError
+		if verbose {
+			model["maxFeePerBlobGas"] = s.MaxFeePerBlobGas
+			model["nBlobs"] = len(s.BlobVersionedHashes)
+			order = append(order, "maxFeePerBlobGas", "nBlobs")
+		}
 
 		if extraOptions["articulate"] == true && s.ArticulatedTx != nil {
 			model["encoding"] = s.ArticulatedTx.Encoding
@@ -339,6 +354,11 @@ func (s *SimpleTransaction) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// BlobVersionedHashes
+	if err = cache.WriteValue(writer, s.BlobVersionedHashes); err != nil {
+		return err
+	}
+
 	// BlockHash
 	if err = cache.WriteValue(writer, &s.BlockHash); err != nil {
 		return err
@@ -389,6 +409,11 @@ func (s *SimpleTransaction) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// MaxFeePerBlobGas
+	if err = cache.WriteValue(writer, s.MaxFeePerBlobGas); err != nil {
+		return err
+	}
+
 	// MaxFeePerGas
 	if err = cache.WriteValue(writer, s.MaxFeePerGas); err != nil {
 		return err
@@ -445,6 +470,14 @@ func (s *SimpleTransaction) UnmarshalCache(version uint64, reader io.Reader) (er
 	}
 	s.ArticulatedTx = optArticulatedTx.Get()
 
+	// BlobVersionedHashes
+	if version >= cacheVersion110 {
+		s.BlobVersionedHashes = make([]base.Hash, 0)
+		if err = cache.ReadValue(reader, &s.BlobVersionedHashes, version); err != nil {
+			return err
+		}
+	}
+
 	// BlockHash
 	if err = cache.ReadValue(reader, &s.BlockHash, version); err != nil {
 		return err
@@ -495,6 +528,13 @@ func (s *SimpleTransaction) UnmarshalCache(version uint64, reader io.Reader) (er
 		return err
 	}
 
+	// MaxFeePerBlobGas
+	if version >= cacheVersion110 {
+		if err = cache.ReadValue(reader, &s.MaxFeePerBlobGas, version); err != nil {
+			return err
+		}
+	}
+
 	// MaxFeePerGas
 	if err = cache.ReadValue(reader, &s.MaxFeePerGas, version); err != nil {
 		return err
@@ -569,12 +609,21 @@ func NewRawTransactionFromMap(input map[string]any) (r *RawTransaction) {
 	r.GasPrice = fmt.Sprint(input["gasPrice"])
 	r.Hash = fmt.Sprint(input["hash"])
 	r.Input = fmt.Sprint(input["input"])
+	r.MaxFeePerBlobGas = fmt.Sprint(input["maxFeePerBlobGas"])
 	r.MaxFeePerGas = fmt.Sprint(input["maxFeePerGas"])
 	r.MaxPriorityFeePerGas = fmt.Sprint(input["maxPriorityFeePerGas"])
 	r.Nonce = fmt.Sprint(input["nonce"])
 	r.To = fmt.Sprint(input["to"])
 	r.TransactionIndex = fmt.Sprint(input["transactionIndex"])
+	r.TransactionType = fmt.Sprint(input["type"])
 	r.Value = fmt.Sprint(input["value"])
+	// Only present on blob-carrying (type 3) transactions
+	if hashes, ok := input["blobVersionedHashes"].([]any); ok {
+		r.BlobVersionedHashes = make([]string, 0, len(hashes))
+		for _, hash := range hashes {
+			r.BlobVersionedHashes = append(r.BlobVersionedHashes, fmt.Sprint(hash))
+		}
+	}
 
 	return
 }
@@ -599,6 +648,13 @@ func NewSimpleTransaction(raw *RawTransaction, receipt *SimpleReceipt, timestamp
 	s.GasPrice = utils.MustParseUint(raw.GasPrice)
 	s.MaxFeePerGas = utils.MustParseUint(raw.MaxFeePerGas)
 	s.MaxPriorityFeePerGas = utils.MustParseUint(raw.MaxPriorityFeePerGas)
+	s.MaxFeePerBlobGas = utils.MustParseUint(raw.MaxFeePerBlobGas)
+	if len(raw.BlobVersionedHashes) > 0 {
+		s.BlobVersionedHashes = make([]base.Hash, 0, len(raw.BlobVersionedHashes))
+		for _, hash := range raw.BlobVersionedHashes {
+			s.BlobVersionedHashes = append(s.BlobVersionedHashes, base.HexToHash(hash))
+		}
+	}
 	s.Input = raw.Input
 
 	s.HasToken = hasToken
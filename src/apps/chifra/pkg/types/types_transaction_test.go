@@ -136,3 +136,46 @@ func TestTransactionCacheArticulated(t *testing.T) {
 		t.Fatalf("value mismatch: got %+v want %+v\n", readBack, expected)
 	}
 }
+
+func TestTransactionCacheBlobs(t *testing.T) {
+	expected := &SimpleTransaction{
+		BlobVersionedHashes: []base.Hash{
+			base.HexToHash("0x01a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8"),
+			base.HexToHash("0x0100dcb1e0a1ac1a1ef09e3d4bbd9f0ee2dbef7ad56b3fb4b1b0f2c1ae4fe8f6"),
+		},
+		BlockNumber:          19426587,
+		From:                 base.HexToAddress("0x5050f69a9786f081509234f1a7f4684b5e5b76c9"),
+		Gas:                  21000,
+		GasPrice:             34000000000,
+		Hash:                 base.HexToHash("0x1b7e4ef0fbe1ab8e3a0d9e1e5b2e27b8a1c7b42c2cc3b1c4f1e5e2f5b4b0e6a1"),
+		Input:                "0x",
+		MaxFeePerBlobGas:     1000000000,
+		MaxFeePerGas:         50000000000,
+		MaxPriorityFeePerGas: 1000000000,
+		Nonce:                12,
+		Timestamp:            1710338135,
+		To:                   base.HexToAddress("0xff00000000000000000000000000000000000010"),
+		TransactionIndex:     3,
+	}
+
+	store, err := cache.NewStore(&cache.StoreOptions{Location: cache.MemoryCache})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Write(expected, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	readBack := &SimpleTransaction{
+		BlockNumber:      expected.BlockNumber,
+		TransactionIndex: expected.TransactionIndex,
+	}
+	if err := store.Read(readBack, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, readBack) {
+		t.Fatalf("value mismatch:\n\tgot %+v\n\twant %+v\n", readBack, expected)
+	}
+}
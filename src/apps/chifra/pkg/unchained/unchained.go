@@ -8,6 +8,17 @@ const (
 	ReadHashName_V2 = "manifestHashMap"                                                    // V2: The name of the function to read the hash
 )
 
+const (
+	Version64         = "trueblocks-core@v3.0.0"                                             // The version of index chunks (and bloom filters) that store 64-bit block numbers
+	HeaderMagicHash64 = "0x3f009a0a2721dab331cd96ff07d2f5fa03856d667f500f2f0111decb7407659f" // Internal hash for index chunks with 64-bit block numbers. The keccek256 of Version64
+)
+
+// IsHeaderMagicHash returns true if the hash found in the header of an index chunk or bloom filter
+// is that of one of the versions of the index (32- or 64-bit block numbers) we can read
+func IsHeaderMagicHash(hash string) bool {
+	return hash == HeaderMagicHash || hash == HeaderMagicHash64
+}
+
 const (
 	address_V2         = "0x0c316b7042b419d07d343f2f4f5bd54ff731183d" // V2: The address of the current version of the Unchained Index
 	preferredPublisher = "0xf503017d7baf7fbc0fff7492b751025c6a78179b" // V2: Us
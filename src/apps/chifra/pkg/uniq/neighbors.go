@@ -0,0 +1,48 @@
+package uniq
+
+import (
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// GetNeighbors returns the addresses that appear in the same transaction as the given appearance
+// (its sender and recipient, any contract it created, the emitters of its logs, addresses found in
+// its input, topics, and data, and the participants in its traces) with the reason each address
+// appears. The appearance's own address is not included, and each neighbor is reported only once
+// (for the first reason it's found). Block rewards and prefunds have no neighbors.
+func GetNeighbors(conn *rpc.Connection, app *types.SimpleAppearance) ([]types.SimpleAppearance, error) {
+	if app.BlockNumber == 0 || app.TransactionIndex >= 99996 {
+		return make([]types.SimpleAppearance, 0), nil
+	}
+
+	raw := types.RawAppearance{
+		Address:          app.Address.Hex(),
+		BlockNumber:      app.BlockNumber,
+		TransactionIndex: app.TransactionIndex,
+	}
+	tx, err := conn.GetTransactionByAppearance(&raw, true /* fetchTraces */)
+	if err != nil {
+		return nil, err
+	}
+
+	return neighborsInTransaction(conn, app, tx)
+}
+
+// neighborsInTransaction returns the addresses other than the appearance's own that appear in the
+// given transaction (which is the appearance's transaction), in the order they're found
+func neighborsInTransaction(conn *rpc.Connection, app *types.SimpleAppearance, tx *types.SimpleTransaction) ([]types.SimpleAppearance, error) {
+	neighbors := make([]types.SimpleAppearance, 0)
+	procFunc := func(s *types.SimpleAppearance) error {
+		if s.Address != app.Address {
+			neighbors = append(neighbors, *s)
+		}
+		return nil
+	}
+
+	addrMap := AddressBooleanMap{}
+	if err := GetUniqAddressesInTransaction(conn.Chain, procFunc, "", tx, tx.Timestamp, addrMap, conn); err != nil {
+		return nil, err
+	}
+
+	return neighbors, nil
+}
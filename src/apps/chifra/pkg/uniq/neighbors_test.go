@@ -0,0 +1,105 @@
+package uniq
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func TestNeighborsInTransaction(t *testing.T) {
+	a := base.HexToAddress("0x1111111111111111111111111111111111111111")
+	b := base.HexToAddress("0x2222222222222222222222222222222222222222")
+	c := base.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	newTx := func(from, to base.Address, logs []base.Address, traces [][2]base.Address) *types.SimpleTransaction {
+		tx := &types.SimpleTransaction{
+			BlockNumber:      100,
+			TransactionIndex: 2,
+			From:             from,
+			To:               to,
+			Receipt:          &types.SimpleReceipt{},
+		}
+		for _, emitter := range logs {
+			tx.Receipt.Logs = append(tx.Receipt.Logs, types.SimpleLog{
+				Address:          emitter,
+				BlockNumber:      100,
+				TransactionIndex: 2,
+			})
+		}
+		for i, trace := range traces {
+			tx.Traces = append(tx.Traces, types.SimpleTrace{
+				Action:           &types.SimpleTraceAction{From: trace[0], To: trace[1]},
+				BlockNumber:      100,
+				TransactionIndex: 2,
+				TraceIndex:       uint64(i),
+				TraceType:        "call",
+			})
+		}
+		return tx
+	}
+
+	tests := []struct {
+		name     string
+		app      base.Address
+		tx       *types.SimpleTransaction
+		expected []string
+	}{
+		{
+			name:     "first appearance",
+			app:      a,
+			tx:       newTx(a, b, []base.Address{c}, nil),
+			expected: []string{b.Hex() + " to", c.Hex() + " log_0_generator"},
+		},
+		{
+			name:     "last appearance",
+			app:      c,
+			tx:       newTx(a, b, nil, [][2]base.Address{{a, b}, {b, c}}),
+			expected: []string{a.Hex() + " from", b.Hex() + " to"},
+		},
+		{
+			name:     "gap",
+			app:      b,
+			tx:       newTx(a, b, []base.Address{b, c}, nil),
+			expected: []string{a.Hex() + " from", c.Hex() + " log_1_generator"},
+		},
+		{
+			name:     "empty",
+			app:      a,
+			tx:       newTx(a, a, nil, nil),
+			expected: []string{},
+		},
+	}
+
+	conn := &rpc.Connection{Chain: "mainnet"}
+	for _, tt := range tests {
+		app := &types.SimpleAppearance{Address: tt.app, BlockNumber: 100, TransactionIndex: 2}
+		neighbors, err := neighborsInTransaction(conn, app, tt.tx)
+		if err != nil {
+			t.Fatal(tt.name, err)
+		}
+		got := []string{}
+		for _, neighbor := range neighbors {
+			got = append(got, neighbor.Address.Hex()+" "+neighbor.Reason)
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Error(tt.name, "expected", tt.expected, "got", got)
+		}
+	}
+}
+
+func TestGetNeighborsRewards(t *testing.T) {
+	apps := []types.SimpleAppearance{
+		{Address: base.HexToAddress("0x1111111111111111111111111111111111111111"), BlockNumber: 0, TransactionIndex: 12},
+		{Address: base.HexToAddress("0x1111111111111111111111111111111111111111"), BlockNumber: 100, TransactionIndex: 99999},
+	}
+	for _, app := range apps {
+		app := app
+		// Neither requires a connection, as there is no transaction to fetch
+		if neighbors, err := GetNeighbors(nil, &app); err != nil || len(neighbors) != 0 {
+			t.Error("expected no neighbors for", app.BlockNumber, app.TransactionIndex, "got", neighbors, err)
+		}
+	}
+}
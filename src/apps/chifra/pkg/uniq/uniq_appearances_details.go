@@ -98,7 +98,7 @@ func GetUniqAddressesInTransaction(chain string, procFunc UniqProcFunc, flow str
 		for i := 0; i < len(inputData)/64; i++ {
 			str := string(inputData[i*64 : (i+1)*64])
 			if index.IsImplicitAddress(str) {
-				streamAppearance(procFunc, flow, str, reason, bn, txid, traceid, ts, addrMap)
+				streamAppearance(procFunc, flow, reason, str, bn, txid, traceid, ts, addrMap)
 			}
 		}
 	}
@@ -54,7 +54,7 @@ func IsValidChunk(path string, ch ChunkSizes, indexRequired bool) (ErrorType, Er
 			index = FILE_MISSING
 		}
 	} else {
-		index = checkSize(indexPath, ch.IndexSize)
+		index = checkIndexSize(indexPath, ch.IndexSize)
 		if index == OKAY {
 			index, err = checkHeader(indexPath)
 		}
@@ -75,6 +75,20 @@ func checkSize(path string, expected int64) ErrorType {
 	return OKAY
 }
 
+// checkIndexSize checks the size of an index portion. Chunks upgraded to 64-bit block numbers (see
+// index.UpgradeChunk) are larger than the 32-bit chunks the manifest describes, so we compare the
+// size they had before being upgraded.
+func checkIndexSize(path string, expected int64) ErrorType {
+	if ret := checkSize(path, expected); ret == OKAY {
+		return OKAY
+	}
+
+	if size, err := index.Size32(path); err != nil || size != expected {
+		return WRONG_SIZE
+	}
+	return OKAY
+}
+
 func checkHeader(path string) (ErrorType, error) {
 	if !file.FileExists(path) {
 		logger.Fatal("should not happen ==> file existence already checked")
@@ -101,7 +115,7 @@ func checkHeader(path string) (ErrorType, error) {
 		if err != nil {
 			return FILE_ERROR, err
 		}
-		if hash.Hex() != unchained.HeaderMagicHash {
+		if !unchained.IsHeaderMagicHash(hash.Hex()) {
 			return WRONG_HASH, nil
 		}
 
@@ -122,7 +136,7 @@ func checkHeader(path string) (ErrorType, error) {
 		if err != nil {
 			return FILE_ERROR, err
 		}
-		if hash.Hex() != unchained.HeaderMagicHash {
+		if !unchained.IsHeaderMagicHash(hash.Hex()) {
 			return WRONG_HASH, nil
 		}
 
@@ -379,7 +379,11 @@ func GetCacheItem(chain string, testMode bool, cT CacheType, cacheInfo *CacheFil
 			"sizeInBytes": size,
 		}
 		if cT == Cache_Monitors {
-			ret["nRecords"] = size / 8 // index.AppRecordWidth - FAST
+			width := int64(8) // index.AppRecordWidth - FAST
+			if file.ReadSmallMagic(cacheInfo.Path) == file.SmallMagicNumber64 {
+				width = 12 // index.AppRecordWidth64
+			}
+			ret["nRecords"] = size / width
 		}
 		return ret, nil
 	default: